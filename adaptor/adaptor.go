@@ -0,0 +1,91 @@
+// 适配器签名: 用一个 adaptor point T = t*G "加密" 的签名, 只有知道 t 的人才能
+// 把它变成有效签名, 而有效签名一旦公开, 预签名的持有者就能算出 t.
+//
+// This is the primitive behind scriptless atomic swaps.  Alice knows a
+// secret t and both parties know T = t*G.  Bob gives Alice a pre-signature
+// under T of the transaction paying her on one chain and Alice gives Bob a
+// pre-signature under T of the transaction paying him on the other.  When
+// Alice completes Bob's pre-signature with Adapt to claim her coins, the
+// signature she publishes lets Bob recover t with ExtractSecret and complete
+// her pre-signature in turn.
+//
+// Both BIP340 Schnorr (PreSign, VerifyPreSignature, Adapt, ExtractSecret)
+// and ECDSA (PreSignECDSA, VerifyPreSignatureECDSA, AdaptECDSA,
+// ExtractSecretECDSA) pre-signatures are supported, and the two can be mixed
+// within one swap since they only share the adaptor point.  The ECDSA
+// construction is the one of libsecp256k1-zkp's ecdsa_adaptor module, where
+// a DLEQ proof shows that the pre-signature nonce is bound to the adaptor
+// point.
+
+package adaptor
+
+import "crypto/rand"
+import "fmt"
+import "io"
+import "math/big"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+var (
+	ErrInvalidPreSignature = fmt.Errorf("adaptor: pre-signature is invalid")
+	ErrInvalidAdaptor      = fmt.Errorf("adaptor: adaptor point is not a valid public key")
+	ErrSecretMismatch      = fmt.Errorf("adaptor: signature does not reveal the secret of the adaptor point")
+)
+
+// generateNonce derives a nonce from the private key, the message, the
+// adaptor point and 32 bytes of fresh randomness.
+func generateNonce(tag string, priv *ec.PrivateKey, msg []byte, adaptorPoint *ec.PublicKey) (*big.Int, error) {
+	n := ec.S256().N
+	sk := priv.PrivatekeyToBytes()
+	defer func() {
+		for i := range sk {
+			sk[i] = 0
+		}
+	}()
+	var aux [32]byte
+	for {
+		if _, err := io.ReadFull(rand.Reader, aux[:]); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(ec.TaggedHash(tag, sk,
+			adaptorPoint.SerializeCompressed(), msg, aux[:]))
+		k.Mod(k, n)
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// checkAdaptor checks that the adaptor point is a point on the curve other
+// than the point at infinity.
+func checkAdaptor(p *ec.PublicKey) error {
+	if p == nil || p.X == nil || p.Y == nil || (p.X.Sign() == 0 && p.Y.Sign() == 0) ||
+		!ec.S256().IsOnCurve(p.X, p.Y) {
+		return ErrInvalidAdaptor
+	}
+	return nil
+}
+
+// secretKey returns t as a private key.  A zero t cannot be the secret of
+// an adaptor point, which excludes the point at infinity.
+func secretKey(t *big.Int) (*ec.PrivateKey, error) {
+	if t.Sign() == 0 {
+		return nil, ErrSecretMismatch
+	}
+	priv, _ := ec.PrivKeyFromBytes(ec.S256(), t.FillBytes(make([]byte, 32)))
+	return priv, nil
+}
+
+func negatePoint(p *ec.PublicKey) *ec.PublicKey {
+	return &ec.PublicKey{Curve: ec.S256(), X: p.X, Y: new(big.Int).Sub(ec.S256().P, p.Y)}
+}
+
+// parseScalar parses a 32 byte scalar, which must be less than N.
+func parseScalar(b []byte) (*big.Int, error) {
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(ec.S256().N) >= 0 {
+		return nil, fmt.Errorf("adaptor: scalar is not less than the group order")
+	}
+	return k, nil
+}
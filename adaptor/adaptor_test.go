@@ -0,0 +1,166 @@
+package adaptor
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+func testKey(seed string) (*ec.PrivateKey, *ec.PublicKey) {
+	b := sha256.Sum256([]byte(seed))
+	return ec.PrivKeyFromBytes(ec.S256(), b[:])
+}
+
+// TestSchnorrSwap runs an atomic swap where both chains use BIP340.
+func TestSchnorrSwap(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		alice, alicePub := testKey("alice" + string(rune('a'+i)))
+		bob, bobPub := testKey("bob" + string(rune('a'+i)))
+		secret, adaptorPoint := testKey("secret" + string(rune('a'+i)))
+		txA := sha256.Sum256([]byte("chain A: alice pays bob"))
+		txB := sha256.Sum256([]byte("chain B: bob pays alice"))
+
+		// Both sides exchange pre-signatures under Alice's adaptor point.
+		bobPre, err := PreSign(bob, txB[:], adaptorPoint)
+		if err != nil {
+			t.Fatalf("PreSign: %v", err)
+		}
+		alicePre, err := PreSign(alice, txA[:], adaptorPoint)
+		if err != nil {
+			t.Fatalf("PreSign: %v", err)
+		}
+		bobPre, err = ParseSchnorrPreSignature(bobPre.Serialize())
+		if err != nil {
+			t.Fatalf("ParseSchnorrPreSignature: %v", err)
+		}
+		if !VerifyPreSignature(bobPre, bobPub, txB[:], adaptorPoint) {
+			t.Fatalf("Alice rejects Bob's pre-signature")
+		}
+		if !VerifyPreSignature(alicePre, alicePub, txA[:], adaptorPoint) {
+			t.Fatalf("Bob rejects Alice's pre-signature")
+		}
+
+		// A pre-signature is not a valid signature by itself.
+		if (&ec.SchnorrSignature{R: bobPre.R.X, S: bobPre.S}).Verify(txB[:], bobPub) {
+			t.Fatalf("pre-signature verifies as a signature")
+		}
+
+		// Alice claims her coins, publishing Bob's completed signature.
+		bobSig := Adapt(bobPre, secret)
+		if !bobSig.Verify(txB[:], bobPub) {
+			t.Fatalf("adapted signature does not verify")
+		}
+
+		// Bob learns the secret from it and claims his coins.
+		learned, err := ExtractSecret(bobPre, bobSig)
+		if err != nil {
+			t.Fatalf("ExtractSecret: %v", err)
+		}
+		if learned.D.Cmp(secret.D) != 0 {
+			t.Fatalf("extracted the wrong secret")
+		}
+		if !Adapt(alicePre, learned).Verify(txA[:], alicePub) {
+			t.Fatalf("Bob cannot complete Alice's pre-signature")
+		}
+	}
+}
+
+// TestECDSASwap runs an atomic swap where Bob's chain uses ECDSA and Alice's
+// chain uses BIP340.
+func TestECDSASwap(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		alice, alicePub := testKey("alice" + string(rune('a'+i)))
+		bob, bobPub := testKey("bob" + string(rune('a'+i)))
+		secret, adaptorPoint := testKey("secret" + string(rune('a'+i)))
+		txA := sha256.Sum256([]byte("chain A: alice pays bob"))
+		txB := sha256.Sum256([]byte("chain B: bob pays alice"))
+
+		bobPre, err := PreSignECDSA(bob, txB[:], adaptorPoint)
+		if err != nil {
+			t.Fatalf("PreSignECDSA: %v", err)
+		}
+		bobPre, err = ParseECDSAPreSignature(bobPre.Serialize())
+		if err != nil {
+			t.Fatalf("ParseECDSAPreSignature: %v", err)
+		}
+		if !VerifyPreSignatureECDSA(bobPre, bobPub, txB[:], adaptorPoint) {
+			t.Fatalf("Alice rejects Bob's pre-signature")
+		}
+		alicePre, err := PreSign(alice, txA[:], adaptorPoint)
+		if err != nil {
+			t.Fatalf("PreSign: %v", err)
+		}
+
+		bobSig := AdaptECDSA(bobPre, secret)
+		if !bobSig.Verify(txB[:], bobPub) || !bobSig.IsLowS() {
+			t.Fatalf("adapted ECDSA signature does not verify")
+		}
+		parsed, err := ec.ParseSignatureWithPolicy(bobSig.Serialize(), ec.S256(), ec.PolicyConsensus)
+		if err != nil {
+			t.Fatalf("ParseSignatureWithPolicy: %v", err)
+		}
+
+		learned, err := ExtractSecretECDSA(bobPre, parsed)
+		if err != nil {
+			t.Fatalf("ExtractSecretECDSA: %v", err)
+		}
+		if learned.D.Cmp(secret.D) != 0 {
+			t.Fatalf("extracted the wrong secret")
+		}
+		if !Adapt(alicePre, learned).Verify(txA[:], alicePub) {
+			t.Fatalf("Bob cannot complete Alice's pre-signature")
+		}
+
+		// A signature with the same nonce but another s reveals nothing.
+		forged := *parsed
+		forged.S = new(big.Int).Add(parsed.S, big.NewInt(1))
+		if _, err := ExtractSecretECDSA(bobPre, &forged); err != ErrSecretMismatch {
+			t.Fatalf("ExtractSecretECDSA of a forged signature: got %v", err)
+		}
+	}
+}
+
+func TestRejectInvalidPreSignatures(t *testing.T) {
+	priv, pub := testKey("signer")
+	_, adaptorPoint := testKey("adaptor")
+	_, otherPoint := testKey("other adaptor")
+	msg := sha256.Sum256([]byte("msg"))
+	other := sha256.Sum256([]byte("other msg"))
+
+	sPre, _ := PreSign(priv, msg[:], adaptorPoint)
+	if VerifyPreSignature(sPre, pub, other[:], adaptorPoint) {
+		t.Errorf("Schnorr pre-signature verifies for another message")
+	}
+	if VerifyPreSignature(sPre, pub, msg[:], otherPoint) {
+		t.Errorf("Schnorr pre-signature verifies for another adaptor point")
+	}
+
+	ePre, _ := PreSignECDSA(priv, msg[:], adaptorPoint)
+	if VerifyPreSignatureECDSA(ePre, pub, other[:], adaptorPoint) {
+		t.Errorf("ECDSA pre-signature verifies for another message")
+	}
+	if VerifyPreSignatureECDSA(ePre, pub, msg[:], otherPoint) {
+		t.Errorf("ECDSA pre-signature verifies for another adaptor point")
+	}
+	forged := *ePre
+	forged.ProofZ = new(big.Int).Add(ePre.ProofZ, big.NewInt(1))
+	if VerifyPreSignatureECDSA(&forged, pub, msg[:], adaptorPoint) {
+		t.Errorf("ECDSA pre-signature verifies with a broken DLEQ proof")
+	}
+
+	// A signature unrelated to the pre-signature reveals nothing.
+	sig, _ := priv.SignSchnorr(msg[:])
+	if _, err := ExtractSecret(sPre, sig); err != ErrSecretMismatch {
+		t.Errorf("ExtractSecret: got %v, want %v", err, ErrSecretMismatch)
+	}
+	ecdsaSig, _ := priv.Sign(msg[:])
+	if _, err := ExtractSecretECDSA(ePre, ecdsaSig); err != ErrSecretMismatch {
+		t.Errorf("ExtractSecretECDSA: got %v, want %v", err, ErrSecretMismatch)
+	}
+
+	if _, err := PreSign(priv, msg[:], &ec.PublicKey{Curve: ec.S256(), X: big.NewInt(1), Y: big.NewInt(1)}); err != ErrInvalidAdaptor {
+		t.Errorf("PreSign with an invalid adaptor point: got %v", err)
+	}
+}
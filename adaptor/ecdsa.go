@@ -0,0 +1,234 @@
+package adaptor
+
+import "fmt"
+import "math/big"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// LenECDSAPreSignature is the length of a serialized ECDSA pre-signature.
+const LenECDSAPreSignature = 162
+
+// ECDSAPreSignature is an ECDSA signature "encrypted" under an adaptor point
+// T.  R = k*T is the nonce point of the final signature and RG = k*G; the
+// proof (ProofE, ProofZ) shows that both use the same k.
+type ECDSAPreSignature struct {
+	R      *ec.PublicKey
+	RG     *ec.PublicKey
+	S      *big.Int
+	ProofE *big.Int
+	ProofZ *big.Int
+}
+
+// PreSignECDSA creates an ECDSA pre-signature of hash under adaptorPoint.
+// Adapting it with the discrete logarithm of adaptorPoint gives an ordinary
+// low S ECDSA signature of hash by priv.
+func PreSignECDSA(priv *ec.PrivateKey, hash []byte, adaptorPoint *ec.PublicKey) (*ECDSAPreSignature, error) {
+	if err := checkAdaptor(adaptorPoint); err != nil {
+		return nil, err
+	}
+	curve := ec.S256()
+	n := curve.N
+	if priv.D.Sign() == 0 || priv.D.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("adaptor: private key is out of range")
+	}
+	m := hashToInt(hash)
+
+	for {
+		k, err := generateNonce("adaptor/ecdsa/nonce", priv, hash, adaptorPoint)
+		if err != nil {
+			return nil, err
+		}
+		rx, ry := curve.ScalarMult(adaptorPoint.X, adaptorPoint.Y, k.FillBytes(make([]byte, 32)))
+		r := new(big.Int).Mod(rx, n)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		// s' = k^-1 * (m + r*d)
+		s := new(big.Int).Mul(r, priv.D)
+		s.Add(s, m)
+		s.Mul(s, new(big.Int).ModInverse(k, n))
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+
+		gx, gy := curve.ScalarBaseMult(k.FillBytes(make([]byte, 32)))
+		preSig := &ECDSAPreSignature{
+			R:  &ec.PublicKey{Curve: curve, X: rx, Y: ry},
+			RG: &ec.PublicKey{Curve: curve, X: gx, Y: gy},
+			S:  s,
+		}
+		if err := preSig.prove(k, adaptorPoint); err != nil {
+			return nil, err
+		}
+		k.SetInt64(0)
+		return preSig, nil
+	}
+}
+
+// VerifyPreSignatureECDSA reports whether preSig is a valid ECDSA
+// pre-signature of hash by pubKey under adaptorPoint.
+func VerifyPreSignatureECDSA(preSig *ECDSAPreSignature, pubKey *ec.PublicKey, hash []byte,
+	adaptorPoint *ec.PublicKey) bool {
+	if checkAdaptor(adaptorPoint) != nil || checkAdaptor(preSig.R) != nil ||
+		checkAdaptor(preSig.RG) != nil {
+		return false
+	}
+	curve := ec.S256()
+	n := curve.N
+	if preSig.S.Sign() <= 0 || preSig.S.Cmp(n) >= 0 || !preSig.verifyProof(adaptorPoint) {
+		return false
+	}
+	r := new(big.Int).Mod(preSig.R.X, n)
+	if r.Sign() == 0 {
+		return false
+	}
+
+	// RG == s'^-1 * (m*G + r*P)
+	sInv := new(big.Int).ModInverse(preSig.S, n)
+	u1 := new(big.Int).Mul(hashToInt(hash), sInv)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(r, sInv)
+	u2.Mod(u2, n)
+	x1, y1 := curve.ScalarBaseMult(u1.FillBytes(make([]byte, 32)))
+	x2, y2 := curve.ScalarMult(pubKey.X, pubKey.Y, u2.FillBytes(make([]byte, 32)))
+	x, y := curve.Add(x1, y1, x2, y2)
+	return x.Cmp(preSig.RG.X) == 0 && y.Cmp(preSig.RG.Y) == 0
+}
+
+// AdaptECDSA completes the pre-signature with the secret t of its adaptor
+// point and returns the low S ECDSA signature.
+func AdaptECDSA(preSig *ECDSAPreSignature, secret *ec.PrivateKey) *ec.Signature {
+	n := ec.S256().N
+	s := new(big.Int).ModInverse(secret.D, n)
+	s.Mul(s, preSig.S)
+	s.Mod(s, n)
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+	}
+	return &ec.Signature{R: new(big.Int).Mod(preSig.R.X, n), S: s}
+}
+
+// ExtractSecretECDSA recovers the secret t of the adaptor point from the
+// pre-signature and the signature that was adapted from it.  Since R = t*RG
+// for the two nonce points of the pre-signature, the secret is checked
+// without the adaptor point itself.
+func ExtractSecretECDSA(preSig *ECDSAPreSignature, sig *ec.Signature) (*ec.PrivateKey, error) {
+	curve := ec.S256()
+	n := curve.N
+	if sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 ||
+		sig.R.Cmp(new(big.Int).Mod(preSig.R.X, n)) != 0 {
+		return nil, ErrSecretMismatch
+	}
+
+	// t = s' / s, up to the sign lost to low S normalization.
+	t := new(big.Int).ModInverse(sig.S, n)
+	t.Mul(t, preSig.S)
+	t.Mod(t, n)
+	x, y := curve.ScalarMult(preSig.RG.X, preSig.RG.Y, t.Bytes())
+	if x.Cmp(preSig.R.X) != 0 {
+		return nil, ErrSecretMismatch
+	}
+	if y.Cmp(preSig.R.Y) != 0 {
+		t.Sub(n, t)
+	}
+	return secretKey(t)
+}
+
+// Serialize returns the 162 byte encoding compressed(R) || compressed(RG) ||
+// bytes(s') || bytes(e) || bytes(z) of the pre-signature.
+func (preSig *ECDSAPreSignature) Serialize() []byte {
+	b := make([]byte, 0, LenECDSAPreSignature)
+	b = append(b, preSig.R.SerializeCompressed()...)
+	b = append(b, preSig.RG.SerializeCompressed()...)
+	b = append(b, preSig.S.FillBytes(make([]byte, 32))...)
+	b = append(b, preSig.ProofE.FillBytes(make([]byte, 32))...)
+	return append(b, preSig.ProofZ.FillBytes(make([]byte, 32))...)
+}
+
+// ParseECDSAPreSignature parses a pre-signature encoded by Serialize.
+func ParseECDSAPreSignature(b []byte) (*ECDSAPreSignature, error) {
+	if len(b) != LenECDSAPreSignature {
+		return nil, ErrInvalidPreSignature
+	}
+	r, err := ec.ParsePubKey(b[:33], ec.S256())
+	if err != nil {
+		return nil, err
+	}
+	rg, err := ec.ParsePubKey(b[33:66], ec.S256())
+	if err != nil {
+		return nil, err
+	}
+	var scalars [3]*big.Int
+	for i := range scalars {
+		if scalars[i], err = parseScalar(b[66+32*i : 98+32*i]); err != nil {
+			return nil, err
+		}
+	}
+	return &ECDSAPreSignature{R: r, RG: rg, S: scalars[0], ProofE: scalars[1], ProofZ: scalars[2]}, nil
+}
+
+// prove creates the DLEQ proof that log_G(RG) == log_T(R) == k.
+func (preSig *ECDSAPreSignature) prove(k *big.Int, adaptorPoint *ec.PublicKey) error {
+	curve := ec.S256()
+	a, err := generateNonce("adaptor/ecdsa/dleq/nonce", &ec.PrivateKey{D: k}, nil, adaptorPoint)
+	if err != nil {
+		return err
+	}
+	a1x, a1y := curve.ScalarBaseMult(a.FillBytes(make([]byte, 32)))
+	a2x, a2y := curve.ScalarMult(adaptorPoint.X, adaptorPoint.Y, a.FillBytes(make([]byte, 32)))
+	e := preSig.dleqChallenge(adaptorPoint,
+		&ec.PublicKey{Curve: curve, X: a1x, Y: a1y}, &ec.PublicKey{Curve: curve, X: a2x, Y: a2y})
+
+	z := new(big.Int).Mul(e, k)
+	z.Add(z, a)
+	z.Mod(z, curve.N)
+	a.SetInt64(0)
+	preSig.ProofE, preSig.ProofZ = e, z
+	return nil
+}
+
+// verifyProof checks the DLEQ proof by recomputing A1 = z*G - e*RG and
+// A2 = z*T - e*R and the challenge from them.
+func (preSig *ECDSAPreSignature) verifyProof(adaptorPoint *ec.PublicKey) bool {
+	curve := ec.S256()
+	n := curve.N
+	if preSig.ProofE.Cmp(n) >= 0 || preSig.ProofZ.Cmp(n) >= 0 {
+		return false
+	}
+	negE := new(big.Int).Sub(n, preSig.ProofE)
+
+	zgx, zgy := curve.ScalarBaseMult(preSig.ProofZ.FillBytes(make([]byte, 32)))
+	egx, egy := curve.ScalarMult(preSig.RG.X, preSig.RG.Y, negE.FillBytes(make([]byte, 32)))
+	a1x, a1y := curve.Add(zgx, zgy, egx, egy)
+
+	ztx, zty := curve.ScalarMult(adaptorPoint.X, adaptorPoint.Y, preSig.ProofZ.FillBytes(make([]byte, 32)))
+	erx, ery := curve.ScalarMult(preSig.R.X, preSig.R.Y, negE.FillBytes(make([]byte, 32)))
+	a2x, a2y := curve.Add(ztx, zty, erx, ery)
+
+	if (a1x.Sign() == 0 && a1y.Sign() == 0) || (a2x.Sign() == 0 && a2y.Sign() == 0) {
+		return false
+	}
+	e := preSig.dleqChallenge(adaptorPoint,
+		&ec.PublicKey{Curve: curve, X: a1x, Y: a1y}, &ec.PublicKey{Curve: curve, X: a2x, Y: a2y})
+	return e.Cmp(preSig.ProofE) == 0
+}
+
+func (preSig *ECDSAPreSignature) dleqChallenge(adaptorPoint, a1, a2 *ec.PublicKey) *big.Int {
+	e := new(big.Int).SetBytes(ec.TaggedHash("adaptor/ecdsa/dleq",
+		adaptorPoint.SerializeCompressed(),
+		preSig.R.SerializeCompressed(), preSig.RG.SerializeCompressed(),
+		a1.SerializeCompressed(), a2.SerializeCompressed()))
+	return e.Mod(e, ec.S256().N)
+}
+
+// hashToInt converts a hash to an integer the way ECDSA does, keeping its
+// leftmost 256 bits.
+func hashToInt(hash []byte) *big.Int {
+	if len(hash) > 32 {
+		hash = hash[:32]
+	}
+	return new(big.Int).SetBytes(hash)
+}
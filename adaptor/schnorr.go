@@ -0,0 +1,152 @@
+package adaptor
+
+import "fmt"
+import "math/big"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// LenSchnorrPreSignature is the length of a serialized Schnorr pre-signature.
+const LenSchnorrPreSignature = 65
+
+// SchnorrPreSignature is a BIP340 signature "encrypted" under an adaptor
+// point T.  R is the full nonce point k*G + T; the parity of its Y coordinate
+// tells whether the final signature adds or subtracts the secret t.
+type SchnorrPreSignature struct {
+	R *ec.PublicKey
+	S *big.Int
+}
+
+// PreSign creates a pre-signature of msg under adaptorPoint.  Adding the
+// discrete logarithm of adaptorPoint with Adapt turns it into a BIP340
+// signature of msg for the x-only form of priv's public key.
+func PreSign(priv *ec.PrivateKey, msg []byte, adaptorPoint *ec.PublicKey) (*SchnorrPreSignature, error) {
+	if err := checkAdaptor(adaptorPoint); err != nil {
+		return nil, err
+	}
+	curve := ec.S256()
+	n := curve.N
+
+	d := new(big.Int).Set(priv.D)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("adaptor: private key is out of range")
+	}
+	pub := priv.ECPubKey()
+	if pub.Y.Bit(0) == 1 {
+		d.Sub(n, d)
+	}
+
+	k, err := generateNonce("adaptor/schnorr/nonce", priv, msg, adaptorPoint)
+	if err != nil {
+		return nil, err
+	}
+	kx, ky := curve.ScalarBaseMult(k.FillBytes(make([]byte, 32)))
+	rx, ry := curve.Add(kx, ky, adaptorPoint.X, adaptorPoint.Y)
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return nil, fmt.Errorf("adaptor: nonce point is the point at infinity")
+	}
+	// The final nonce is -R when R has an odd Y, so k is negated with it.
+	if ry.Bit(0) == 1 {
+		k.Sub(n, k)
+	}
+
+	e := schnorrChallenge(rx, pub, msg)
+	s := e.Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, n)
+	k.SetInt64(0)
+
+	return &SchnorrPreSignature{R: &ec.PublicKey{Curve: curve, X: rx, Y: ry}, S: s}, nil
+}
+
+// VerifyPreSignature reports whether preSig is a valid pre-signature of msg
+// by pubKey under adaptorPoint, i.e. whether adapting it with the discrete
+// logarithm of adaptorPoint gives a valid BIP340 signature.
+func VerifyPreSignature(preSig *SchnorrPreSignature, pubKey *ec.PublicKey, msg []byte,
+	adaptorPoint *ec.PublicKey) bool {
+	if checkAdaptor(adaptorPoint) != nil || checkAdaptor(preSig.R) != nil {
+		return false
+	}
+	curve := ec.S256()
+	if preSig.S.Sign() < 0 || preSig.S.Cmp(curve.N) >= 0 {
+		return false
+	}
+	pk, err := ec.ParseXOnlyPubKey(pubKey.SerializeXOnly())
+	if err != nil {
+		return false
+	}
+
+	// s'*G == ±(R - T) + e*P, with the sign of the Y parity of R.
+	t := negatePoint(adaptorPoint)
+	kx, ky := curve.Add(preSig.R.X, preSig.R.Y, t.X, t.Y)
+	if preSig.R.Y.Bit(0) == 1 {
+		ky = new(big.Int).Sub(curve.P, ky)
+	}
+	e := schnorrChallenge(preSig.R.X, pk, msg)
+	ex, ey := curve.ScalarMult(pk.X, pk.Y, e.Bytes())
+	rhsx, rhsy := curve.Add(kx, ky, ex, ey)
+	lhsx, lhsy := curve.ScalarBaseMult(preSig.S.FillBytes(make([]byte, 32)))
+	return lhsx.Cmp(rhsx) == 0 && lhsy.Cmp(rhsy) == 0
+}
+
+// Adapt completes the pre-signature with the secret t of its adaptor point
+// and returns the BIP340 signature.
+func Adapt(preSig *SchnorrPreSignature, secret *ec.PrivateKey) *ec.SchnorrSignature {
+	n := ec.S256().N
+	s := new(big.Int)
+	if preSig.R.Y.Bit(0) == 1 {
+		s.Sub(preSig.S, secret.D)
+	} else {
+		s.Add(preSig.S, secret.D)
+	}
+	s.Mod(s, n)
+	return &ec.SchnorrSignature{R: new(big.Int).Set(preSig.R.X), S: s}
+}
+
+// ExtractSecret recovers the secret t of the adaptor point from the
+// pre-signature and the signature that was adapted from it.  Only the nonce of
+// sig is checked against the pre-signature, so a caller that has not
+// verified sig should check that t*G is the adaptor point it expects.
+func ExtractSecret(preSig *SchnorrPreSignature, sig *ec.SchnorrSignature) (*ec.PrivateKey, error) {
+	if sig.R.Cmp(preSig.R.X) != 0 {
+		return nil, ErrSecretMismatch
+	}
+	t := new(big.Int)
+	if preSig.R.Y.Bit(0) == 1 {
+		t.Sub(preSig.S, sig.S)
+	} else {
+		t.Sub(sig.S, preSig.S)
+	}
+	t.Mod(t, ec.S256().N)
+	return secretKey(t)
+}
+
+// Serialize returns the 65 byte encoding compressed(R) || bytes(s') of the
+// pre-signature.
+func (preSig *SchnorrPreSignature) Serialize() []byte {
+	b := make([]byte, 0, LenSchnorrPreSignature)
+	b = append(b, preSig.R.SerializeCompressed()...)
+	return append(b, preSig.S.FillBytes(make([]byte, 32))...)
+}
+
+// ParseSchnorrPreSignature parses a pre-signature encoded by Serialize.
+func ParseSchnorrPreSignature(b []byte) (*SchnorrPreSignature, error) {
+	if len(b) != LenSchnorrPreSignature {
+		return nil, ErrInvalidPreSignature
+	}
+	r, err := ec.ParsePubKey(b[:33], ec.S256())
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseScalar(b[33:])
+	if err != nil {
+		return nil, err
+	}
+	return &SchnorrPreSignature{R: r, S: s}, nil
+}
+
+func schnorrChallenge(rx *big.Int, pk *ec.PublicKey, msg []byte) *big.Int {
+	e := new(big.Int).SetBytes(ec.TaggedHash("BIP0340/challenge",
+		rx.FillBytes(make([]byte, 32)), pk.SerializeXOnly(), msg))
+	return e.Mod(e, ec.S256().N)
+}
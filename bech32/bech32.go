@@ -0,0 +1,429 @@
+// bech32 地址编码: BIP173 的 bech32 和 BIP350 的 bech32m, 以及隔离见证地址.
+//
+// Encode and Decode work on strings of 5-bit values with a configurable
+// human-readable part (HRP); ConvertBits regroups bytes into 5-bit values and
+// back.  EncodeSegwitAddress and DecodeSegwitAddress add the segregated
+// witness rules on top: witness version 0 uses bech32, versions 1 to 16 use
+// bech32m.
+//
+// Decoding failures are reported as *DecodeError, which carries the
+// positions of the offending characters.  For checksum failures the
+// positions of up to two substituted characters are located the way Bitcoin
+// Core does, so a wallet can point the user at a typo.
+//
+// https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+
+package bech32
+
+import "fmt"
+import "sort"
+import "strings"
+
+// Encoding selects the checksum constant of a bech32 string.
+type Encoding int
+
+const (
+	// Bech32 is the original BIP173 checksum.
+	Bech32 Encoding = iota + 1
+
+	// Bech32m is the BIP350 checksum, used for witness versions 1 and up.
+	Bech32m
+)
+
+// String returns the name of the encoding.
+func (e Encoding) String() string {
+	switch e {
+	case Bech32:
+		return "bech32"
+	case Bech32m:
+		return "bech32m"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// checksumConst is the value the checksum of a valid string of the encoding
+// evaluates to.
+func (e Encoding) checksumConst() uint32 {
+	if e == Bech32m {
+		return 0x2bc830a3
+	}
+	return 1
+}
+
+const MaxLength = 90     // 字符串的最大长度
+const checksumLength = 6 // 校验码的字符数
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// charsetRev maps a lower case character to its 5-bit value, or -1.
+var charsetRev [128]int8
+
+func init() {
+	for i := range charsetRev {
+		charsetRev[i] = -1
+	}
+	for i, c := range charset {
+		charsetRev[c] = int8(i)
+	}
+}
+
+// DecodeError describes why a string is not valid bech32.  Positions holds
+// the indexes of the characters found to be wrong, in increasing order; it
+// is empty when no location could be determined, for example for checksums
+// with more than two errors.
+type DecodeError struct {
+	Reason    string
+	Positions []int
+}
+
+func (e *DecodeError) Error() string {
+	if len(e.Positions) == 0 {
+		return "bech32: " + e.Reason
+	}
+	return fmt.Sprintf("bech32: %s at position %v", e.Reason, e.Positions)
+}
+
+// polymod computes the BCH checksum remainder of the values.
+func polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// hrpExpand returns the values the HRP contributes to the checksum.
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// residue returns the checksum remainder of the HRP and data, which is zero
+// for a valid string of the encoding.
+func residue(hrp string, data []byte, enc Encoding) uint32 {
+	return polymod(append(hrpExpand(hrp), data...)) ^ enc.checksumConst()
+}
+
+// Encode returns the bech32 string of the HRP and the 5-bit values in data
+// with the checksum of enc.  The HRP is converted to lower case.
+func Encode(hrp string, data []byte, enc Encoding) (string, error) {
+	if enc != Bech32 && enc != Bech32m {
+		return "", fmt.Errorf("bech32: unknown encoding %d", int(enc))
+	}
+	if len(hrp) == 0 {
+		return "", fmt.Errorf("bech32: empty human-readable part")
+	}
+	if len(hrp)+1+len(data)+checksumLength > MaxLength {
+		return "", fmt.Errorf("bech32: string would be longer than %d characters", MaxLength)
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", fmt.Errorf("bech32: invalid human-readable part character %q", hrp[i])
+		}
+	}
+	hrp = strings.ToLower(hrp)
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(data) + checksumLength)
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range data {
+		if v > 31 {
+			return "", fmt.Errorf("bech32: data value %d is not a 5-bit value", v)
+		}
+		sb.WriteByte(charset[v])
+	}
+	mod := polymod(append(append(hrpExpand(hrp), data...), 0, 0, 0, 0, 0, 0)) ^ enc.checksumConst()
+	for i := 0; i < checksumLength; i++ {
+		sb.WriteByte(charset[(mod>>uint(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
+
+// Decode parses a bech32 or bech32m string and returns its lower case HRP,
+// the 5-bit data values without the checksum and the encoding whose
+// checksum matched.  Errors are of type *DecodeError.
+func Decode(s string) (hrp string, data []byte, enc Encoding, err error) {
+	if len(s) > MaxLength {
+		pos := make([]int, 0, len(s)-MaxLength)
+		for i := MaxLength; i < len(s); i++ {
+			pos = append(pos, i)
+		}
+		return "", nil, 0, &DecodeError{"string too long", pos}
+	}
+	if pos := checkCharacters(s); pos != nil {
+		return "", nil, 0, &DecodeError{"invalid character or mixed case", pos}
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 0 {
+		return "", nil, 0, &DecodeError{"missing separator", nil}
+	}
+	if sep == 0 || sep+checksumLength >= len(s) {
+		return "", nil, 0, &DecodeError{"invalid separator position", []int{sep}}
+	}
+	hrp = s[:sep]
+	values := make([]byte, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		v := charsetRev[s[i]]
+		if v < 0 {
+			return "", nil, 0, &DecodeError{"invalid data character", []int{i}}
+		}
+		values[i-sep-1] = byte(v)
+	}
+
+	for _, e := range []Encoding{Bech32, Bech32m} {
+		if residue(hrp, values, e) == 0 {
+			return hrp, values[:len(values)-checksumLength], e, nil
+		}
+	}
+	return "", nil, 0, &DecodeError{"invalid checksum", locateErrors(hrp, values, len(s))}
+}
+
+// checkCharacters returns the positions of characters outside of the
+// printable ASCII range, or of the characters whose case differs from the
+// first cased character, or nil if there are none.
+func checkCharacters(s string) []int {
+	var pos []int
+	lower, upper := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < 33 || c > 126:
+			pos = append(pos, i)
+		case c >= 'a' && c <= 'z':
+			if upper {
+				pos = append(pos, i)
+			} else {
+				lower = true
+			}
+		case c >= 'A' && c <= 'Z':
+			if lower {
+				pos = append(pos, i)
+			} else {
+				upper = true
+			}
+		}
+	}
+	return pos
+}
+
+// The checksum is a BCH code over GF(32) whose generator has the roots
+// e^997, e^998 and e^999 in GF(1024), so evaluating the remainder at them
+// gives three syndromes that locate up to two errors.  GF(32) is built with
+// x^5 + x^3 + 1 and GF(1024) over it with z^2 + 9z + 23, as in Bitcoin Core's
+// bech32.cpp.
+var (
+	gf1024Exp      [1023]int
+	gf1024Log      [1024]int
+	syndromeConsts [25]uint32
+)
+
+func init() {
+	var gf32Exp [31]int
+	var gf32Log [32]int
+	gf32Exp[0], gf32Log[0], gf32Log[1] = 1, -1, 0
+	v := 1
+	for i := 1; i < 31; i++ {
+		v <<= 1
+		if v&32 != 0 {
+			v ^= 41
+		}
+		gf32Exp[i], gf32Log[v] = v, i
+	}
+	gf32Mul := func(a, b int) int {
+		if a == 0 || b == 0 {
+			return 0
+		}
+		return gf32Exp[(gf32Log[a]+gf32Log[b])%31]
+	}
+
+	gf1024Exp[0], gf1024Log[0], gf1024Log[1] = 1, -1, 0
+	v = 1
+	for i := 1; i < 1023; i++ {
+		v0, v1 := v&31, v>>5
+		v = (gf32Mul(v1, 9)^v0)<<5 | gf32Mul(v1, 23)
+		gf1024Exp[i], gf1024Log[v] = v, i
+	}
+
+	// syndromeConsts[i] holds the three syndromes of the remainder with
+	// only bit i+5 set, packed 10 bits each.  The remainder bits 5k to
+	// 5k+4 are the coefficient of x^k, and a GF(32) value is the GF(1024)
+	// value with a zero z coefficient.
+	for i := 0; i < 25; i++ {
+		coef, k := 1<<uint(i%5), 1+i/5
+		for j := 0; j < 3; j++ {
+			l := (gf1024Log[coef] + (997+j)*k) % 1023
+			syndromeConsts[i] |= uint32(gf1024Exp[l]) << uint(10*j)
+		}
+	}
+}
+
+// syndrome evaluates the remainder at e^997, e^998 and e^999.
+func syndrome(r uint32) uint32 {
+	low := r & 31
+	s := low ^ low<<10 ^ low<<20
+	for i := 0; i < 25; i++ {
+		if (r>>uint(5+i))&1 == 1 {
+			s ^= syndromeConsts[i]
+		}
+	}
+	return s
+}
+
+// locateErrors returns the positions in a string of length n of at most two
+// substitutions that would make the checksum of hrp and values valid under
+// either encoding, preferring the encoding that needs fewer, or nil.
+func locateErrors(hrp string, values []byte, n int) []int {
+	var best []int
+	for _, enc := range []Encoding{Bech32, Bech32m} {
+		pos := locateErrorsEnc(hrp, values, enc)
+		if pos != nil && (best == nil || len(pos) < len(best)) {
+			best = pos
+		}
+	}
+	for i := range best {
+		// The positions count from the end of the string.
+		best[i] = n - 1 - best[i]
+	}
+	sort.Ints(best)
+	return best
+}
+
+// locateErrorsEnc finds the positions, counted from the last character, of
+// one or two errors in the data part values under the encoding enc.  The
+// syndromes only use three of the six roots of the generator, so every
+// candidate is confirmed by finding the substitutions that fix the checksum.
+func locateErrorsEnc(hrp string, values []byte, enc Encoding) []int {
+	n := len(values)
+	syn := syndrome(residue(hrp, values, enc))
+	s0, s1, s2 := int(syn&0x3ff), int(syn>>10&0x3ff), int(syn>>20)
+	l0, l1, l2 := gf1024Log[s0], gf1024Log[s1], gf1024Log[s2]
+
+	// A single error e1*x^p1 has s1/s0 = s2/s1 = e^p1.
+	if l0 != -1 && l1 != -1 && l2 != -1 && (2*l1-l2-l0+2046)%1023 == 0 {
+		p1 := (l1 - l0 + 1023) % 1023
+		le1 := l0 + (1023-997)*p1
+		// The error value must be in GF(32), whose logs are multiples
+		// of 33.
+		if p1 < n && le1%33 == 0 && fixable(hrp, values, enc, []int{p1}) {
+			return []int{p1}
+		}
+		return nil
+	}
+
+	// Otherwise try every position for the first of two errors.
+	mulExp := func(s, l, p int) int {
+		if s == 0 {
+			return 0
+		}
+		return gf1024Exp[(l+p)%1023]
+	}
+	for p1 := 0; p1 < n; p1++ {
+		s2s1p1 := s2 ^ mulExp(s1, l1, p1)
+		if s2s1p1 == 0 {
+			continue
+		}
+		s1s0p1 := s1 ^ mulExp(s0, l0, p1)
+		if s1s0p1 == 0 {
+			continue
+		}
+		ls1s0p1 := gf1024Log[s1s0p1]
+		p2 := (gf1024Log[s2s1p1] - ls1s0p1 + 1023) % 1023
+		if p2 >= n || p1 == p2 {
+			continue
+		}
+		s1s0p2 := s1 ^ mulExp(s0, l0, p2)
+		if s1s0p2 == 0 {
+			continue
+		}
+		invP1P2 := 1023 - gf1024Log[gf1024Exp[p1]^gf1024Exp[p2]]
+		if (gf1024Log[s1s0p2]+invP1P2+(1023-997)*p1)%33 != 0 {
+			continue
+		}
+		if (ls1s0p1+invP1P2+(1023-997)*p2)%33 != 0 {
+			continue
+		}
+		if fixable(hrp, values, enc, []int{p1, p2}) {
+			return []int{p1, p2}
+		}
+	}
+	return nil
+}
+
+// fixable reports whether substituting the values at the positions, counted
+// from the end, can make the checksum valid.
+func fixable(hrp string, values []byte, enc Encoding, pos []int) bool {
+	v := append([]byte(nil), values...)
+	var try func(k int) bool
+	try = func(k int) bool {
+		if k == len(pos) {
+			return residue(hrp, v, enc) == 0
+		}
+		i := len(v) - 1 - pos[k]
+		orig := v[i]
+		for c := byte(0); c < 32; c++ {
+			if c == orig {
+				continue
+			}
+			v[i] = c
+			if try(k + 1) {
+				return true
+			}
+		}
+		v[i] = orig
+		return false
+	}
+	return try(0)
+}
+
+// ConvertBits regroups a sequence of fromBits-bit values into toBits-bit
+// values.  With pad, a partial last group is padded with zero bits; without
+// it, leftover bits must be fewer than fromBits and zero, as BIP173 requires
+// when decoding.
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	if fromBits < 1 || fromBits > 8 || toBits < 1 || toBits > 8 {
+		return nil, fmt.Errorf("bech32: invalid bit group sizes %d and %d", fromBits, toBits)
+	}
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, (len(data)*int(fromBits)+int(toBits)-1)/int(toBits))
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, fmt.Errorf("bech32: value %d does not fit in %d bits", v, fromBits)
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits {
+		return nil, fmt.Errorf("bech32: more than %d bits of padding", fromBits-1)
+	} else if acc<<(toBits-bits)&maxv != 0 {
+		return nil, fmt.Errorf("bech32: non-zero padding")
+	}
+	return out, nil
+}
@@ -0,0 +1,244 @@
+package bech32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Valid strings from BIP173 and BIP350.
+var validStrings = []struct {
+	s   string
+	enc Encoding
+}{
+	{"A12UEL5L", Bech32},
+	{"a12uel5l", Bech32},
+	{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", Bech32},
+	{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", Bech32},
+	{"11" + strings.Repeat("q", 82) + "c8247j", Bech32},
+	{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", Bech32},
+	{"?1ezyfcl", Bech32},
+	{"A1LQFN3A", Bech32m},
+	{"a1lqfn3a", Bech32m},
+	{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", Bech32m},
+	{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", Bech32m},
+	{"11" + strings.Repeat("l", 83) + "udsr8", Bech32m},
+	{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", Bech32m},
+	{"?1v759aa", Bech32m},
+}
+
+// Invalid strings from BIP173 and BIP350.
+var invalidStrings = []string{
+	"\x201nwldj5",
+	"\x7f1axkwrx",
+	"\x801eym55h",
+	"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+	"pzry9x0s0muk",
+	"1pzry9x0s0muk",
+	"x1b4n0q5v",
+	"li1dgmt3",
+	"de1lg7wt\xff",
+	"A1G7SGD8",
+	"10a06t8",
+	"1qzzfhee",
+	"\x201xj0phk",
+	"\x7f1g6xzxy",
+	"\x801vctc34",
+	"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4",
+	"qyrz8wqd2c9m",
+	"1qyrz8wqd2c9m",
+	"y1b0jsk6g",
+	"lt1igcx5c0",
+	"in1muywd",
+	"mm1crxm3i",
+	"au1s5cgom",
+	"M1VUXWEZ",
+	"16plkw9",
+	"1p2gdwpf",
+}
+
+func TestDecode(t *testing.T) {
+	for _, test := range validStrings {
+		hrp, data, enc, err := Decode(test.s)
+		if err != nil {
+			t.Errorf("%q: %v", test.s, err)
+			continue
+		}
+		if enc != test.enc {
+			t.Errorf("%q: got %v, want %v", test.s, enc, test.enc)
+		}
+		got, err := Encode(hrp, data, enc)
+		if err != nil || got != strings.ToLower(test.s) {
+			t.Errorf("%q: re-encodes to %q, %v", test.s, got, err)
+		}
+	}
+	for _, s := range invalidStrings {
+		if _, _, _, err := Decode(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		} else if _, ok := err.(*DecodeError); !ok {
+			t.Errorf("%q: error %v is not a *DecodeError", s, err)
+		}
+	}
+}
+
+// Segwit addresses from BIP350 with their output scripts.
+var validAddresses = []struct {
+	addr, script string
+}{
+	{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+	{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"BC1SW50QGDZ25J", "6002751e"},
+	{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "5210751e76e8199196d454941c45d1b3a323"},
+	{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+}
+
+var invalidAddresses = []string{
+	"tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut",
+	"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+	"tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf",
+	"BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL",
+	"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+	"tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47",
+	"bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4",
+	"BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R",
+	"bc1pw5dgrnzv",
+	"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav",
+	"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",
+	"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq",
+	"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf",
+	"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j",
+	"bc1gmk9yu",
+}
+
+func TestSegwitAddress(t *testing.T) {
+	for _, test := range validAddresses {
+		hrp := strings.ToLower(test.addr[:2])
+		version, program, err := DecodeSegwitAddress(hrp, test.addr)
+		if err != nil {
+			t.Errorf("%s: %v", test.addr, err)
+			continue
+		}
+		script, err := SegwitScriptPubKey(version, program)
+		if err != nil || hex.EncodeToString(script) != test.script {
+			t.Errorf("%s: script %x, %v, want %s", test.addr, script, err, test.script)
+		}
+		addr, err := EncodeSegwitAddress(hrp, version, program)
+		if err != nil || addr != strings.ToLower(test.addr) {
+			t.Errorf("%s: re-encodes to %s, %v", test.addr, addr, err)
+		}
+	}
+	for _, addr := range invalidAddresses {
+		for _, hrp := range []string{"bc", "tb"} {
+			if _, _, err := DecodeSegwitAddress(hrp, addr); err == nil {
+				t.Errorf("%s: expected an error for %s", addr, hrp)
+			}
+		}
+	}
+
+	// Encoding applies the same program rules.
+	for _, test := range []struct {
+		version byte
+		program []byte
+	}{
+		{0, make([]byte, 21)},
+		{1, make([]byte, 1)},
+		{1, make([]byte, 41)},
+		{17, make([]byte, 32)},
+	} {
+		if _, err := EncodeSegwitAddress("bc", test.version, test.program); err == nil {
+			t.Errorf("version %d, %d bytes: expected an error", test.version, len(test.program))
+		}
+	}
+}
+
+// TestLocateErrors checks that one or two substituted characters are found
+// in valid addresses of both encodings.
+func TestLocateErrors(t *testing.T) {
+	addrs := []string{
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+	}
+	substitute := func(s string, i int) string {
+		c := charset[(charsetRev[s[i]]+7)%32]
+		return s[:i] + string(c) + s[i+1:]
+	}
+	for _, addr := range addrs {
+		_, _, enc, _ := Decode(addr)
+		for i := 3; i < len(addr); i++ {
+			checkErrors(t, substitute(addr, i), []int{i}, enc)
+			for j := i + 5; j < len(addr); j += 11 {
+				checkErrors(t, substitute(substitute(addr, i), j), []int{i, j}, enc)
+			}
+		}
+	}
+
+	// Non-checksum errors point at the characters too.
+	checkErrors(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3tb", []int{41}, 0)
+	checkErrors(t, "bc1qw508D6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", []int{8}, 0)
+	checkErrors(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t"+"\x00", []int{41}, 0)
+}
+
+// checkErrors checks the positions reported for s.  A string with two errors
+// can also be two substitutions away from a valid string of the other
+// encoding, in which case either answer is right.
+func checkErrors(t *testing.T, s string, want []int, enc Encoding) {
+	t.Helper()
+	_, _, _, err := Decode(s)
+	derr, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("%s: got %v, want a *DecodeError", s, err)
+	}
+	if equalInts(derr.Positions, want) {
+		return
+	}
+	if len(want) == 2 && len(derr.Positions) == 2 {
+		sep := strings.LastIndexByte(s, '1')
+		values := make([]byte, len(s)-sep-1)
+		for i := range values {
+			values[i] = byte(charsetRev[s[sep+1+i]])
+		}
+		other := Bech32
+		if enc == Bech32 {
+			other = Bech32m
+		}
+		pos := []int{len(s) - 1 - derr.Positions[0], len(s) - 1 - derr.Positions[1]}
+		if fixable(s[:sep], values, other, pos) {
+			return
+		}
+	}
+	t.Fatalf("%s: got positions %v, want %v", s, derr.Positions, want)
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestConvertBits(t *testing.T) {
+	data := []byte{0xff, 0x00, 0xa5, 0x5a, 0x01}
+	five, err := ConvertBits(data, 8, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eight, err := ConvertBits(five, 5, 8, false)
+	if err != nil || !bytes.Equal(eight, data) {
+		t.Errorf("round trip: got %x, %v", eight, err)
+	}
+	if _, err := ConvertBits([]byte{32}, 5, 8, true); err == nil {
+		t.Error("expected an error for a value over 5 bits")
+	}
+	if _, err := ConvertBits([]byte{1}, 5, 8, false); err == nil {
+		t.Error("expected an error for non-zero padding")
+	}
+}
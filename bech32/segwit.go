@@ -0,0 +1,77 @@
+package bech32
+
+import "fmt"
+import "strings"
+
+// EncodeSegwitAddress returns the address of the witness program for the
+// HRP of a network, such as "bc" for bitcoin.  Version 0 programs must be 20
+// (P2WPKH) or 32 (P2WSH) bytes and are encoded with bech32; versions 1 to 16
+// (version 1 is taproot) take 2 to 40 bytes and are encoded with bech32m.
+func EncodeSegwitAddress(hrp string, version byte, program []byte) (string, error) {
+	if err := checkWitnessProgram(version, program); err != nil {
+		return "", err
+	}
+	data, _ := ConvertBits(program, 8, 5, true)
+	enc := Bech32m
+	if version == 0 {
+		enc = Bech32
+	}
+	return Encode(hrp, append([]byte{version}, data...), enc)
+}
+
+// DecodeSegwitAddress parses the address of a witness program for the HRP of
+// a network and returns the witness version and program.
+func DecodeSegwitAddress(hrp, addr string) (version byte, program []byte, err error) {
+	gotHRP, data, enc, err := Decode(addr)
+	if err != nil {
+		return 0, nil, err
+	}
+	if gotHRP != strings.ToLower(hrp) {
+		return 0, nil, fmt.Errorf("bech32: human-readable part %q, expected %q", gotHRP, hrp)
+	}
+	if len(data) == 0 {
+		return 0, nil, fmt.Errorf("bech32: empty data section")
+	}
+	version = data[0]
+	if version > 16 {
+		return 0, nil, fmt.Errorf("bech32: invalid witness version %d", version)
+	}
+	if (version == 0) != (enc == Bech32) {
+		return 0, nil, fmt.Errorf("bech32: witness version %d with %v checksum", version, enc)
+	}
+	program, err = ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := checkWitnessProgram(version, program); err != nil {
+		return 0, nil, err
+	}
+	return version, program, nil
+}
+
+// SegwitScriptPubKey returns the output script paying to the witness
+// program: the version opcode followed by a push of the program.
+func SegwitScriptPubKey(version byte, program []byte) ([]byte, error) {
+	if err := checkWitnessProgram(version, program); err != nil {
+		return nil, err
+	}
+	op := byte(0x00) // OP_0
+	if version > 0 {
+		op = 0x50 + version // OP_1 to OP_16
+	}
+	return append([]byte{op, byte(len(program))}, program...), nil
+}
+
+// checkWitnessProgram applies the BIP141 length rules.
+func checkWitnessProgram(version byte, program []byte) error {
+	if version > 16 {
+		return fmt.Errorf("bech32: invalid witness version %d", version)
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return fmt.Errorf("bech32: invalid witness program length %d", len(program))
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return fmt.Errorf("bech32: invalid witness version 0 program length %d", len(program))
+	}
+	return nil
+}
@@ -67,6 +67,7 @@ func NewMnemonic(size int) (string, error) {
 	if e != nil{
 		return "", e
 	}
+	defer zeroBytes(entropy)
 	//熵长度
 	var entropyBitLength = len(entropy) * 8
 	//校验位
@@ -78,8 +79,9 @@ func NewMnemonic(size int) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	entropy = addChecksum(entropy)
-	entropyInt := new(big.Int).SetBytes(entropy)
+	checksummed := addChecksum(entropy)
+	defer zeroBytes(checksummed)
+	entropyInt := new(big.Int).SetBytes(checksummed)
 	words := make([]string, sentenceLength)
 	word := big.NewInt(0)
 	for i := sentenceLength - 1; i >= 0; i-- {
@@ -166,15 +168,36 @@ func compareByteSlices(a, b []byte) bool {
 	return true
 }
 
-func NewSeed(mnemonic string, pwd string) (seed []byte, e error){
+// Seed 是由助记词推导出的种子，用完后应调用 Zero 清除。
+//
+// A Seed is a []byte, so it can be passed to hdkeychain.NewMaster directly.
+type Seed []byte
+
+// Zero wipes the seed.
+func (s Seed) Zero() {
+	zeroBytes(s)
+}
+
+func NewSeed(mnemonic string, pwd string) (seed Seed, e error){
 	if err := checkMnemonic(mnemonic); err != nil{
 		e = err
 		return seed, e
 	}
-	seed = pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+pwd), 2048, 64, sha512.New)
+	password := []byte(mnemonic)
+	salt := []byte("mnemonic"+pwd)
+	defer zeroBytes(password)
+	defer zeroBytes(salt)
+	seed = pbkdf2.Key(password, salt, 2048, 64, sha512.New)
 	return seed, nil
 }
 
+// zeroBytes overwrites b.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
 // 验证助记词是否数量合法且在助记词单词列表中
 func IsMnemonicValid(mnemonic string) bool {
 	words := strings.Fields(mnemonic)
@@ -0,0 +1,41 @@
+package bip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSeedZero(t *testing.T) {
+	SetWordList(English)
+	mnemonic, err := NewMnemonic(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := NewSeed(mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seed) != 64 || bytes.Equal(seed, make([]byte, 64)) {
+		t.Fatalf("unexpected seed %x", seed)
+	}
+	seed.Zero()
+	if !bytes.Equal(seed, make([]byte, 64)) {
+		t.Errorf("seed was not wiped: %x", seed)
+	}
+}
+
+func TestNewSeedVector(t *testing.T) {
+	// From the BIP39 test vectors.
+	SetWordList(English)
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+	seed, err := NewSeed(mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer seed.Zero()
+	if got := hex.EncodeToString(seed); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
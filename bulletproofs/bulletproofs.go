@@ -0,0 +1,383 @@
+// Bulletproofs 范围证明: 证明 Pedersen 承诺里的金额落在 [0, 2^bits) 之内, 而不
+// 泄露金额本身.  m 个承诺可以聚合成一个证明, 证明大小只随 log(bits*m) 增长.
+//
+// This is the aggregated logarithmic range proof of section 4.3 of the
+// Bulletproofs paper, made non-interactive with a Fiat-Shamir transcript of
+// tagged SHA-256 hashes.  Values are committed with the pedersen package:
+// V = gamma*G + v*pedersen.H.
+//
+// https://eprint.iacr.org/2017/1066
+
+package bulletproofs
+
+import "crypto/rand"
+import "fmt"
+import "io"
+import "math/big"
+
+// import "../pedersen"
+import "github.com/symphonyprotocol/sutil/pedersen"
+
+// MaxAggregation is the largest number of values one proof can cover.
+const MaxAggregation = 64
+
+var (
+	ErrInvalidBits        = fmt.Errorf("bulletproofs: bit size must be 8, 16, 32 or 64")
+	ErrInvalidAggregation = fmt.Errorf("bulletproofs: number of values must be a power of two no greater than 64")
+	ErrValueOutOfRange    = fmt.Errorf("bulletproofs: value does not fit in the bit size")
+	ErrMalformedProof     = fmt.Errorf("bulletproofs: malformed range proof")
+)
+
+// RangeProof proves that each of a list of commitments hides a value in
+// [0, 2^bits).
+type RangeProof struct {
+	a, s, t1, t2 point
+	taux, mu, t  *big.Int
+
+	// inner product argument
+	ls, rs []point
+	ipA    *big.Int
+	ipB    *big.Int
+}
+
+// Prove creates an aggregated range proof that every value is less than
+// 2^bits, along with the commitments blinds[i]*G + values[i]*H it is a proof
+// for.  The number of values must be a power of two; callers with other
+// counts can pad with commitments to zero.  Randomness is read from r, or
+// from crypto/rand if r is nil.
+func Prove(values []uint64, blinds []*pedersen.BlindingFactor, bits int,
+	r io.Reader) (*RangeProof, []*pedersen.Commitment, error) {
+	m := len(values)
+	if err := checkParams(bits, m); err != nil {
+		return nil, nil, err
+	}
+	if len(blinds) != m {
+		return nil, nil, fmt.Errorf("bulletproofs: %d values but %d blinding factors", m, len(blinds))
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+	n := bits * m
+	gs, hs := generators(n)
+
+	gammas := make([]*big.Int, m)
+	commitments := make([]*pedersen.Commitment, m)
+	for j, v := range values {
+		if bits < 64 && v>>uint(bits) != 0 {
+			return nil, nil, ErrValueOutOfRange
+		}
+		gamma, err := blinds[j].Scalar()
+		if err != nil {
+			return nil, nil, err
+		}
+		gammas[j] = gamma
+		commitments[j] = pedersen.CommitScalar(new(big.Int).SetUint64(v), gamma)
+	}
+
+	t := newTranscript(bits, m)
+	for _, c := range commitments {
+		t.append(c.Serialize())
+	}
+
+	// aL are the bits of the values, aR = aL - 1.
+	aL := make([]*big.Int, n)
+	aR := make([]*big.Int, n)
+	one := big.NewInt(1)
+	for j, v := range values {
+		for i := 0; i < bits; i++ {
+			bit := big.NewInt(int64(v >> uint(i) & 1))
+			aL[j*bits+i] = bit
+			aR[j*bits+i] = modSub(bit, one)
+		}
+	}
+
+	alpha, err := randomScalar(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A = alpha*G + <aL, g> + <aR, h>, with aL, aR in {0, 1, -1}.
+	proof := &RangeProof{a: basePoint().mul(alpha)}
+	for i := range aL {
+		if aL[i].Sign() != 0 {
+			proof.a = proof.a.add(gs[i])
+		} else {
+			proof.a = proof.a.add(hs[i].neg())
+		}
+	}
+
+	rho, err := randomScalar(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	sL, err := randomScalars(r, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	sR, err := randomScalars(r, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof.s = basePoint().mul(rho).add(multiExp(append(append([]*big.Int{}, sL...), sR...),
+		append(append([]point{}, gs...), hs...)))
+
+	t.appendPoints(proof.a, proof.s)
+	y := t.challenge()
+	z := t.challenge()
+
+	yN := powers(y, n)
+	d := bitWeights(z, bits, m)
+
+	// l(X) = l0 + l1*X, r(X) = r0 + r1*X
+	l0 := make([]*big.Int, n)
+	r0 := make([]*big.Int, n)
+	r1 := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		l0[i] = modSub(aL[i], z)
+		r0[i] = modAdd(modMul(yN[i], modAdd(aR[i], z)), d[i])
+		r1[i] = modMul(yN[i], sR[i])
+	}
+	t1 := modAdd(innerProduct(l0, r1), innerProduct(sL, r0))
+	t2 := innerProduct(sL, r1)
+
+	tau1, err := randomScalar(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	tau2, err := randomScalar(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	c1 := pedersen.CommitScalar(t1, tau1)
+	c2 := pedersen.CommitScalar(t2, tau2)
+	proof.t1 = point{c1.X, c1.Y}
+	proof.t2 = point{c2.X, c2.Y}
+
+	t.appendPoints(proof.t1, proof.t2)
+	x := t.challenge()
+
+	l := make([]*big.Int, n)
+	rv := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		l[i] = modAdd(l0[i], modMul(sL[i], x))
+		rv[i] = modAdd(r0[i], modMul(r1[i], x))
+	}
+	proof.t = innerProduct(l, rv)
+
+	// taux = tau2*x^2 + tau1*x + sum(z^(2+j) * gamma_j)
+	proof.taux = modAdd(modMul(tau2, modMul(x, x)), modMul(tau1, x))
+	zj := modMul(z, z)
+	for _, gamma := range gammas {
+		proof.taux = modAdd(proof.taux, modMul(zj, gamma))
+		zj = modMul(zj, z)
+	}
+	proof.mu = modAdd(alpha, modMul(rho, x))
+
+	t.appendScalars(proof.taux, proof.mu, proof.t)
+	w := t.challenge()
+
+	// h'_i = y^-i * h_i
+	yInv := modInv(y)
+	hPrime := make([]point, n)
+	for i, yi := range powers(yInv, n) {
+		hPrime[i] = hs[i].mul(yi)
+	}
+	proof.ls, proof.rs, proof.ipA, proof.ipB = proveInnerProduct(t, gs, hPrime, genU.mul(w), l, rv)
+	return proof, commitments, nil
+}
+
+// Verify reports whether the proof shows that every commitment hides a value
+// in [0, 2^bits).
+func (proof *RangeProof) Verify(commitments []*pedersen.Commitment, bits int) bool {
+	m := len(commitments)
+	if checkParams(bits, m) != nil {
+		return false
+	}
+	n := bits * m
+	if 1<<uint(len(proof.ls)) != n || len(proof.rs) != len(proof.ls) {
+		return false
+	}
+	gs, hs := generators(n)
+
+	t := newTranscript(bits, m)
+	for _, c := range commitments {
+		t.append(c.Serialize())
+	}
+	t.appendPoints(proof.a, proof.s)
+	y := t.challenge()
+	z := t.challenge()
+	t.appendPoints(proof.t1, proof.t2)
+	x := t.challenge()
+	t.appendScalars(proof.taux, proof.mu, proof.t)
+	w := t.challenge()
+
+	yN := powers(y, n)
+	d := bitWeights(z, bits, m)
+	z2 := modMul(z, z)
+
+	// t*H + taux*G == sum(z^(2+j) * V_j) + delta(y, z)*H + x*T1 + x^2*T2
+	// delta(y, z) = (z - z^2) * <1, y^n> - sum(z^(3+j)) * <1, 2^bits>
+	sumY := new(big.Int)
+	for _, yi := range yN {
+		sumY.Add(sumY, yi)
+	}
+	sum2 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+	delta := modMul(modSub(z, z2), sumY)
+	zj := modMul(z2, z)
+	for j := 0; j < m; j++ {
+		delta = modSub(delta, modMul(zj, sum2))
+		zj = modMul(zj, z)
+	}
+
+	hGen := pointFromPubKey(pedersen.H)
+	scalars := []*big.Int{modSub(proof.t, delta), proof.taux, modNeg(x), modNeg(modMul(x, x))}
+	points := []point{hGen, basePoint(), proof.t1, proof.t2}
+	zj = z2
+	for _, c := range commitments {
+		scalars = append(scalars, modNeg(zj))
+		points = append(points, point{c.X, c.Y})
+		zj = modMul(zj, z)
+	}
+	if !multiExp(scalars, points).isInfinity() {
+		return false
+	}
+
+	// The inner product argument, with the commitment P to l and r rebuilt
+	// from A and S, checked in a single multi-exponentiation:
+	//   sum((a*s_i + z) * g_i) + sum((y^-i * (b/s_i - d_i) - z) * h_i)
+	//   + w*(a*b - t)*U + mu*G - A - x*S - sum(x_k^2*L_k + x_k^-2*R_k) == 0
+	s, xs := innerProductScalars(t, proof.ls, proof.rs, n)
+	yInvN := powers(modInv(y), n)
+	scalars = scalars[:0]
+	points = points[:0]
+	for i := 0; i < n; i++ {
+		scalars = append(scalars, modAdd(modMul(proof.ipA, s[i]), z))
+		points = append(points, gs[i])
+		hs1 := modMul(proof.ipB, s[n-1-i])
+		scalars = append(scalars, modSub(modMul(yInvN[i], modSub(hs1, d[i])), z))
+		points = append(points, hs[i])
+	}
+	scalars = append(scalars,
+		modMul(w, modSub(modMul(proof.ipA, proof.ipB), proof.t)),
+		proof.mu, modNeg(big.NewInt(1)), modNeg(x))
+	points = append(points, genU, basePoint(), proof.a, proof.s)
+	for k := range proof.ls {
+		x2 := modMul(xs[k], xs[k])
+		scalars = append(scalars, modNeg(x2), modNeg(modInv(x2)))
+		points = append(points, proof.ls[k], proof.rs[k])
+	}
+	return multiExp(scalars, points).isInfinity()
+}
+
+// Serialize encodes the proof as A || S || T1 || T2 || taux || mu || t ||
+// (L_k || R_k)... || a || b, with points in compressed form.
+func (proof *RangeProof) Serialize() []byte {
+	b := make([]byte, 0, proofSize(len(proof.ls)))
+	for _, p := range []point{proof.a, proof.s, proof.t1, proof.t2} {
+		b = append(b, p.serialize()...)
+	}
+	for _, k := range []*big.Int{proof.taux, proof.mu, proof.t} {
+		b = append(b, k.FillBytes(make([]byte, 32))...)
+	}
+	for k := range proof.ls {
+		b = append(b, proof.ls[k].serialize()...)
+		b = append(b, proof.rs[k].serialize()...)
+	}
+	b = append(b, proof.ipA.FillBytes(make([]byte, 32))...)
+	return append(b, proof.ipB.FillBytes(make([]byte, 32))...)
+}
+
+// ParseRangeProof parses a proof encoded by Serialize.
+func ParseRangeProof(b []byte) (*RangeProof, error) {
+	rounds := (len(b) - proofSize(0)) / 66
+	if len(b) < proofSize(0) || proofSize(rounds) != len(b) || rounds > 12 {
+		return nil, ErrMalformedProof
+	}
+
+	var points []point
+	for len(points) < 4+2*rounds {
+		off := 33 * len(points)
+		if len(points) >= 4 {
+			off += 96
+		}
+		p, err := parsePoint(b[off : off+33])
+		if err != nil {
+			return nil, ErrMalformedProof
+		}
+		points = append(points, p)
+	}
+	var scalars []*big.Int
+	for _, off := range []int{132, 164, 196, len(b) - 64, len(b) - 32} {
+		k := new(big.Int).SetBytes(b[off : off+32])
+		if k.Cmp(order()) >= 0 {
+			return nil, ErrMalformedProof
+		}
+		scalars = append(scalars, k)
+	}
+
+	proof := &RangeProof{
+		a: points[0], s: points[1], t1: points[2], t2: points[3],
+		taux: scalars[0], mu: scalars[1], t: scalars[2],
+		ipA: scalars[3], ipB: scalars[4],
+	}
+	for k := 0; k < rounds; k++ {
+		proof.ls = append(proof.ls, points[4+2*k])
+		proof.rs = append(proof.rs, points[5+2*k])
+	}
+	return proof, nil
+}
+
+func proofSize(rounds int) int {
+	return 4*33 + 3*32 + rounds*66 + 2*32
+}
+
+func checkParams(bits, m int) error {
+	switch bits {
+	case 8, 16, 32, 64:
+	default:
+		return ErrInvalidBits
+	}
+	if m < 1 || m > MaxAggregation || m&(m-1) != 0 {
+		return ErrInvalidAggregation
+	}
+	return nil
+}
+
+// bitWeights returns d with d[j*bits+i] = z^(2+j) * 2^i, the weights that
+// tie the bits of value j to its commitment.
+func bitWeights(z *big.Int, bits, m int) []*big.Int {
+	d := make([]*big.Int, 0, bits*m)
+	zj := modMul(z, z)
+	two := big.NewInt(2)
+	for j := 0; j < m; j++ {
+		for _, p := range powers(two, bits) {
+			d = append(d, modMul(zj, p))
+		}
+		zj = modMul(zj, z)
+	}
+	return d
+}
+
+func randomScalar(r io.Reader) (*big.Int, error) {
+	var b [32]byte
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(b[:])
+		if k.Sign() > 0 && k.Cmp(order()) < 0 {
+			return k, nil
+		}
+	}
+}
+
+func randomScalars(r io.Reader, n int) ([]*big.Int, error) {
+	ks := make([]*big.Int, n)
+	for i := range ks {
+		k, err := randomScalar(r)
+		if err != nil {
+			return nil, err
+		}
+		ks[i] = k
+	}
+	return ks, nil
+}
@@ -0,0 +1,107 @@
+package bulletproofs
+
+import (
+	"testing"
+
+	"github.com/symphonyprotocol/sutil/pedersen"
+)
+
+func blindingFactors(t *testing.T, n int) []*pedersen.BlindingFactor {
+	bs := make([]*pedersen.BlindingFactor, n)
+	for i := range bs {
+		b, err := pedersen.NewBlindingFactor(nil)
+		if err != nil {
+			t.Fatalf("NewBlindingFactor: %v", err)
+		}
+		bs[i] = b
+	}
+	return bs
+}
+
+func TestRangeProof(t *testing.T) {
+	tests := []struct {
+		values []uint64
+		bits   int
+	}{
+		{[]uint64{0}, 8},
+		{[]uint64{255}, 8},
+		{[]uint64{1 << 31}, 32},
+		{[]uint64{^uint64(0)}, 64},
+		{[]uint64{3, 1 << 40}, 64},
+		{[]uint64{1, 2, 65535, 40000}, 16},
+	}
+	for i, test := range tests {
+		blinds := blindingFactors(t, len(test.values))
+		proof, commitments, err := Prove(test.values, blinds, test.bits, nil)
+		if err != nil {
+			t.Fatalf("#%d: Prove: %v", i, err)
+		}
+		for j, c := range commitments {
+			if !c.Open(test.values[j], blinds[j]) {
+				t.Fatalf("#%d: commitment %d does not open", i, j)
+			}
+		}
+		parsed, err := ParseRangeProof(proof.Serialize())
+		if err != nil {
+			t.Fatalf("#%d: ParseRangeProof: %v", i, err)
+		}
+		if !parsed.Verify(commitments, test.bits) {
+			t.Errorf("#%d: valid proof does not verify", i)
+		}
+
+		// The proof is bound to the commitments and the bit size.
+		other, _ := pedersen.Commit(test.values[0]+1, blinds[0])
+		tampered := append([]*pedersen.Commitment{other}, commitments[1:]...)
+		if parsed.Verify(tampered, test.bits) {
+			t.Errorf("#%d: proof verifies for another commitment", i)
+		}
+		if test.bits > 8 && parsed.Verify(commitments, test.bits/2) {
+			t.Errorf("#%d: proof verifies for another bit size", i)
+		}
+	}
+}
+
+func TestRangeProofRejects(t *testing.T) {
+	blinds := blindingFactors(t, 3)
+	if _, _, err := Prove([]uint64{256}, blinds[:1], 8, nil); err != ErrValueOutOfRange {
+		t.Errorf("value out of range: got %v, want %v", err, ErrValueOutOfRange)
+	}
+	if _, _, err := Prove([]uint64{1, 2, 3}, blinds, 8, nil); err != ErrInvalidAggregation {
+		t.Errorf("three values: got %v, want %v", err, ErrInvalidAggregation)
+	}
+	if _, _, err := Prove([]uint64{1}, blinds[:1], 12, nil); err != ErrInvalidBits {
+		t.Errorf("12 bits: got %v, want %v", err, ErrInvalidBits)
+	}
+
+	proof, commitments, err := Prove([]uint64{42}, blinds[:1], 8, nil)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+	b := proof.Serialize()
+	for _, off := range []int{140, 200, len(b) - 1} {
+		corrupt := append([]byte{}, b...)
+		corrupt[off] ^= 1
+		if p, err := ParseRangeProof(corrupt); err == nil && p.Verify(commitments, 8) {
+			t.Errorf("corrupted proof (byte %d) verifies", off)
+		}
+	}
+	if _, err := ParseRangeProof(b[:len(b)-1]); err != ErrMalformedProof {
+		t.Errorf("truncated proof: got %v, want %v", err, ErrMalformedProof)
+	}
+}
+
+func BenchmarkProve64(b *testing.B) {
+	blind, _ := pedersen.NewBlindingFactor(nil)
+	for i := 0; i < b.N; i++ {
+		Prove([]uint64{uint64(i)}, []*pedersen.BlindingFactor{blind}, 64, nil)
+	}
+}
+
+func BenchmarkVerify64(b *testing.B) {
+	blind, _ := pedersen.NewBlindingFactor(nil)
+	proof, commitments, _ := Prove([]uint64{12345}, []*pedersen.BlindingFactor{blind}, 64, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proof.Verify(commitments, 64)
+	}
+}
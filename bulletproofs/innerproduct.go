@@ -0,0 +1,75 @@
+package bulletproofs
+
+import "math/big"
+
+// proveInnerProduct runs the inner product argument of section 3 of the
+// Bulletproofs paper for P = <a, g> + <b, h> + <a, b>*u, halving the vectors
+// in every round.  It returns the L and R points of every round and the
+// final one element vectors a and b.
+func proveInnerProduct(t *transcript, g, h []point, u point, a, b []*big.Int) ([]point, []point, *big.Int, *big.Int) {
+	g = append([]point{}, g...)
+	h = append([]point{}, h...)
+	a = append([]*big.Int{}, a...)
+	b = append([]*big.Int{}, b...)
+
+	var ls, rs []point
+	for n := len(a); n > 1; n /= 2 {
+		half := n / 2
+		aLo, aHi := a[:half], a[half:n]
+		bLo, bHi := b[:half], b[half:n]
+		gLo, gHi := g[:half], g[half:n]
+		hLo, hHi := h[:half], h[half:n]
+
+		cL := innerProduct(aLo, bHi)
+		cR := innerProduct(aHi, bLo)
+		l := multiExp(append(append(append([]*big.Int{}, aLo...), bHi...), cL),
+			append(append(append([]point{}, gHi...), hLo...), u))
+		r := multiExp(append(append(append([]*big.Int{}, aHi...), bLo...), cR),
+			append(append(append([]point{}, gLo...), hHi...), u))
+		ls = append(ls, l)
+		rs = append(rs, r)
+
+		t.appendPoints(l, r)
+		x := t.challenge()
+		xInv := modInv(x)
+
+		for i := 0; i < half; i++ {
+			a[i] = modAdd(modMul(aLo[i], x), modMul(aHi[i], xInv))
+			b[i] = modAdd(modMul(bLo[i], xInv), modMul(bHi[i], x))
+			g[i] = gLo[i].mul(xInv).add(gHi[i].mul(x))
+			h[i] = hLo[i].mul(x).add(hHi[i].mul(xInv))
+		}
+	}
+	return ls, rs, a[0], b[0]
+}
+
+// innerProductScalars replays the challenges of the inner product argument
+// and returns, for every generator g_i, the scalar s_i it was multiplied by
+// while folding the vectors: the product over all rounds of x_k if i was in
+// the high half in round k and of 1/x_k otherwise.  h_i was multiplied by
+// 1/s_i, which is s_(n-1-i).  It also returns the challenges.
+func innerProductScalars(t *transcript, ls, rs []point, n int) ([]*big.Int, []*big.Int) {
+	rounds := len(ls)
+	xs := make([]*big.Int, rounds)
+	xInvs := make([]*big.Int, rounds)
+	for k := range ls {
+		t.appendPoints(ls[k], rs[k])
+		xs[k] = t.challenge()
+		xInvs[k] = modInv(xs[k])
+	}
+
+	s := make([]*big.Int, n)
+	for i := range s {
+		acc := big.NewInt(1)
+		for k := 0; k < rounds; k++ {
+			// Round k splits on bit (rounds-1-k) of the index.
+			if i>>(uint(rounds-1-k))&1 == 1 {
+				acc = modMul(acc, xs[k])
+			} else {
+				acc = modMul(acc, xInvs[k])
+			}
+		}
+		s[i] = acc
+	}
+	return s, xs
+}
@@ -0,0 +1,186 @@
+package bulletproofs
+
+import "encoding/binary"
+import "math/big"
+import "sync"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// import "../pedersen"
+import "github.com/symphonyprotocol/sutil/pedersen"
+
+// point is an affine point, (0, 0) being the point at infinity as in the
+// rest of the elliptic package.
+type point struct {
+	x, y *big.Int
+}
+
+func infinity() point {
+	return point{new(big.Int), new(big.Int)}
+}
+
+func basePoint() point {
+	return point{ec.S256().Gx, ec.S256().Gy}
+}
+
+func pointFromPubKey(p *ec.PublicKey) point {
+	return point{p.X, p.Y}
+}
+
+func (p point) isInfinity() bool {
+	return p.x.Sign() == 0 && p.y.Sign() == 0
+}
+
+func (p point) add(q point) point {
+	x, y := ec.S256().Add(p.x, p.y, q.x, q.y)
+	return point{x, y}
+}
+
+func (p point) neg() point {
+	if p.isInfinity() {
+		return p
+	}
+	return point{p.x, new(big.Int).Sub(ec.S256().P, p.y)}
+}
+
+func (p point) mul(k *big.Int) point {
+	x, y := ec.S256().ScalarMult(p.x, p.y, k.FillBytes(make([]byte, 32)))
+	return point{x, y}
+}
+
+func (p point) serialize() []byte {
+	return (&ec.PublicKey{Curve: ec.S256(), X: p.x, Y: p.y}).SerializeCompressed()
+}
+
+func parsePoint(b []byte) (point, error) {
+	p, err := ec.ParsePubKey(b, ec.S256())
+	if err != nil {
+		return point{}, err
+	}
+	return point{p.X, p.Y}, nil
+}
+
+// multiExp returns sum(scalars[i] * points[i]).
+func multiExp(scalars []*big.Int, points []point) point {
+	xs := make([]*big.Int, len(points))
+	ys := make([]*big.Int, len(points))
+	ks := make([][]byte, len(scalars))
+	for i, p := range points {
+		xs[i], ys[i] = p.x, p.y
+		ks[i] = scalars[i].Bytes()
+	}
+	x, y := ec.S256().MultiScalarMult(xs, ys, ks)
+	return point{x, y}
+}
+
+// Scalar arithmetic mod N.
+
+func order() *big.Int {
+	return ec.S256().N
+}
+
+func modAdd(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, order())
+}
+
+func modSub(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, order())
+}
+
+func modMul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, order())
+}
+
+func modInv(a *big.Int) *big.Int {
+	return new(big.Int).ModInverse(a, order())
+}
+
+func modNeg(a *big.Int) *big.Int {
+	r := new(big.Int).Neg(a)
+	return r.Mod(r, order())
+}
+
+// powers returns [1, x, x^2, ..., x^(n-1)].
+func powers(x *big.Int, n int) []*big.Int {
+	p := make([]*big.Int, n)
+	acc := big.NewInt(1)
+	for i := range p {
+		p[i] = acc
+		acc = modMul(acc, x)
+	}
+	return p
+}
+
+func innerProduct(a, b []*big.Int) *big.Int {
+	sum := new(big.Int)
+	for i := range a {
+		sum.Add(sum, new(big.Int).Mul(a[i], b[i]))
+	}
+	return sum.Mod(sum, order())
+}
+
+// transcript turns the interactive protocol into a non-interactive one with
+// the Fiat-Shamir heuristic: every challenge is a hash of everything the
+// prover sent before it.
+type transcript struct {
+	state []byte
+}
+
+func newTranscript(bits, m int) *transcript {
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(bits))
+	binary.BigEndian.PutUint32(b[4:], uint32(m))
+	return &transcript{state: ec.TaggedHash("Bulletproofs/rangeproof", b[:])}
+}
+
+func (t *transcript) append(data ...[]byte) {
+	t.state = ec.TaggedHash("Bulletproofs/transcript", append([][]byte{t.state}, data...)...)
+}
+
+func (t *transcript) appendPoints(points ...point) {
+	for _, p := range points {
+		t.append(p.serialize())
+	}
+}
+
+func (t *transcript) appendScalars(scalars ...*big.Int) {
+	for _, k := range scalars {
+		t.append(k.FillBytes(make([]byte, 32)))
+	}
+}
+
+// challenge returns a non-zero scalar derived from the transcript so far.
+func (t *transcript) challenge() *big.Int {
+	for {
+		t.state = ec.TaggedHash("Bulletproofs/challenge", t.state)
+		c := new(big.Int).SetBytes(t.state)
+		c.Mod(c, order())
+		if c.Sign() != 0 {
+			return c
+		}
+	}
+}
+
+// Generator vectors, derived on demand and shared by all proofs.
+var (
+	genMu      sync.Mutex
+	genG, genH []point
+	genU       = pointFromPubKey(pedersen.GeneratorFromSeed([]byte("Bulletproofs/U")))
+)
+
+// generators returns the first n points of the G and H generator vectors.
+func generators(n int) ([]point, []point) {
+	genMu.Lock()
+	defer genMu.Unlock()
+	for i := len(genG); i < n; i++ {
+		var idx [4]byte
+		binary.BigEndian.PutUint32(idx[:], uint32(i))
+		genG = append(genG, pointFromPubKey(pedersen.GeneratorFromSeed(append([]byte("Bulletproofs/G"), idx[:]...))))
+		genH = append(genH, pointFromPubKey(pedersen.GeneratorFromSeed(append([]byte("Bulletproofs/H"), idx[:]...))))
+	}
+	return genG[:n:n], genH[:n:n]
+}
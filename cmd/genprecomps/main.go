@@ -0,0 +1,39 @@
+// genprecomps 生成 secp256k1 基点乘法的预计算表。
+//
+// The table holds every multiple 0..255 of G*2^(8*i) for each of the 32 byte
+// windows of a scalar and is embedded into the elliptic package, which uses
+// it to compute k*G with 32 point additions.  The generator does not use the
+// existing table, so it can rebuild it from scratch:
+//
+//	cd elliptic && go generate
+//
+// It also prints the endomorphism vectors hard-coded in the curve
+// parameters so they can be checked.
+package main
+
+import "flag"
+import "fmt"
+import "log"
+import "os"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+func main() {
+	out := flag.String("o", "secp256k1_bytepoints.bin", "output file")
+	flag.Parse()
+
+	serialized := ec.S256().SerializedBytePoints()
+	if err := os.WriteFile(*out, serialized, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	a1, b1, a2, b2 := ec.S256().EndomorphismVectors()
+	fmt.Println("The following values are the computed linearly " +
+		"independent vectors needed to make use of the secp256k1 " +
+		"endomorphism:")
+	fmt.Printf("a1: %x\n", a1)
+	fmt.Printf("b1: %x\n", b1)
+	fmt.Printf("a2: %x\n", a2)
+	fmt.Printf("b2: %x\n", b2)
+}
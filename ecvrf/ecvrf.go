@@ -0,0 +1,201 @@
+// 可验证随机函数 (VRF): 持有私钥的人对输入 alpha 算出唯一的伪随机输出 beta 和一个
+// 证明 pi, 任何人都能用公钥验证 beta 确实来自这把私钥, 适合做出块人选举.
+//
+// This is ECVRF as specified in RFC 9381 section 5, instantiated on secp256k1
+// the way the RFC instantiates ECVRF-P256-SHA256-TAI on P-256:
+//
+//   suite_string           0xFE (not assigned by the RFC)
+//   encode_to_curve        try-and-increment, section 5.4.1.1
+//   nonce generation       RFC 6979 with SHA-256, section 5.4.2.1
+//   point_to_string        SEC1 compressed points, 33 bytes
+//   cLen, qLen             16, 32
+//
+// A proof is Gamma || c || s, 81 bytes, and the output beta is 32 bytes.
+// Proofs are deterministic: the same key and alpha always give the same pi.
+//
+// https://www.rfc-editor.org/rfc/rfc9381
+
+package ecvrf
+
+import "crypto/sha256"
+import "crypto/subtle"
+import "fmt"
+import "math/big"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+const (
+	// SuiteString identifies the ciphersuite in every hash.
+	SuiteString = 0xFE
+
+	// ProofSize is the length of a proof pi.
+	ProofSize = ptLen + cLen + qLen
+
+	// OutputSize is the length of the output beta.
+	OutputSize = sha256.Size
+
+	ptLen = 33
+	cLen  = 16
+	qLen  = 32
+)
+
+var (
+	ErrInvalidProof  = fmt.Errorf("ecvrf: malformed proof")
+	ErrEncodeToCurve = fmt.Errorf("ecvrf: no valid point found for input")
+)
+
+// Prove returns the proof pi and the output beta of priv for the input
+// alpha.
+func Prove(priv *ec.PrivateKey, alpha []byte) (pi, beta []byte) {
+	curve := ec.S256()
+	pub := priv.ECPubKey()
+
+	h, err := encodeToCurve(pub, alpha)
+	if err != nil {
+		// Happens with probability 2^-256.
+		panic(err)
+	}
+	hString := h.SerializeCompressed()
+
+	gx, gy := curve.ScalarMult(h.X, h.Y, priv.D.Bytes())
+	gamma := &ec.PublicKey{Curve: curve, X: gx, Y: gy}
+
+	hashed := sha256.Sum256(hString)
+	k := ec.NonceRFC6979(priv.D, hashed[:])
+	ux, uy := curve.ScalarBaseMult(k.Bytes())
+	vx, vy := curve.ScalarMult(h.X, h.Y, k.Bytes())
+
+	c := challenge(pub, h, gamma, point(ux, uy), point(vx, vy))
+	s := new(big.Int).Mul(c, priv.D)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+
+	pi = make([]byte, 0, ProofSize)
+	pi = append(pi, gamma.SerializeCompressed()...)
+	pi = append(pi, padded(c, cLen)...)
+	pi = append(pi, padded(s, qLen)...)
+	return pi, gammaToHash(gamma)
+}
+
+// Verify checks the proof pi for the input alpha under pub and returns the
+// output beta it proves.
+func Verify(pub *ec.PublicKey, alpha, pi []byte) (beta []byte, ok bool) {
+	curve := ec.S256()
+	if pub == nil || pub.X == nil || !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, false
+	}
+	gamma, c, s, err := decodeProof(pi)
+	if err != nil {
+		return nil, false
+	}
+	h, err := encodeToCurve(pub, alpha)
+	if err != nil {
+		return nil, false
+	}
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	negC := new(big.Int).Sub(curve.N, c).Bytes()
+	sbx, sby := curve.ScalarBaseMult(s.Bytes())
+	cyx, cyy := curve.ScalarMult(pub.X, pub.Y, negC)
+	ux, uy := curve.Add(sbx, sby, cyx, cyy)
+	shx, shy := curve.ScalarMult(h.X, h.Y, s.Bytes())
+	cgx, cgy := curve.ScalarMult(gamma.X, gamma.Y, negC)
+	vx, vy := curve.Add(shx, shy, cgx, cgy)
+
+	c2 := challenge(pub, h, gamma, point(ux, uy), point(vx, vy))
+	if subtle.ConstantTimeCompare(padded(c, cLen), padded(c2, cLen)) != 1 {
+		return nil, false
+	}
+	return gammaToHash(gamma), true
+}
+
+// ProofToHash returns the output beta of a proof without verifying it.  It
+// must only be used on proofs that have been verified.
+func ProofToHash(pi []byte) ([]byte, error) {
+	gamma, _, _, err := decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	return gammaToHash(gamma), nil
+}
+
+// encodeToCurve is ECVRF_encode_to_curve_try_and_increment with the public
+// key as salt.
+func encodeToCurve(pub *ec.PublicKey, alpha []byte) (*ec.PublicKey, error) {
+	salt := pub.SerializeCompressed()
+	buf := make([]byte, 0, 2+len(salt)+len(alpha)+2)
+	buf = append(buf, SuiteString, 0x01)
+	buf = append(buf, salt...)
+	buf = append(buf, alpha...)
+	for ctr := 0; ctr < 256; ctr++ {
+		hash := sha256.Sum256(append(buf, byte(ctr), 0x00))
+		h, err := ec.ParsePubKey(append([]byte{0x02}, hash[:]...), ec.S256())
+		if err == nil {
+			return h, nil
+		}
+	}
+	return nil, ErrEncodeToCurve
+}
+
+// challenge is ECVRF_challenge_generation: the first cLen bytes of the hash
+// of the five points, as an integer.
+func challenge(points ...*ec.PublicKey) *big.Int {
+	h := sha256.New()
+	h.Write([]byte{SuiteString, 0x02})
+	for _, p := range points {
+		h.Write(pointToString(p))
+	}
+	h.Write([]byte{0x00})
+	return new(big.Int).SetBytes(h.Sum(nil)[:cLen])
+}
+
+// gammaToHash is ECVRF_proof_to_hash given the decoded Gamma.  The cofactor
+// of secp256k1 is 1.
+func gammaToHash(gamma *ec.PublicKey) []byte {
+	h := sha256.New()
+	h.Write([]byte{SuiteString, 0x03})
+	h.Write(gamma.SerializeCompressed())
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+func decodeProof(pi []byte) (*ec.PublicKey, *big.Int, *big.Int, error) {
+	if len(pi) != ProofSize {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	if pi[0] != 0x02 && pi[0] != 0x03 {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	gamma, err := ec.ParsePubKey(pi[:ptLen], ec.S256())
+	if err != nil {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	c := new(big.Int).SetBytes(pi[ptLen : ptLen+cLen])
+	s := new(big.Int).SetBytes(pi[ptLen+cLen:])
+	if s.Cmp(ec.S256().N) >= 0 {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	return gamma, c, s, nil
+}
+
+func point(x, y *big.Int) *ec.PublicKey {
+	return &ec.PublicKey{Curve: ec.S256(), X: x, Y: y}
+}
+
+// pointToString returns the compressed encoding of p.  U and V can only be
+// the point at infinity for forged proofs; it is encoded as the single zero
+// byte of SEC1.
+func pointToString(p *ec.PublicKey) []byte {
+	if p.X.Sign() == 0 && p.Y.Sign() == 0 {
+		return []byte{0x00}
+	}
+	return p.SerializeCompressed()
+}
+
+func padded(k *big.Int, size int) []byte {
+	b := make([]byte, size)
+	kb := k.Bytes()
+	copy(b[size-len(kb):], kb)
+	return b
+}
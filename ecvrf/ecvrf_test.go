@@ -0,0 +1,120 @@
+package ecvrf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+func testKey(seed string) (*ec.PrivateKey, *ec.PublicKey) {
+	b := sha256.Sum256([]byte(seed))
+	return ec.PrivKeyFromBytes(ec.S256(), b[:])
+}
+
+func TestProveVerify(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		priv, pub := testKey("validator" + string(rune('a'+i)))
+		alpha := []byte("epoch " + string(rune('0'+i)))
+
+		pi, beta := Prove(priv, alpha)
+		if len(pi) != ProofSize || len(beta) != OutputSize {
+			t.Fatalf("#%d: got %d byte proof and %d byte output", i, len(pi), len(beta))
+		}
+		got, ok := Verify(pub, alpha, pi)
+		if !ok {
+			t.Fatalf("#%d: valid proof rejected", i)
+		}
+		if !bytes.Equal(got, beta) {
+			t.Fatalf("#%d: Verify returned %x, Prove returned %x", i, got, beta)
+		}
+		h, err := ProofToHash(pi)
+		if err != nil || !bytes.Equal(h, beta) {
+			t.Fatalf("#%d: ProofToHash returned %x, %v", i, h, err)
+		}
+
+		// Proofs are deterministic.
+		pi2, beta2 := Prove(priv, alpha)
+		if !bytes.Equal(pi, pi2) || !bytes.Equal(beta, beta2) {
+			t.Fatalf("#%d: proof is not deterministic", i)
+		}
+	}
+}
+
+func TestOutputsDiffer(t *testing.T) {
+	priv1, _ := testKey("validator a")
+	priv2, _ := testKey("validator b")
+	_, a := Prove(priv1, []byte("round 1"))
+	_, b := Prove(priv1, []byte("round 2"))
+	_, c := Prove(priv2, []byte("round 1"))
+	if bytes.Equal(a, b) || bytes.Equal(a, c) {
+		t.Fatal("outputs for different keys or inputs collide")
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	priv, pub := testKey("validator")
+	_, otherPub := testKey("other validator")
+	alpha := []byte("round 7")
+	pi, _ := Prove(priv, alpha)
+
+	if _, ok := Verify(otherPub, alpha, pi); ok {
+		t.Error("proof accepted under another key")
+	}
+	if _, ok := Verify(pub, []byte("round 8"), pi); ok {
+		t.Error("proof accepted for another input")
+	}
+	for _, i := range []int{1, ptLen, ptLen + cLen - 1, ptLen + cLen, ProofSize - 1} {
+		bad := append([]byte{}, pi...)
+		bad[i] ^= 0x01
+		if _, ok := Verify(pub, alpha, bad); ok {
+			t.Errorf("proof with byte %d flipped accepted", i)
+		}
+	}
+
+	// Gamma from another key's proof.
+	otherPriv, _ := testKey("other validator")
+	otherPi, _ := Prove(otherPriv, alpha)
+	bad := append(append([]byte{}, otherPi[:ptLen]...), pi[ptLen:]...)
+	if _, ok := Verify(pub, alpha, bad); ok {
+		t.Error("proof with substituted Gamma accepted")
+	}
+
+	// Malformed encodings.
+	if _, ok := Verify(pub, alpha, pi[:ProofSize-1]); ok {
+		t.Error("short proof accepted")
+	}
+	bad = append([]byte{}, pi...)
+	bad[0] = 0x04
+	if _, ok := Verify(pub, alpha, bad); ok {
+		t.Error("proof with bad point prefix accepted")
+	}
+	bad = append([]byte{}, pi...)
+	copy(bad[ptLen+cLen:], padded(new(big.Int).Add(ec.S256().N, big.NewInt(1)), qLen))
+	if _, err := ProofToHash(bad); err != ErrInvalidProof {
+		t.Errorf("s >= N: got %v, want %v", err, ErrInvalidProof)
+	}
+	if _, ok := Verify(&ec.PublicKey{Curve: ec.S256(), X: big.NewInt(1), Y: big.NewInt(1)}, alpha, pi); ok {
+		t.Error("proof accepted under a key off the curve")
+	}
+}
+
+func BenchmarkProve(b *testing.B) {
+	priv, _ := testKey("validator")
+	alpha := []byte("round 7")
+	for i := 0; i < b.N; i++ {
+		Prove(priv, alpha)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	priv, pub := testKey("validator")
+	alpha := []byte("round 7")
+	pi, _ := Prove(priv, alpha)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pub, alpha, pi)
+	}
+}
@@ -0,0 +1,319 @@
+package elliptic
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	// bech32 "../bech32"
+	"github.com/symphonyprotocol/sutil/bech32"
+)
+
+// ErrUnknownAddressType is returned by DecodeAddress for addresses that are
+// well formed but of no type known for the network.
+var ErrUnknownAddressType = errors.New("unknown address type for the network")
+
+// Address is a payment destination of one of the supported script types.
+// The concrete types are *AddressPubKeyHash, *AddressScriptHash,
+// *AddressWitnessPubKeyHash, *AddressWitnessScriptHash, *AddressTaproot and
+// *AddressSymphony.
+type Address interface {
+	// Encode returns the string encoding of the address.
+	Encode() string
+
+	// String is the same as Encode.
+	String() string
+
+	// Hash returns the payload the address commits to: the 20 byte
+	// hash160 of a key or script, the 32 byte SHA-256 of a witness script
+	// or the 32 byte x-only taproot output key.
+	Hash() []byte
+
+	// IsForNet reports whether the address belongs to the network.
+	IsForNet(net *NetParams) bool
+}
+
+// DecodeAddress parses an address of the network, detecting its type from
+// the encoding: bech32 addresses with the segwit human-readable part of the
+// network, and base58check addresses with its version bytes.
+func DecodeAddress(addr string, net *NetParams) (Address, error) {
+	hrp := net.Bech32HRPSegwit
+	if hrp != "" && len(addr) > len(hrp) && strings.EqualFold(addr[:len(hrp)+1], hrp+"1") {
+		version, program, err := bech32.DecodeSegwitAddress(hrp, addr)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case version == 0 && len(program) == 20:
+			return NewAddressWitnessPubKeyHash(program, net)
+		case version == 0 && len(program) == 32:
+			return NewAddressWitnessScriptHash(program, net)
+		case version == 1 && len(program) == 32:
+			return NewAddressTaproot(program, net)
+		}
+		return nil, ErrUnknownAddressType
+	}
+
+	ver, hash, err := b58checkdecode(addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) != 20 {
+		return nil, fmt.Errorf("invalid address payload length %d", len(hash))
+	}
+	switch {
+	case ver == net.PubKeyHashAddrID && net.NativeAddrs:
+		return NewAddressSymphony(hash)
+	case ver == net.PubKeyHashAddrID:
+		return NewAddressPubKeyHash(hash, net)
+	case ver == net.ScriptHashAddrID && !net.NativeAddrs:
+		return NewAddressScriptHashFromHash(hash, net)
+	}
+	return nil, ErrUnknownAddressType
+}
+
+// segwitHRP returns the segwit human-readable part of the network.
+func segwitHRP(net *NetParams) (string, error) {
+	if net.Bech32HRPSegwit == "" {
+		return "", fmt.Errorf("network %s has no segwit addresses", net.Name)
+	}
+	return net.Bech32HRPSegwit, nil
+}
+
+// checkHashLength returns an error unless hash has the expected length.
+func checkHashLength(hash []byte, want int) error {
+	if len(hash) != want {
+		return fmt.Errorf("address hash must be %d bytes, got %d", want, len(hash))
+	}
+	return nil
+}
+
+// AddressPubKeyHash is a pay-to-pubkey-hash (P2PKH) address.
+type AddressPubKeyHash struct {
+	hash  [20]byte
+	netID byte
+}
+
+// NewAddressPubKeyHash returns the P2PKH address of the hash160 of a
+// serialized public key on the network.
+func NewAddressPubKeyHash(hash []byte, net *NetParams) (*AddressPubKeyHash, error) {
+	if err := checkHashLength(hash, 20); err != nil {
+		return nil, err
+	}
+	a := &AddressPubKeyHash{netID: net.PubKeyHashAddrID}
+	copy(a.hash[:], hash)
+	return a, nil
+}
+
+// Encode returns the base58check encoding of the address.
+func (a *AddressPubKeyHash) Encode() string { return base58CheckEncode(a.netID, a.hash[:]) }
+
+// String is the same as Encode.
+func (a *AddressPubKeyHash) String() string { return a.Encode() }
+
+// Hash returns the hash160 of the public key.
+func (a *AddressPubKeyHash) Hash() []byte { return append([]byte(nil), a.hash[:]...) }
+
+// IsForNet reports whether the address belongs to the network.
+func (a *AddressPubKeyHash) IsForNet(net *NetParams) bool {
+	return !net.NativeAddrs && a.netID == net.PubKeyHashAddrID
+}
+
+// AddressScriptHash is a pay-to-script-hash (P2SH) address.
+type AddressScriptHash struct {
+	hash  [20]byte
+	netID byte
+}
+
+// NewAddressScriptHash returns the P2SH address of the redeem script on the
+// network.
+func NewAddressScriptHash(script []byte, net *NetParams) (*AddressScriptHash, error) {
+	return NewAddressScriptHashFromHash(Hash160(script), net)
+}
+
+// NewAddressScriptHashFromHash returns the P2SH address of the hash160 of a
+// redeem script on the network.
+func NewAddressScriptHashFromHash(hash []byte, net *NetParams) (*AddressScriptHash, error) {
+	if net.NativeAddrs {
+		return nil, fmt.Errorf("network %s has no pay-to-script-hash addresses", net.Name)
+	}
+	if err := checkHashLength(hash, 20); err != nil {
+		return nil, err
+	}
+	a := &AddressScriptHash{netID: net.ScriptHashAddrID}
+	copy(a.hash[:], hash)
+	return a, nil
+}
+
+// Encode returns the base58check encoding of the address.
+func (a *AddressScriptHash) Encode() string { return base58CheckEncode(a.netID, a.hash[:]) }
+
+// String is the same as Encode.
+func (a *AddressScriptHash) String() string { return a.Encode() }
+
+// Hash returns the hash160 of the redeem script.
+func (a *AddressScriptHash) Hash() []byte { return append([]byte(nil), a.hash[:]...) }
+
+// IsForNet reports whether the address belongs to the network.
+func (a *AddressScriptHash) IsForNet(net *NetParams) bool {
+	return !net.NativeAddrs && a.netID == net.ScriptHashAddrID
+}
+
+// AddressWitnessPubKeyHash is a segwit version 0 pay-to-witness-pubkey-hash
+// (P2WPKH) address.
+type AddressWitnessPubKeyHash struct {
+	hash [20]byte
+	hrp  string
+}
+
+// NewAddressWitnessPubKeyHash returns the P2WPKH address of the hash160 of
+// a compressed public key on the network.
+func NewAddressWitnessPubKeyHash(hash []byte, net *NetParams) (*AddressWitnessPubKeyHash, error) {
+	if err := checkHashLength(hash, 20); err != nil {
+		return nil, err
+	}
+	hrp, err := segwitHRP(net)
+	if err != nil {
+		return nil, err
+	}
+	a := &AddressWitnessPubKeyHash{hrp: hrp}
+	copy(a.hash[:], hash)
+	return a, nil
+}
+
+// Encode returns the bech32 encoding of the address.
+func (a *AddressWitnessPubKeyHash) Encode() string { return encodeSegwit(a.hrp, 0, a.hash[:]) }
+
+// String is the same as Encode.
+func (a *AddressWitnessPubKeyHash) String() string { return a.Encode() }
+
+// Hash returns the hash160 of the public key.
+func (a *AddressWitnessPubKeyHash) Hash() []byte { return append([]byte(nil), a.hash[:]...) }
+
+// IsForNet reports whether the address belongs to the network.
+func (a *AddressWitnessPubKeyHash) IsForNet(net *NetParams) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+
+// AddressWitnessScriptHash is a segwit version 0
+// pay-to-witness-script-hash (P2WSH) address.
+type AddressWitnessScriptHash struct {
+	hash [32]byte
+	hrp  string
+}
+
+// NewAddressWitnessScriptHash returns the P2WSH address of the SHA-256 of a
+// witness script on the network.
+func NewAddressWitnessScriptHash(hash []byte, net *NetParams) (*AddressWitnessScriptHash, error) {
+	if err := checkHashLength(hash, 32); err != nil {
+		return nil, err
+	}
+	hrp, err := segwitHRP(net)
+	if err != nil {
+		return nil, err
+	}
+	a := &AddressWitnessScriptHash{hrp: hrp}
+	copy(a.hash[:], hash)
+	return a, nil
+}
+
+// NewAddressWitnessScript returns the P2WSH address of a witness script on
+// the network.
+func NewAddressWitnessScript(script []byte, net *NetParams) (*AddressWitnessScriptHash, error) {
+	hash := sha256.Sum256(script)
+	return NewAddressWitnessScriptHash(hash[:], net)
+}
+
+// Encode returns the bech32 encoding of the address.
+func (a *AddressWitnessScriptHash) Encode() string { return encodeSegwit(a.hrp, 0, a.hash[:]) }
+
+// String is the same as Encode.
+func (a *AddressWitnessScriptHash) String() string { return a.Encode() }
+
+// Hash returns the SHA-256 of the witness script.
+func (a *AddressWitnessScriptHash) Hash() []byte { return append([]byte(nil), a.hash[:]...) }
+
+// IsForNet reports whether the address belongs to the network.
+func (a *AddressWitnessScriptHash) IsForNet(net *NetParams) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+
+// AddressTaproot is a segwit version 1 pay-to-taproot (P2TR) address.
+type AddressTaproot struct {
+	key [32]byte
+	hrp string
+}
+
+// NewAddressTaproot returns the P2TR address of the x-only output key on the
+// network, as returned by ComputeTaprootOutputKey.  The key must be on the
+// curve.
+func NewAddressTaproot(key []byte, net *NetParams) (*AddressTaproot, error) {
+	if _, err := ParseXOnlyPubKey(key); err != nil {
+		return nil, err
+	}
+	hrp, err := segwitHRP(net)
+	if err != nil {
+		return nil, err
+	}
+	a := &AddressTaproot{hrp: hrp}
+	copy(a.key[:], key)
+	return a, nil
+}
+
+// Encode returns the bech32m encoding of the address.
+func (a *AddressTaproot) Encode() string { return encodeSegwit(a.hrp, 1, a.key[:]) }
+
+// String is the same as Encode.
+func (a *AddressTaproot) String() string { return a.Encode() }
+
+// Hash returns the x-only output key.
+func (a *AddressTaproot) Hash() []byte { return append([]byte(nil), a.key[:]...) }
+
+// IsForNet reports whether the address belongs to the network.
+func (a *AddressTaproot) IsForNet(net *NetParams) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+
+// AddressSymphony is a Symphony-native account address, the format of
+// ToAddress, ToAddressCompressed and LoadAddress: the base58check encoding
+// of the hash160 of a serialized public key with the WALLET_ADDRESS_FLAG
+// version.
+type AddressSymphony struct {
+	hash [20]byte
+}
+
+// NewAddressSymphony returns the Symphony-native address of the hash160 of
+// a serialized public key.
+func NewAddressSymphony(hash []byte) (*AddressSymphony, error) {
+	if err := checkHashLength(hash, 20); err != nil {
+		return nil, err
+	}
+	a := new(AddressSymphony)
+	copy(a.hash[:], hash)
+	return a, nil
+}
+
+// Encode returns the base58check encoding of the address.
+func (a *AddressSymphony) Encode() string { return base58CheckEncode(WALLET_ADDRESS_FLAG, a.hash[:]) }
+
+// String is the same as Encode.
+func (a *AddressSymphony) String() string { return a.Encode() }
+
+// Hash returns the hash160 of the public key.
+func (a *AddressSymphony) Hash() []byte { return append([]byte(nil), a.hash[:]...) }
+
+// IsForNet reports whether the address belongs to the network.
+func (a *AddressSymphony) IsForNet(net *NetParams) bool {
+	return net.NativeAddrs && net.PubKeyHashAddrID == WALLET_ADDRESS_FLAG
+}
+
+// encodeSegwit encodes a witness program that the constructors have already
+// validated, so encoding cannot fail.
+func encodeSegwit(hrp string, version byte, program []byte) string {
+	addr, err := bech32.EncodeSegwitAddress(hrp, version, program)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
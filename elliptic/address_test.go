@@ -0,0 +1,132 @@
+package elliptic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		net  *NetParams
+		hash string
+		typ  string
+	}{
+		{"P2PKH", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", &BitcoinNetParams,
+			"77bff20c60e522dfaa3350c39b030a5d004e839a", "*elliptic.AddressPubKeyHash"},
+		{"P2SH", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", &BitcoinNetParams,
+			"b472a266d0bd89c13706a4132ccfb16f7c3b9fcb", "*elliptic.AddressScriptHash"},
+		{"P2WPKH", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", &BitcoinNetParams,
+			"751e76e8199196d454941c45d1b3a323f1433bd6", "*elliptic.AddressWitnessPubKeyHash"},
+		{"P2WSH", "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3", &BitcoinNetParams,
+			"1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", "*elliptic.AddressWitnessScriptHash"},
+		{"P2TR", "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", &BitcoinNetParams,
+			"a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", "*elliptic.AddressTaproot"},
+		{"Symphony", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", &SymphonyNetParams,
+			"77bff20c60e522dfaa3350c39b030a5d004e839a", "*elliptic.AddressSymphony"},
+	}
+	for _, test := range tests {
+		a, err := DecodeAddress(test.addr, test.net)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", a); got != test.typ {
+			t.Errorf("%s: type %s, want %s", test.name, got, test.typ)
+		}
+		if !bytes.Equal(a.Hash(), hexToBytes(test.hash)) {
+			t.Errorf("%s: hash %x, want %s", test.name, a.Hash(), test.hash)
+		}
+		if a.Encode() != test.addr || a.String() != test.addr {
+			t.Errorf("%s: encoded %s, want %s", test.name, a.Encode(), test.addr)
+		}
+		if !a.IsForNet(test.net) {
+			t.Errorf("%s: not for its own network", test.name)
+		}
+		other := &SymphonyNetParams
+		if test.net == other {
+			other = &BitcoinNetParams
+		}
+		if a.IsForNet(other) {
+			t.Errorf("%s: reported for network %s", test.name, other.Name)
+		}
+	}
+
+	// Bech32 addresses decode regardless of case.
+	upper := strings.ToUpper("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	if _, err := DecodeAddress(upper, &BitcoinNetParams); err != nil {
+		t.Errorf("upper case P2WPKH: %v", err)
+	}
+}
+
+func TestDecodeAddressErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		net  *NetParams
+	}{
+		{"wrong hrp", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", &BitcoinNetParams},
+		{"segwit on other net", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", &SymphonyNetParams},
+		{"bad bech32 checksum", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", &BitcoinNetParams},
+		{"bad base58 checksum", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", &BitcoinNetParams},
+		{"unknown version", "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", &BitcoinNetParams},
+	}
+	for _, test := range tests {
+		if a, err := DecodeAddress(test.addr, test.net); err == nil {
+			t.Errorf("%s: decoded %s", test.name, a)
+		}
+	}
+
+	// A valid version 2 witness program has no address type.
+	_, err := DecodeAddress("bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", &BitcoinNetParams)
+	if !errors.Is(err, ErrUnknownAddressType) {
+		t.Errorf("version 2 program: got %v, want %v", err, ErrUnknownAddressType)
+	}
+
+	// Symphony has no P2SH addresses, so bitcoin ones are not mistaken for them.
+	_, err = DecodeAddress("3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", &SymphonyNetParams)
+	if !errors.Is(err, ErrUnknownAddressType) {
+		t.Errorf("P2SH on symphony: got %v, want %v", err, ErrUnknownAddressType)
+	}
+}
+
+func TestAddressFromPubKey(t *testing.T) {
+	pub, err := ParsePubKey(hexToBytes("0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"), S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := DecodeAddress(pub.ToAddressCompressed(), &SymphonyNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sym, ok := a.(*AddressSymphony)
+	if !ok {
+		t.Fatalf("decoded %T, want *AddressSymphony", a)
+	}
+	if !bytes.Equal(sym.Hash(), Hash160(pub.SerializeCompressed())) {
+		t.Errorf("hash %x, want hash160 of the compressed key", sym.Hash())
+	}
+
+	wpkh, err := NewAddressWitnessPubKeyHash(Hash160(pub.SerializeCompressed()), &BitcoinNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := pub.ToP2WPKHAddress(&BitcoinNetParams)
+	if wpkh.Encode() != want {
+		t.Errorf("P2WPKH %s, want %s", wpkh.Encode(), want)
+	}
+
+	if _, err := NewAddressPubKeyHash(make([]byte, 19), &BitcoinNetParams); err == nil {
+		t.Error("accepted a 19 byte hash")
+	}
+	if _, err := NewAddressTaproot(bytes.Repeat([]byte{0xff}, 32), &BitcoinNetParams); err == nil {
+		t.Error("accepted an x coordinate off the curve")
+	}
+	if _, err := NewAddressScriptHash([]byte{0x51}, &SymphonyNetParams); err == nil {
+		t.Error("made a P2SH address on a network without them")
+	}
+}
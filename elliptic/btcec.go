@@ -17,7 +17,6 @@ import (
 	"crypto/elliptic"
 	"math/big"
 	"sync"
-	"encoding/binary"
 )
 
 var (
@@ -52,7 +51,7 @@ type KoblitzCurve struct {
 	// curve.
 	beta *fieldVal
 
-	// See the EndomorphismVectors in gensecp256k1.go to see how these are
+	// See EndomorphismVectors to see how these are
 	// derived.
 	a1 *big.Int
 	b1 *big.Int
@@ -857,7 +856,8 @@ func (curve *KoblitzCurve) ScalarMult(Bx, By *big.Int, k []byte) (*big.Int, *big
 // Part of the elliptic.Curve interface.
 func (curve *KoblitzCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
 	newK := curve.moduloReduce(k)
-	diff := len(curve.bytePoints) - len(newK)
+	bytePoints := curve.precomputedBytePoints()
+	diff := len(bytePoints) - len(newK)
 
 	// Point Q = ∞ (point at infinity).
 	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
@@ -868,7 +868,7 @@ func (curve *KoblitzCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
 	// Each "digit" in the 8-bit window can be looked up using bytePoints
 	// and added together.
 	for i, byteVal := range newK {
-		p := curve.bytePoints[diff+i][byteVal]
+		p := bytePoints[diff+i][byteVal]
 		curve.addJacobian(qx, qy, qz, &p[0], &p[1], &p[2], qx, qy, qz)
 	}
 	return curve.fieldJacobianToBigAffine(qx, qy, qz)
@@ -917,10 +917,9 @@ func initS256() {
 	secp256k1.byteSize = secp256k1.BitSize / 8
 
 	// Deserialize and set the pre-computed table used to accelerate scalar
-	// base multiplication.  This is hard-coded data, so any errors are
-	// panics because it means something is wrong in the source code.
-	if err := loadS256BytePoints(); err != nil {
-		panic(err)
+	// base multiplication, unless the build defers it to the first use.
+	if !lazyBytePoints {
+		secp256k1.precomputedBytePoints()
 	}
 
 	// Next 6 constants are from Hal Finney's bitcointalk.org post:
@@ -928,7 +927,7 @@ func initS256() {
 	// May he rest in peace.
 	//
 	// They have also been independently derived from the code in the
-	// EndomorphismVectors function in gensecp256k1.go.
+	// EndomorphismVectors function, which cmd/genprecomps prints.
 	secp256k1.lambda = fromHex("5363AD4CC05C30E0A5261C028812645A122E22EA20816678DF02967C1B23BD72")
 	secp256k1.beta = new(fieldVal).SetHex("7AE96A2B657C07106E64479EAC3434E99CF0497512F58995C1396C28719501EE")
 	secp256k1.a1 = fromHex("3086D221A7D46BCDE86C90E49284EB15")
@@ -953,13 +952,18 @@ func S256() *KoblitzCurve {
 }
 
 // SerializedBytePoints returns a serialized byte slice which contains all of
-// the possible points per 8-bit window.  This is used to when generating
-// secp256k1.go.
+// the possible points per 8-bit window.  This is used by cmd/genprecomps to
+// generate secp256k1_bytepoints.bin.
+//
+// The points are serialized window by window, starting with the window of the
+// most significant byte, as the 32 byte big endian affine x and y
+// coordinates, with the point at infinity encoded as (0, 0).  The format does
+// not depend on the field representation of the build.
 func (curve *KoblitzCurve) SerializedBytePoints() []byte {
 	doublingPoints := curve.getDoublingPoints()
 
 	// Segregate the bits into byte-sized windows
-	serialized := make([]byte, curve.byteSize*256*3*10*4)
+	serialized := make([]byte, curve.byteSize*256*64)
 	offset := 0
 	for byteNum := 0; byteNum < curve.byteSize; byteNum++ {
 		// Grab the 8 bits that make up this byte from doublingPoints.
@@ -968,25 +972,18 @@ func (curve *KoblitzCurve) SerializedBytePoints() []byte {
 
 		// Compute all points in this window and serialize them.
 		for i := 0; i < 256; i++ {
-			px, py, pz := new(fieldVal), new(fieldVal), new(fieldVal)
+			var p Point
 			for j := 0; j < 8; j++ {
 				if i>>uint(j)&1 == 1 {
-					curve.addJacobian(px, py, pz, &computingPoints[j][0],
-						&computingPoints[j][1], &computingPoints[j][2], px, py, pz)
+					curve.addJacobian(&p.x, &p.y, &p.z, &computingPoints[j][0],
+						&computingPoints[j][1], &computingPoints[j][2], &p.x, &p.y, &p.z)
 				}
 			}
-			for i := 0; i < 10; i++ {
-				binary.LittleEndian.PutUint32(serialized[offset:], px.n[i])
-				offset += 4
-			}
-			for i := 0; i < 10; i++ {
-				binary.LittleEndian.PutUint32(serialized[offset:], py.n[i])
-				offset += 4
-			}
-			for i := 0; i < 10; i++ {
-				binary.LittleEndian.PutUint32(serialized[offset:], pz.n[i])
-				offset += 4
+			if p.toAffine() {
+				p.x.PutBytes((*[32]byte)(serialized[offset:]))
+				p.y.PutBytes((*[32]byte)(serialized[offset+32:]))
 			}
+			offset += 64
 		}
 	}
 
@@ -0,0 +1,433 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+var (
+	// ErrInvalidMAC occurs when Message Authentication Check (MAC) fails
+	// during decryption. This happens because of either invalid private key or
+	// corrupt ciphertext.
+	ErrInvalidMAC = errors.New("invalid mac hash")
+
+	// errInputTooShort occurs when the input ciphertext to the Decrypt
+	// function is less than 134 bytes long.
+	errInputTooShort = errors.New("ciphertext too short")
+
+	// errUnsupportedCurve occurs when the first two bytes of the encrypted
+	// text aren't 0x02CA (= 714 = secp256k1, from OpenSSL).
+	errUnsupportedCurve = errors.New("unsupported curve")
+
+	errInvalidXLength = errors.New("invalid X length, must be 32")
+	errInvalidYLength = errors.New("invalid Y length, must be 32")
+	errInvalidPadding = errors.New("invalid PKCS#7 padding")
+
+	// errUnknownCipherMode occurs when a CipherMode that is not one of the
+	// constants below is passed to EncryptWithMode or DecryptWithMode.
+	errUnknownCipherMode = errors.New("unknown cipher mode")
+)
+
+var (
+	// 0x02CA = 714
+	ciphCurveBytes = [2]byte{0x02, 0xCA}
+	// 0x20 = 32
+	ciphCoordLength = [2]byte{0x00, 0x20}
+)
+
+// CipherMode selects the wire format and key schedule used by
+// EncryptWithMode and DecryptWithMode.
+type CipherMode int
+
+const (
+	// CipherModeNative is the format produced by Encrypt:
+	//
+	//   IV (16) || 0x02CA (2) || 0x0020 (2) || X (32) || 0x0020 (2) || Y (32) ||
+	//   AES-256-CBC ciphertext (16n) || HMAC-SHA-256 (32)
+	//
+	// X and Y are the coordinates of the ephemeral public key. The AES and
+	// HMAC keys are the first and last 32 bytes of SHA-512 over the ECDH
+	// shared secret, the plaintext is PKCS#7 padded and the HMAC covers
+	// everything that precedes it.
+	CipherModeNative CipherMode = iota
+
+	// CipherModeGeth is the go-ethereum crypto/ecies layout
+	// (ECIES_AES128_SHA256 without shared info):
+	//
+	//   0x04 || X (32) || Y (32) || IV (16) || AES-128-CTR ciphertext || HMAC-SHA-256 (32)
+	//
+	// 32 bytes of key material are taken from the NIST SP 800-56 concatenation
+	// KDF with SHA-256 over the ECDH shared secret. The first half is the AES
+	// key, SHA-256 of the second half is the HMAC key and the HMAC covers the
+	// IV and ciphertext.
+	CipherModeGeth
+
+	// CipherModeEciesJS is the default layout of the eciesjs / eciespy
+	// libraries:
+	//
+	//   0x04 || X (32) || Y (32) || nonce (16) || tag (16) || AES-256-GCM ciphertext
+	//
+	// The AES key is HKDF-SHA-256, with no salt and no info, over the
+	// uncompressed ephemeral public key followed by the uncompressed ECDH
+	// shared point.
+	CipherModeEciesJS
+)
+
+// GenerateSharedSecret generates a shared secret based on a private key and a
+// public key using Diffie-Hellman key exchange (ECDH) (RFC 4753).
+// RFC5903 Section 9 states we should only return x.
+func GenerateSharedSecret(privkey *PrivateKey, pubkey *PublicKey) []byte {
+	x, _ := pubkey.Curve.ScalarMult(pubkey.X, pubkey.Y, privkey.D.Bytes())
+	return paddedAppend(32, nil, x.Bytes())
+}
+
+// generateSharedPoint is like GenerateSharedSecret but returns the whole
+// shared point in uncompressed form, which is what eciesjs feeds its KDF.
+func generateSharedPoint(privkey *PrivateKey, pubkey *PublicKey) []byte {
+	x, y := pubkey.Curve.ScalarMult(pubkey.X, pubkey.Y, privkey.D.Bytes())
+	shared := PublicKey{Curve: pubkey.Curve, X: x, Y: y}
+	return shared.SerializeUncompressed()
+}
+
+// Encrypt encrypts data for the target public key using AES-256-CBC. It also
+// generates a private key (the pubkey of which is also in the output). The only
+// supported curve is secp256k1. The `structure' that it encodes everything into
+// is described on CipherModeNative.
+func Encrypt(pubkey *PublicKey, in []byte) ([]byte, error) {
+	return EncryptWithMode(pubkey, in, CipherModeNative)
+}
+
+// Decrypt decrypts data that was encrypted using the Encrypt function.
+func Decrypt(priv *PrivateKey, in []byte) ([]byte, error) {
+	return DecryptWithMode(priv, in, CipherModeNative)
+}
+
+// EncryptWithMode encrypts data for the target public key using the wire format
+// selected by mode. Use CipherModeGeth or CipherModeEciesJS to produce
+// ciphertexts that go-ethereum or eciesjs peers can decrypt.
+func EncryptWithMode(pubkey *PublicKey, in []byte, mode CipherMode) ([]byte, error) {
+	ephemeral, err := GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case CipherModeNative:
+		return encryptNative(ephemeral, pubkey, in)
+	case CipherModeGeth:
+		return encryptGeth(ephemeral, pubkey, in)
+	case CipherModeEciesJS:
+		return encryptEciesJS(ephemeral, pubkey, in)
+	}
+	return nil, errUnknownCipherMode
+}
+
+// DecryptWithMode decrypts data that was encrypted by EncryptWithMode, or by a
+// compatible implementation, using the same mode.
+func DecryptWithMode(priv *PrivateKey, in []byte, mode CipherMode) ([]byte, error) {
+	switch mode {
+	case CipherModeNative:
+		return decryptNative(priv, in)
+	case CipherModeGeth:
+		return decryptGeth(priv, in)
+	case CipherModeEciesJS:
+		return decryptEciesJS(priv, in)
+	}
+	return nil, errUnknownCipherMode
+}
+
+func encryptNative(ephemeral *PrivateKey, pubkey *PublicKey, in []byte) ([]byte, error) {
+	ecdhKey := GenerateSharedSecret(ephemeral, pubkey)
+	derivedKey := sha512.Sum512(ecdhKey)
+	keyE := derivedKey[:32]
+	keyM := derivedKey[32:]
+
+	paddedIn := addPKCSPadding(in)
+	// IV + Curve params/X/Y + padded plaintext/ciphertext + HMAC-256
+	out := make([]byte, aes.BlockSize+70+len(paddedIn)+sha256.Size)
+	iv := out[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	// start writing public key
+	pb := ephemeral.ECPubKey().SerializeUncompressed()
+	offset := aes.BlockSize
+
+	// curve and X length
+	copy(out[offset:offset+4], append(ciphCurveBytes[:], ciphCoordLength[:]...))
+	offset += 4
+	// X
+	copy(out[offset:offset+32], pb[1:33])
+	offset += 32
+	// Y length
+	copy(out[offset:offset+2], ciphCoordLength[:])
+	offset += 2
+	// Y
+	copy(out[offset:offset+32], pb[33:])
+	offset += 32
+
+	// start encryption
+	block, err := aes.NewCipher(keyE)
+	if err != nil {
+		return nil, err
+	}
+	mode := cipher.NewCBCEncrypter(block, iv)
+	mode.CryptBlocks(out[offset:len(out)-sha256.Size], paddedIn)
+
+	// start HMAC-SHA-256
+	hm := hmac.New(sha256.New, keyM)
+	hm.Write(out[:len(out)-sha256.Size])          // everything is hashed
+	copy(out[len(out)-sha256.Size:], hm.Sum(nil)) // write checksum
+
+	return out, nil
+}
+
+func decryptNative(priv *PrivateKey, in []byte) ([]byte, error) {
+	// IV + Curve params/X/Y + 1 block + HMAC-256
+	if len(in) < aes.BlockSize+70+aes.BlockSize+sha256.Size {
+		return nil, errInputTooShort
+	}
+
+	// read iv
+	iv := in[:aes.BlockSize]
+	offset := aes.BlockSize
+
+	// start reading pubkey
+	if !bytes.Equal(in[offset:offset+2], ciphCurveBytes[:]) {
+		return nil, errUnsupportedCurve
+	}
+	offset += 2
+
+	if !bytes.Equal(in[offset:offset+2], ciphCoordLength[:]) {
+		return nil, errInvalidXLength
+	}
+	offset += 2
+
+	xBytes := in[offset : offset+32]
+	offset += 32
+
+	if !bytes.Equal(in[offset:offset+2], ciphCoordLength[:]) {
+		return nil, errInvalidYLength
+	}
+	offset += 2
+
+	yBytes := in[offset : offset+32]
+	offset += 32
+
+	pb := make([]byte, 65)
+	pb[0] = PubkeyUncompressed
+	copy(pb[1:33], xBytes)
+	copy(pb[33:], yBytes)
+	// check if (X, Y) lies on the curve and create a Pubkey if it does
+	pubkey, err := ParsePubKey(pb, S256())
+	if err != nil {
+		return nil, err
+	}
+
+	// check for cipher text length
+	if (len(in)-offset-sha256.Size)%aes.BlockSize != 0 {
+		return nil, errInvalidPadding // not padded to 16 bytes
+	}
+
+	// read hmac
+	messageMAC := in[len(in)-sha256.Size:]
+
+	// generate shared secret
+	ecdhKey := GenerateSharedSecret(priv, pubkey)
+	derivedKey := sha512.Sum512(ecdhKey)
+	keyE := derivedKey[:32]
+	keyM := derivedKey[32:]
+
+	// verify mac
+	hm := hmac.New(sha256.New, keyM)
+	hm.Write(in[:len(in)-sha256.Size]) // everything is hashed
+	expectedMAC := hm.Sum(nil)
+	if !hmac.Equal(messageMAC, expectedMAC) {
+		return nil, ErrInvalidMAC
+	}
+
+	// start decryption
+	block, err := aes.NewCipher(keyE)
+	if err != nil {
+		return nil, err
+	}
+	mode := cipher.NewCBCDecrypter(block, iv)
+	// same length as ciphertext
+	plaintext := make([]byte, len(in)-offset-sha256.Size)
+	mode.CryptBlocks(plaintext, in[offset:len(in)-sha256.Size])
+
+	return removePKCSPadding(plaintext)
+}
+
+// concatKDF implements the NIST SP 800-56 Concatenation Key Derivation
+// Function with SHA-256 and no shared info, as used by go-ethereum.
+func concatKDF(z []byte, kdLen int) []byte {
+	var counter [4]byte
+	k := make([]byte, 0, kdLen+sha256.Size)
+	for i := uint32(1); len(k) < kdLen; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h := sha256.New()
+		h.Write(counter[:])
+		h.Write(z)
+		k = h.Sum(k)
+	}
+	return k[:kdLen]
+}
+
+// gethKeys derives the AES-128 and HMAC-SHA-256 keys used by CipherModeGeth.
+func gethKeys(z []byte) (keyE, keyM []byte) {
+	k := concatKDF(z, 32)
+	km := sha256.Sum256(k[16:])
+	return k[:16], km[:]
+}
+
+func encryptGeth(ephemeral *PrivateKey, pubkey *PublicKey, in []byte) ([]byte, error) {
+	keyE, keyM := gethKeys(GenerateSharedSecret(ephemeral, pubkey))
+
+	pb := ephemeral.ECPubKey().SerializeUncompressed()
+	out := make([]byte, len(pb)+aes.BlockSize+len(in)+sha256.Size)
+	offset := copy(out, pb)
+
+	em := out[offset : len(out)-sha256.Size]
+	iv := em[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(keyE)
+	if err != nil {
+		return nil, err
+	}
+	cipher.NewCTR(block, iv).XORKeyStream(em[aes.BlockSize:], in)
+
+	hm := hmac.New(sha256.New, keyM)
+	hm.Write(em)
+	copy(out[len(out)-sha256.Size:], hm.Sum(nil))
+
+	return out, nil
+}
+
+func decryptGeth(priv *PrivateKey, in []byte) ([]byte, error) {
+	// Pubkey + IV + HMAC-256, the message itself may be empty
+	if len(in) < LenPubKeyBytesUnCompressed+aes.BlockSize+sha256.Size {
+		return nil, errInputTooShort
+	}
+	pubkey, err := ParsePubKey(in[:LenPubKeyBytesUnCompressed], S256())
+	if err != nil {
+		return nil, err
+	}
+	keyE, keyM := gethKeys(GenerateSharedSecret(priv, pubkey))
+
+	em := in[LenPubKeyBytesUnCompressed : len(in)-sha256.Size]
+	messageMAC := in[len(in)-sha256.Size:]
+
+	hm := hmac.New(sha256.New, keyM)
+	hm.Write(em)
+	if !hmac.Equal(messageMAC, hm.Sum(nil)) {
+		return nil, ErrInvalidMAC
+	}
+
+	block, err := aes.NewCipher(keyE)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(em)-aes.BlockSize)
+	cipher.NewCTR(block, em[:aes.BlockSize]).XORKeyStream(plaintext, em[aes.BlockSize:])
+	return plaintext, nil
+}
+
+// eciesJSCipher returns the AES-256-GCM instance, with 16 byte nonces, keyed
+// the way eciesjs derives its symmetric key.
+func eciesJSCipher(senderPoint, sharedPoint []byte) (cipher.AEAD, error) {
+	master := append(append([]byte{}, senderPoint...), sharedPoint...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, master, nil, nil), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, 16)
+}
+
+func encryptEciesJS(ephemeral *PrivateKey, pubkey *PublicKey, in []byte) ([]byte, error) {
+	pb := ephemeral.ECPubKey().SerializeUncompressed()
+	aead, err := eciesJSCipher(pb, generateSharedPoint(ephemeral, pubkey))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	// Go appends the tag to the ciphertext, eciesjs puts it in front.
+	sealed := aead.Seal(nil, nonce, in, nil)
+	tag := sealed[len(sealed)-aead.Overhead():]
+
+	out := make([]byte, 0, len(pb)+len(nonce)+len(sealed))
+	out = append(out, pb...)
+	out = append(out, nonce...)
+	out = append(out, tag...)
+	out = append(out, sealed[:len(sealed)-aead.Overhead()]...)
+	return out, nil
+}
+
+func decryptEciesJS(priv *PrivateKey, in []byte) ([]byte, error) {
+	// Pubkey + nonce + tag, the message itself may be empty
+	if len(in) < LenPubKeyBytesUnCompressed+16+16 {
+		return nil, errInputTooShort
+	}
+	pb := in[:LenPubKeyBytesUnCompressed]
+	pubkey, err := ParsePubKey(pb, S256())
+	if err != nil {
+		return nil, err
+	}
+	aead, err := eciesJSCipher(pb, generateSharedPoint(priv, pubkey))
+	if err != nil {
+		return nil, err
+	}
+
+	offset := LenPubKeyBytesUnCompressed
+	nonce := in[offset : offset+aead.NonceSize()]
+	offset += aead.NonceSize()
+	tag := in[offset : offset+aead.Overhead()]
+	offset += aead.Overhead()
+
+	sealed := append(append([]byte{}, in[offset:]...), tag...)
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidMAC
+	}
+	return plaintext, nil
+}
+
+// Implement PKCS#7 padding with block size of 16 (AES block size).
+
+// addPKCSPadding adds padding to a block of data
+func addPKCSPadding(src []byte) []byte {
+	padding := aes.BlockSize - len(src)%aes.BlockSize
+	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
+	return append(append([]byte{}, src...), padtext...)
+}
+
+// removePKCSPadding removes padding from data that was added with addPKCSPadding
+func removePKCSPadding(src []byte) ([]byte, error) {
+	length := len(src)
+	padLength := int(src[length-1])
+	if padLength > aes.BlockSize || length < aes.BlockSize || padLength == 0 {
+		return nil, errInvalidPadding
+	}
+
+	return src[:length-padLength], nil
+}
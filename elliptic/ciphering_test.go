@@ -0,0 +1,187 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestGenerateSharedSecret(t *testing.T) {
+	privKey1, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("private key generation error: %s", err)
+	}
+	privKey2, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("private key generation error: %s", err)
+	}
+
+	secret1 := GenerateSharedSecret(privKey1, privKey2.ECPubKey())
+	secret2 := GenerateSharedSecret(privKey2, privKey1.ECPubKey())
+
+	if !bytes.Equal(secret1, secret2) {
+		t.Errorf("ECDH failed, secrets mismatch - first: %x, second: %x",
+			secret1, secret2)
+	}
+}
+
+func TestCipheringModes(t *testing.T) {
+	privkey, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("private key generation error: %s", err)
+	}
+	other, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("private key generation error: %s", err)
+	}
+
+	modes := []CipherMode{CipherModeNative, CipherModeGeth, CipherModeEciesJS}
+	messages := [][]byte{
+		{},
+		[]byte("hello"),
+		bytes.Repeat([]byte{0x5a}, 16),
+		bytes.Repeat([]byte{0xa5}, 1000),
+	}
+	for _, mode := range modes {
+		for _, msg := range messages {
+			out, err := EncryptWithMode(privkey.ECPubKey(), msg, mode)
+			if err != nil {
+				t.Fatalf("mode %d: failed to encrypt: %v", mode, err)
+			}
+
+			dec, err := DecryptWithMode(privkey, out, mode)
+			if err != nil {
+				t.Fatalf("mode %d: failed to decrypt: %v", mode, err)
+			}
+			if !bytes.Equal(msg, dec) {
+				t.Errorf("mode %d: decrypted data doesn't match original", mode)
+			}
+
+			// The wrong key must fail the MAC check.
+			if _, err := DecryptWithMode(other, out, mode); err != ErrInvalidMAC {
+				t.Errorf("mode %d: decrypt with wrong key: got %v, want %v",
+					mode, err, ErrInvalidMAC)
+			}
+
+			// So must a flipped bit in the ciphertext.
+			out[len(out)-1] ^= 0x01
+			if _, err := DecryptWithMode(privkey, out, mode); err != ErrInvalidMAC {
+				t.Errorf("mode %d: decrypt of tampered data: got %v, want %v",
+					mode, err, ErrInvalidMAC)
+			}
+		}
+	}
+}
+
+// cipheringVectors are fixed ciphertexts of one message for one key in each
+// mode.  They were made outside this package with the Node.js crypto
+// module, following the go-ethereum crypto/ecies and eciesjs constructions
+// step by step, with the ephemeral key
+// 2b1d36bc8df8d3a0dbf4a1b7f1c0a3e0c4a1b7c8d9e0f1a2b3c4d5e6f708192a and the
+// IV or nonce 000102030405060708090a0b0c0d0e0f.
+var cipheringVectors = []struct {
+	mode       CipherMode
+	ciphertext string
+}{
+	{
+		CipherModeNative,
+		"000102030405060708090a0b0c0d0e0f02ca0020f41dea69640bd0228f45e371" +
+			"e92792a9d5ee83c59117be8b635e13d86363731900204e8d54ccbdc74926cfb5" +
+			"2f9df8ada5a052e74e5b2d3f8bc60a692d45397bf657805736f842c0ce7d0418" +
+			"efd03b4db9c54a283a9c55ab5a3b82265bffaef18c0acacaecfeb904d084b9fa" +
+			"15f9c45f7ed84c97ff2e29041b561b92d4b29cbe793c",
+	},
+	{
+		CipherModeGeth,
+		"04f41dea69640bd0228f45e371e92792a9d5ee83c59117be8b635e13d8636373" +
+			"194e8d54ccbdc74926cfb52f9df8ada5a052e74e5b2d3f8bc60a692d45397bf6" +
+			"57000102030405060708090a0b0c0d0e0f2f5a48ff9ee6a198cd84da1b3cbba3" +
+			"8d414ffba7b6ac5efd63ab398e63898f268f956926ae0c348e15543e19b51148" +
+			"97e5a8f7b7d40fb0c71b0b",
+	},
+	{
+		CipherModeEciesJS,
+		"04f41dea69640bd0228f45e371e92792a9d5ee83c59117be8b635e13d8636373" +
+			"194e8d54ccbdc74926cfb52f9df8ada5a052e74e5b2d3f8bc60a692d45397bf6" +
+			"57000102030405060708090a0b0c0d0e0f560e9a666a6795efab789860d5d140" +
+			"98501d826cac4f1e5ceeba552a227a43ac9915558e4584add58978",
+	},
+}
+
+func TestCipheringVectors(t *testing.T) {
+	privkey, _ := PrivKeyFromBytes(S256(), hexToBytes(
+		"c7ea1ac1eb9e9c6f4b3b5ab0d6f34d8b0b0c8bd0e0a0d7a93e1b9a6f7c3b2a19"))
+	want := []byte("Symphony ECIES test vector")
+
+	for _, test := range cipheringVectors {
+		got, err := DecryptWithMode(privkey, hexToBytes(test.ciphertext), test.mode)
+		if err != nil {
+			t.Errorf("mode %d: failed to decrypt: %v", test.mode, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("mode %d: decrypted %q, want %q", test.mode, got, want)
+		}
+	}
+}
+
+func TestCipheringErrors(t *testing.T) {
+	privkey, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("private key generation error: %s", err)
+	}
+
+	tests1 := []struct {
+		ciphertext []byte // input ciphertext
+	}{
+		{bytes.Repeat([]byte{0x00}, 133)},                   // errInputTooShort
+		{bytes.Repeat([]byte{0x00}, 134)},                   // errUnsupportedCurve
+		{bytes.Repeat([]byte{0x02, 0xCA}, 134)},             // errInvalidXLength
+		{bytes.Repeat([]byte{0x02, 0xCA, 0x00, 0x20}, 134)}, // errInvalidYLength
+		{[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // IV
+			0x02, 0xCA, 0x00, 0x20, // curve and X length
+			0x11, 0x5C, 0x42, 0xE7, 0x57, 0x76, 0xF2, 0x0F, 0x00, 0x4E, 0xE2,
+			0x1A, 0x00, 0x9A, 0x7E, 0x32, 0x71, 0x91, 0x87, 0x43, 0x53, 0xB6,
+			0x42, 0x04, 0x2D, 0x9E, 0x28, 0x4D, 0xE0, 0x6A, 0xBB, 0xBA, // X
+			0x00, 0x20, // Y length
+			0x42, 0xA8, 0x47, 0x74, 0xA3, 0x1E, 0xF9, 0x42, 0x8F, 0x7C, 0x02,
+			0x2C, 0x4F, 0x78, 0x30, 0x29, 0x84, 0xA0, 0xDA, 0x63, 0x27, 0x0F,
+			0xF2, 0x9A, 0x33, 0x96, 0x85, 0x0C, 0xA8, 0x8F, 0x33, 0x5F, // Y
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		}}, // point not on curve
+	}
+	for i, test := range tests1 {
+		if _, err := Decrypt(privkey, test.ciphertext); err == nil {
+			t.Errorf("Decrypt #%d did not get error", i)
+		}
+	}
+
+	if _, err := DecryptWithMode(privkey, make([]byte, 112), CipherModeGeth); err != errInputTooShort {
+		t.Errorf("geth: got %v, want %v", err, errInputTooShort)
+	}
+	if _, err := DecryptWithMode(privkey, make([]byte, 96), CipherModeEciesJS); err != errInputTooShort {
+		t.Errorf("eciesjs: got %v, want %v", err, errInputTooShort)
+	}
+	if _, err := EncryptWithMode(privkey.ECPubKey(), nil, CipherMode(-1)); err != errUnknownCipherMode {
+		t.Errorf("got %v, want %v", err, errUnknownCipherMode)
+	}
+}
+
+func TestConcatKDF(t *testing.T) {
+	// The first block of the KDF is SHA-256(0x00000001 || z) and the
+	// output is truncated to the requested length.
+	z := []byte("input")
+	k := concatKDF(z, 48)
+	if len(k) != 48 {
+		t.Fatalf("got %d bytes of key material, want 48", len(k))
+	}
+	if !bytes.Equal(k[:16], concatKDF(z, 16)) {
+		t.Errorf("KDF output is not a prefix of a longer derivation")
+	}
+}
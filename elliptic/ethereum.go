@@ -0,0 +1,178 @@
+package elliptic
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// LenEthereumAddress is the length of an Ethereum account address.
+const LenEthereumAddress = 20
+
+// personalMessagePrefix is framed in front of EIP-191 version 0x45
+// (personal_sign) messages.
+const personalMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// ErrEthereumAddressChecksum is returned by DecodeEthereumAddress for mixed
+// case addresses whose case does not match their EIP-55 checksum.
+var ErrEthereumAddressChecksum = errors.New("invalid EIP-55 address checksum")
+
+// Keccak256 calculates the legacy Keccak-256 hash used by Ethereum, which
+// differs from the standardized SHA3-256 in its padding, of the
+// concatenation of data.
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// EthereumAddress returns the 20 byte Ethereum account address of the public
+// key: the last 20 bytes of the Keccak-256 hash of its uncompressed
+// serialization without the 0x04 prefix.
+func (p *PublicKey) EthereumAddress() []byte {
+	return Keccak256(p.SerializeUncompressed()[1:])[32-LenEthereumAddress:]
+}
+
+// ToEthereumAddress returns the EIP-55 checksummed Ethereum account address
+// of the public key, such as 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf.
+func (p *PublicKey) ToEthereumAddress() string {
+	return EncodeEthereumAddress(p.EthereumAddress())
+}
+
+// EncodeEthereumAddress returns the 0x prefixed EIP-55 mixed case checksum
+// encoding of a 20 byte address: a hex letter is upper case when the
+// matching nibble of the Keccak-256 hash of the lower case hex is 8 or more.
+func EncodeEthereumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	hash := Keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// DecodeEthereumAddress parses a hex Ethereum address with or without the 0x
+// prefix.  All lower and all upper case addresses carry no checksum and are
+// accepted as is; mixed case addresses must match their EIP-55 checksum.
+func DecodeEthereumAddress(s string) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(digits) != 2*LenEthereumAddress {
+		return nil, errors.New("invalid Ethereum address length")
+	}
+	addr, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, errors.New("malformed hex in Ethereum address")
+	}
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) &&
+		EncodeEthereumAddress(addr)[2:] != digits {
+		return nil, ErrEthereumAddressChecksum
+	}
+	return addr, nil
+}
+
+// PersonalMessageHash returns the EIP-191 hash that personal_sign and
+// eth_sign sign for message: Keccak-256 of "\x19Ethereum Signed Message:\n",
+// the decimal length of the message and the message.
+func PersonalMessageHash(message []byte) []byte {
+	return Keccak256([]byte(personalMessagePrefix+strconv.Itoa(len(message))), message)
+}
+
+// SignEthereum signs hash with key and returns the 65 byte [R || S || V]
+// signature used by Ethereum.  V is the recovery id offset by vBase, which
+// is 27 for the V of personal_sign and most wallets or 0 for the raw
+// recovery id of go-ethereum's crypto.Sign and typed transactions.
+func SignEthereum(key *PrivateKey, hash []byte, vBase byte) ([]byte, error) {
+	compact, err := SignCompact(S256(), key, hash, false)
+	if err != nil {
+		return nil, err
+	}
+	// The header of SignCompact is 27 plus the recovery id. Ethereum only
+	// has room for the Y parity, R >= N is too improbable to ever happen.
+	recid := compact[0] - 27
+	if recid > 1 {
+		return nil, errors.New("signature recovery id does not fit Ethereum V")
+	}
+	return append(compact[1:], recid+vBase), nil
+}
+
+// NormalizeEthereumV returns a copy of the [R || S || V] signature sig with V
+// set to the recovery id offset by vBase, 27 or 0, whether V was 27/28 or
+// 0/1 before.
+func NormalizeEthereumV(sig []byte, vBase byte) ([]byte, error) {
+	recid, err := ethereumRecoveryID(sig)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(nil), sig...)
+	out[64] = recid + vBase
+	return out, nil
+}
+
+// ethereumRecoveryID returns the recovery id encoded in V of an Ethereum
+// signature, accepting both the 27/28 and the 0/1 convention.
+func ethereumRecoveryID(sig []byte) (byte, error) {
+	if len(sig) != LenEcrecoverSignature {
+		return 0, errors.New("invalid signature length, expected 65 bytes")
+	}
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return 0, errors.New("invalid signature V, expected 27, 28, 0 or 1")
+	}
+	return v, nil
+}
+
+// RecoverEthereum returns the public key that produced the [R || S || V]
+// signature sig over hash, with V either 27/28 or 0/1.
+func RecoverEthereum(sig, hash []byte) (*PublicKey, error) {
+	recid, err := ethereumRecoveryID(sig)
+	if err != nil {
+		return nil, err
+	}
+	compact := append([]byte{27 + recid}, sig[:64]...)
+	pubKey, _, err := RecoverCompact(S256(), compact, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidRecoveredKey(pubKey) {
+		return nil, errors.New("recovered public key is invalid")
+	}
+	return pubKey, nil
+}
+
+// SignPersonalMessage signs message with key the way personal_sign does and
+// returns the 65 byte [R || S || V] signature with V normalized to vBase, 27
+// as wallets produce or 0.
+func SignPersonalMessage(key *PrivateKey, message []byte, vBase byte) ([]byte, error) {
+	return SignEthereum(key, PersonalMessageHash(message), vBase)
+}
+
+// VerifyPersonalMessage checks that sig is a personal_sign signature of
+// message made by the owner of the Ethereum address, which must be either
+// unchecksummed or correctly EIP-55 checksummed.  V may be 27/28 or 0/1.
+func VerifyPersonalMessage(address string, message, sig []byte) (bool, error) {
+	addr, err := DecodeEthereumAddress(address)
+	if err != nil {
+		return false, err
+	}
+	pubKey, err := RecoverEthereum(sig, PersonalMessageHash(message))
+	if err != nil {
+		return false, err
+	}
+	return string(pubKey.EthereumAddress()) == string(addr), nil
+}
@@ -0,0 +1,147 @@
+package elliptic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestKeccak256(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+	for _, test := range tests {
+		if got := hex.EncodeToString(Keccak256([]byte(test.in))); got != test.want {
+			t.Errorf("Keccak256(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestEthereumAddress(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"0000000000000000000000000000000000000000000000000000000000000001",
+			"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		{"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+			"0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"},
+	}
+	for _, test := range tests {
+		_, pub := PrivKeyFromBytes(S256(), hexToBytes(test.key))
+		if got := pub.ToEthereumAddress(); got != test.want {
+			t.Errorf("ToEthereumAddress(%s) = %s, want %s", test.key, got, test.want)
+		}
+	}
+}
+
+func TestEIP55(t *testing.T) {
+	// Test vectors from EIP-55.
+	valid := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+	for _, addr := range valid {
+		b, err := DecodeEthereumAddress(addr)
+		if err != nil {
+			t.Errorf("DecodeEthereumAddress(%s): %v", addr, err)
+			continue
+		}
+		if got := EncodeEthereumAddress(b); got != addr {
+			t.Errorf("EncodeEthereumAddress(%x) = %s, want %s", b, got, addr)
+		}
+		for _, s := range []string{strings.ToLower(addr), "0x" + strings.ToUpper(addr[2:]), addr[2:]} {
+			if _, err := DecodeEthereumAddress(s); err != nil {
+				t.Errorf("DecodeEthereumAddress(%s): %v", s, err)
+			}
+		}
+	}
+
+	invalid := []struct {
+		addr string
+		err  error
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", ErrEthereumAddressChecksum},
+		{"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrEthereumAddressChecksum},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", nil},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAez", nil},
+	}
+	for _, test := range invalid {
+		_, err := DecodeEthereumAddress(test.addr)
+		if err == nil || (test.err != nil && err != test.err) {
+			t.Errorf("DecodeEthereumAddress(%s): got %v, want %v", test.addr, err, test.err)
+		}
+	}
+}
+
+func TestPersonalSign(t *testing.T) {
+	hash := PersonalMessageHash([]byte("hello world"))
+	if got := hex.EncodeToString(hash); got != "d9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68" {
+		t.Errorf("PersonalMessageHash = %s", got)
+	}
+
+	// web3.eth.accounts.sign("Some data", key) from the web3.js
+	// documentation, which signs deterministically with RFC 6979.
+	key, pub := PrivKeyFromBytes(S256(), hexToBytes("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"))
+	msg := []byte("Some data")
+	want := hexToBytes("b91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c")
+
+	sig, err := SignPersonalMessage(key, msg, 27)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, want) {
+		t.Fatalf("SignPersonalMessage = %x, want %x", sig, want)
+	}
+	sig0, err := SignPersonalMessage(key, msg, 0)
+	if err != nil || sig0[64] != sig[64]-27 || !bytes.Equal(sig0[:64], sig[:64]) {
+		t.Errorf("SignPersonalMessage with V base 0 = %x, %v", sig0, err)
+	}
+	if norm, err := NormalizeEthereumV(sig0, 27); err != nil || !bytes.Equal(norm, sig) {
+		t.Errorf("NormalizeEthereumV(%x, 27) = %x, %v", sig0, norm, err)
+	}
+	if norm, err := NormalizeEthereumV(sig, 0); err != nil || !bytes.Equal(norm, sig0) {
+		t.Errorf("NormalizeEthereumV(%x, 0) = %x, %v", sig, norm, err)
+	}
+
+	addr := pub.ToEthereumAddress()
+	for _, s := range [][]byte{sig, sig0} {
+		if ok, err := VerifyPersonalMessage(addr, msg, s); !ok || err != nil {
+			t.Errorf("VerifyPersonalMessage(%x) = %v, %v", s, ok, err)
+		}
+		if ok, err := VerifyPersonalMessage(strings.ToLower(addr), msg, s); !ok || err != nil {
+			t.Errorf("VerifyPersonalMessage lower case (%x) = %v, %v", s, ok, err)
+		}
+	}
+
+	// The recovered key agrees with Ecrecover.
+	recovered, err := RecoverEthereum(sig, PersonalMessageHash(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eth, _ := Ecrecover(PersonalMessageHash(msg), sig); !bytes.Equal(eth, recovered.SerializeUncompressed()) {
+		t.Errorf("RecoverEthereum disagrees with Ecrecover")
+	}
+
+	if ok, _ := VerifyPersonalMessage(addr, []byte("Some other data"), sig); ok {
+		t.Error("verified the signature of another message")
+	}
+	if ok, _ := VerifyPersonalMessage("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", msg, sig); ok {
+		t.Error("verified the signature for another address")
+	}
+	bad := append([]byte(nil), sig...)
+	bad[64] = 29
+	if _, err := VerifyPersonalMessage(addr, msg, bad); err == nil {
+		t.Error("accepted V = 29")
+	}
+	if _, err := VerifyPersonalMessage(addr, msg, sig[:64]); err == nil {
+		t.Error("accepted a 64 byte signature")
+	}
+}
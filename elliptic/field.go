@@ -97,7 +97,7 @@ const (
 	fieldPrimeWordOne = 0x3ffffbf
 )
 
-// fieldVal implements optimized fixed-precision arithmetic over the
+// fieldVal10x26 implements optimized fixed-precision arithmetic over the
 // secp256k1 finite field.  This means all arithmetic is performed modulo
 // 0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f.  It
 // represents each 256-bit value as 10 32-bit integers in base 2^26.  This
@@ -105,6 +105,10 @@ const (
 // word) for a total of 64 bits of overflow (9*6 + 10 = 64).  It only implements
 // the arithmetic needed for elliptic curve operations.
 //
+// This is the backend for 32-bit platforms.  64-bit platforms use
+// fieldVal5x52 instead, see field_5x52.go, and the curve code refers to
+// whichever one the build selects as fieldVal.
+//
 // The following depicts the internal representation:
 // 	 -----------------------------------------------------------------
 // 	|        n[9]       |        n[8]       | ... |        n[0]       |
@@ -127,20 +131,20 @@ const (
 // 	n[1] * 2^(26*1) = 2^23 * 2^26  = 2^49
 // 	n[0] * 2^(26*0) = 1    * 2^0   = 1
 // 	Sum: 0 + 0 + ... + 2^49 + 1 = 2^49 + 1
-type fieldVal struct {
+type fieldVal10x26 struct {
 	n [10]uint32
 }
 
 // String returns the field value as a human-readable hex string.
-func (f fieldVal) String() string {
-	t := new(fieldVal).Set(&f).Normalize()
+func (f fieldVal10x26) String() string {
+	t := new(fieldVal10x26).Set(&f).Normalize()
 	return hex.EncodeToString(t.Bytes()[:])
 }
 
 // Zero sets the field value to zero.  A newly created field value is already
 // set to zero.  This function can be useful to clear an existing field value
 // for reuse.
-func (f *fieldVal) Zero() {
+func (f *fieldVal10x26) Zero() {
 	f.n[0] = 0
 	f.n[1] = 0
 	f.n[2] = 0
@@ -156,9 +160,9 @@ func (f *fieldVal) Zero() {
 // Set sets the field value equal to the passed value.
 //
 // The field value is returned to support chaining.  This enables syntax like:
-// f := new(fieldVal).Set(f2).Add(1) so that f = f2 + 1 where f2 is not
+// f := new(fieldVal10x26).Set(f2).Add(1) so that f = f2 + 1 where f2 is not
 // modified.
-func (f *fieldVal) Set(val *fieldVal) *fieldVal {
+func (f *fieldVal10x26) Set(val *fieldVal10x26) *fieldVal10x26 {
 	*f = *val
 	return f
 }
@@ -168,8 +172,8 @@ func (f *fieldVal) Set(val *fieldVal) *fieldVal {
 // native integers.
 //
 // The field value is returned to support chaining.  This enables syntax such
-// as f := new(fieldVal).SetInt(2).Mul(f2) so that f = 2 * f2.
-func (f *fieldVal) SetInt(ui uint) *fieldVal {
+// as f := new(fieldVal10x26).SetInt(2).Mul(f2) so that f = 2 * f2.
+func (f *fieldVal10x26) SetInt(ui uint) *fieldVal10x26 {
 	f.Zero()
 	f.n[0] = uint32(ui)
 	return f
@@ -179,8 +183,8 @@ func (f *fieldVal) SetInt(ui uint) *fieldVal {
 // value representation.
 //
 // The field value is returned to support chaining.  This enables syntax like:
-// f := new(fieldVal).SetBytes(byteArray).Mul(f2) so that f = ba * f2.
-func (f *fieldVal) SetBytes(b *[32]byte) *fieldVal {
+// f := new(fieldVal10x26).SetBytes(byteArray).Mul(f2) so that f = ba * f2.
+func (f *fieldVal10x26) SetBytes(b *[32]byte) *fieldVal10x26 {
 	// Pack the 256 total bits across the 10 uint32 words with a max of
 	// 26-bits per word.  This could be done with a couple of for loops,
 	// but this unrolled version is significantly faster.  Benchmarks show
@@ -213,8 +217,8 @@ func (f *fieldVal) SetBytes(b *[32]byte) *fieldVal {
 // will be truncated.
 //
 // The field value is returned to support chaining.  This enables syntax like:
-// f := new(fieldVal).SetByteSlice(byteSlice)
-func (f *fieldVal) SetByteSlice(b []byte) *fieldVal {
+// f := new(fieldVal10x26).SetByteSlice(byteSlice)
+func (f *fieldVal10x26) SetByteSlice(b []byte) *fieldVal10x26 {
 	var b32 [32]byte
 	for i := 0; i < len(b); i++ {
 		if i < 32 {
@@ -228,8 +232,8 @@ func (f *fieldVal) SetByteSlice(b []byte) *fieldVal {
 // representation.  Only the first 32-bytes are used.
 //
 // The field value is returned to support chaining.  This enables syntax like:
-// f := new(fieldVal).SetHex("0abc").Add(1) so that f = 0x0abc + 1
-func (f *fieldVal) SetHex(hexString string) *fieldVal {
+// f := new(fieldVal10x26).SetHex("0abc").Add(1) so that f = 0x0abc + 1
+func (f *fieldVal10x26) SetHex(hexString string) *fieldVal10x26 {
 	if len(hexString)%2 != 0 {
 		hexString = "0" + hexString
 	}
@@ -240,7 +244,7 @@ func (f *fieldVal) SetHex(hexString string) *fieldVal {
 // Normalize normalizes the internal field words into the desired range and
 // performs fast modular reduction over the secp256k1 prime by making use of the
 // special form of the prime.
-func (f *fieldVal) Normalize() *fieldVal {
+func (f *fieldVal10x26) Normalize() *fieldVal10x26 {
 	// The field representation leaves 6 bits of overflow in each word so
 	// intermediate calculations can be performed without needing to
 	// propagate the carry to each higher word during the calculations.  In
@@ -369,7 +373,7 @@ func (f *fieldVal) Normalize() *fieldVal {
 //
 // The field value must be normalized for this function to return the correct
 // result.
-func (f *fieldVal) PutBytes(b *[32]byte) {
+func (f *fieldVal10x26) PutBytes(b *[32]byte) {
 	// Unpack the 256 total bits from the 10 uint32 words with a max of
 	// 26-bits per word.  This could be done with a couple of for loops,
 	// but this unrolled version is a bit faster.  Benchmarks show this is
@@ -415,14 +419,14 @@ func (f *fieldVal) PutBytes(b *[32]byte) {
 //
 // The field value must be normalized for this function to return correct
 // result.
-func (f *fieldVal) Bytes() *[32]byte {
+func (f *fieldVal10x26) Bytes() *[32]byte {
 	b := new([32]byte)
 	f.PutBytes(b)
 	return b
 }
 
 // IsZero returns whether or not the field value is equal to zero.
-func (f *fieldVal) IsZero() bool {
+func (f *fieldVal10x26) IsZero() bool {
 	// The value can only be zero if no bits are set in any of the words.
 	// This is a constant time implementation.
 	bits := f.n[0] | f.n[1] | f.n[2] | f.n[3] | f.n[4] |
@@ -435,7 +439,7 @@ func (f *fieldVal) IsZero() bool {
 //
 // The field value must be normalized for this function to return correct
 // result.
-func (f *fieldVal) IsOdd() bool {
+func (f *fieldVal10x26) IsOdd() bool {
 	// Only odd numbers have the bottom bit set.
 	return f.n[0]&1 == 1
 }
@@ -443,7 +447,7 @@ func (f *fieldVal) IsOdd() bool {
 // Equals returns whether or not the two field values are the same.  Both
 // field values being compared must be normalized for this function to return
 // the correct result.
-func (f *fieldVal) Equals(val *fieldVal) bool {
+func (f *fieldVal10x26) Equals(val *fieldVal10x26) bool {
 	// Xor only sets bits when they are different, so the two field values
 	// can only be the same if no bits are set after xoring each word.
 	// This is a constant time implementation.
@@ -460,7 +464,7 @@ func (f *fieldVal) Equals(val *fieldVal) bool {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.NegateVal(f2).AddInt(1) so that f = -f2 + 1.
-func (f *fieldVal) NegateVal(val *fieldVal, magnitude uint32) *fieldVal {
+func (f *fieldVal10x26) NegateVal(val *fieldVal10x26, magnitude uint32) *fieldVal10x26 {
 	// Negation in the field is just the prime minus the value.  However,
 	// in order to allow negation against a field value without having to
 	// normalize/reduce it first, multiply by the magnitude (that is how
@@ -498,7 +502,7 @@ func (f *fieldVal) NegateVal(val *fieldVal, magnitude uint32) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.Negate().AddInt(1) so that f = -f + 1.
-func (f *fieldVal) Negate(magnitude uint32) *fieldVal {
+func (f *fieldVal10x26) Negate(magnitude uint32) *fieldVal10x26 {
 	return f.NegateVal(f, magnitude)
 }
 
@@ -508,7 +512,7 @@ func (f *fieldVal) Negate(magnitude uint32) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.AddInt(1).Add(f2) so that f = f + 1 + f2.
-func (f *fieldVal) AddInt(ui uint) *fieldVal {
+func (f *fieldVal10x26) AddInt(ui uint) *fieldVal10x26 {
 	// Since the field representation intentionally provides overflow bits,
 	// it's ok to use carryless addition as the carry bit is safely part of
 	// the word and will be normalized out.
@@ -522,7 +526,7 @@ func (f *fieldVal) AddInt(ui uint) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.Add(f2).AddInt(1) so that f = f + f2 + 1.
-func (f *fieldVal) Add(val *fieldVal) *fieldVal {
+func (f *fieldVal10x26) Add(val *fieldVal10x26) *fieldVal10x26 {
 	// Since the field representation intentionally provides overflow bits,
 	// it's ok to use carryless addition as the carry bit is safely part of
 	// each word and will be normalized out.  This could obviously be done
@@ -545,7 +549,7 @@ func (f *fieldVal) Add(val *fieldVal) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f3.Add2(f, f2).AddInt(1) so that f3 = f + f2 + 1.
-func (f *fieldVal) Add2(val *fieldVal, val2 *fieldVal) *fieldVal {
+func (f *fieldVal10x26) Add2(val *fieldVal10x26, val2 *fieldVal10x26) *fieldVal10x26 {
 	// Since the field representation intentionally provides overflow bits,
 	// it's ok to use carryless addition as the carry bit is safely part of
 	// each word and will be normalized out.  This could obviously be done
@@ -571,7 +575,7 @@ func (f *fieldVal) Add2(val *fieldVal, val2 *fieldVal) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.MulInt(2).Add(f2) so that f = 2 * f + f2.
-func (f *fieldVal) MulInt(val uint) *fieldVal {
+func (f *fieldVal10x26) MulInt(val uint) *fieldVal10x26 {
 	// Since each word of the field representation can hold up to
 	// fieldOverflowBits extra bits which will be normalized out, it's safe
 	// to multiply each word without using a larger type or carry
@@ -601,7 +605,7 @@ func (f *fieldVal) MulInt(val uint) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.Mul(f2).AddInt(1) so that f = (f * f2) + 1.
-func (f *fieldVal) Mul(val *fieldVal) *fieldVal {
+func (f *fieldVal10x26) Mul(val *fieldVal10x26) *fieldVal10x26 {
 	return f.Mul2(f, val)
 }
 
@@ -613,7 +617,7 @@ func (f *fieldVal) Mul(val *fieldVal) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f3.Mul2(f, f2).AddInt(1) so that f3 = (f * f2) + 1.
-func (f *fieldVal) Mul2(val *fieldVal, val2 *fieldVal) *fieldVal {
+func (f *fieldVal10x26) Mul2(val *fieldVal10x26, val2 *fieldVal10x26) *fieldVal10x26 {
 	// This could be done with a couple of for loops and an array to store
 	// the intermediate terms, but this unrolled version is significantly
 	// faster.
@@ -882,7 +886,7 @@ func (f *fieldVal) Mul2(val *fieldVal, val2 *fieldVal) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.Square().Mul(f2) so that f = f^2 * f2.
-func (f *fieldVal) Square() *fieldVal {
+func (f *fieldVal10x26) Square() *fieldVal10x26 {
 	return f.SquareVal(f)
 }
 
@@ -893,7 +897,7 @@ func (f *fieldVal) Square() *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f3.SquareVal(f).Mul(f) so that f3 = f^2 * f = f^3.
-func (f *fieldVal) SquareVal(val *fieldVal) *fieldVal {
+func (f *fieldVal10x26) SquareVal(val *fieldVal10x26) *fieldVal10x26 {
 	// This could be done with a couple of for loops and an array to store
 	// the intermediate terms, but this unrolled version is significantly
 	// faster.
@@ -1113,7 +1117,7 @@ func (f *fieldVal) SquareVal(val *fieldVal) *fieldVal {
 //
 // The field value is returned to support chaining.  This enables syntax like:
 // f.Inverse().Mul(f2) so that f = f^-1 * f2.
-func (f *fieldVal) Inverse() *fieldVal {
+func (f *fieldVal10x26) Inverse() *fieldVal10x26 {
 	// Fermat's little theorem states that for a nonzero number a and prime
 	// prime p, a^(p-1) = 1 (mod p).  Since the multipliciative inverse is
 	// a*b = 1 (mod p), it follows that b = a*a^(p-2) = a^(p-1) = 1 (mod p).
@@ -1127,7 +1131,7 @@ func (f *fieldVal) Inverse() *fieldVal {
 	// The secp256k1 prime - 2 is 2^256 - 4294968275.
 	//
 	// This has a cost of 258 field squarings and 33 field multiplications.
-	var a2, a3, a4, a10, a11, a21, a42, a45, a63, a1019, a1023 fieldVal
+	var a2, a3, a4, a10, a11, a21, a42, a45, a63, a1019, a1023 fieldVal10x26
 	a2.SquareVal(f)
 	a3.Mul2(&a2, f)
 	a4.SquareVal(&a2)
@@ -0,0 +1,447 @@
+package elliptic
+
+// This file implements the field arithmetic of field.go with 5 uint64 words
+// in base 2^52 instead of 10 uint32 words in base 2^26.  On 64-bit CPUs the
+// 64x64->128 bit multiplications of math/bits compile to single
+// instructions, so a field multiplication needs 25 word products instead of
+// the 100 of the 10x26 representation.
+//
+// The algorithms are those of the 5x52 field implementation of
+// libsecp256k1.  Magnitudes follow its convention: a value of magnitude m
+// has words of at most 2*m*(2^52-1) (2*m*(2^48-1) for the most significant
+// word), so the 12 overflow bits of every word allow magnitudes up to 2048
+// before normalization, while multiplication requires a max magnitude of 8
+// just like the 10x26 implementation.
+
+import (
+	"encoding/hex"
+	"math/bits"
+)
+
+// Constants related to the 5x52 field representation.
+const (
+	// field52BaseMask is the mask for the 52 value bits of every word
+	// except the most significant one.
+	field52BaseMask = 0xfffffffffffff
+
+	// field52MSBMask is the mask for the 48 value bits of the most
+	// significant word.
+	field52MSBMask = 0xffffffffffff
+
+	// field52PrimeWordZero is word zero of the secp256k1 prime in the
+	// 5x52 representation; words one to three are field52BaseMask and
+	// word four is field52MSBMask.
+	field52PrimeWordZero = 0xffffefffffc2f
+
+	// field52R is 2^256 mod p = 4294968273 shifted left by 4 bits, the
+	// multiplier that folds the words of a product above 2^260 back into
+	// the low words.
+	field52R = 0x1000003d10
+)
+
+// fieldVal5x52 implements optimized fixed-precision arithmetic over the
+// secp256k1 finite field with the same API as fieldVal10x26.  It represents
+// each 256-bit value as 5 64-bit integers in base 2^52, leaving 12 bits of
+// overflow in each word (16 bits in the most significant word).
+//
+// The following depicts the internal representation:
+//
+//	 -----------------------------------------------------------------
+//	|        n[4]       |        n[3]       | ... |        n[0]       |
+//	| 64 bits available | 64 bits available | ... | 64 bits available |
+//	| 48 bits for value | 52 bits for value | ... | 52 bits for value |
+//	| 16 bits overflow  | 12 bits overflow  | ... | 12 bits overflow  |
+//	| Mult: 2^(52*4)    | Mult: 2^(52*3)    | ... | Mult: 2^(52*0)    |
+//	 -----------------------------------------------------------------
+type fieldVal5x52 struct {
+	n [5]uint64
+}
+
+// String returns the field value as a human-readable hex string.
+func (f fieldVal5x52) String() string {
+	t := new(fieldVal5x52).Set(&f).Normalize()
+	return hex.EncodeToString(t.Bytes()[:])
+}
+
+// Zero sets the field value to zero.
+func (f *fieldVal5x52) Zero() {
+	f.n = [5]uint64{}
+}
+
+// Set sets the field value equal to the passed value.
+func (f *fieldVal5x52) Set(val *fieldVal5x52) *fieldVal5x52 {
+	*f = *val
+	return f
+}
+
+// SetInt sets the field value to the passed integer.
+func (f *fieldVal5x52) SetInt(ui uint) *fieldVal5x52 {
+	f.n = [5]uint64{uint64(ui), 0, 0, 0, 0}
+	return f
+}
+
+// SetBytes packs the passed 32-byte big-endian value into the internal field
+// value representation.
+func (f *fieldVal5x52) SetBytes(b *[32]byte) *fieldVal5x52 {
+	// Load the value as four 64-bit little endian words and regroup the
+	// bits into 52-bit words.
+	w0 := be64(b[24:])
+	w1 := be64(b[16:])
+	w2 := be64(b[8:])
+	w3 := be64(b[0:])
+	f.n[0] = w0 & field52BaseMask
+	f.n[1] = (w0>>52 | w1<<12) & field52BaseMask
+	f.n[2] = (w1>>40 | w2<<24) & field52BaseMask
+	f.n[3] = (w2>>28 | w3<<36) & field52BaseMask
+	f.n[4] = w3 >> 16
+	return f
+}
+
+// SetByteSlice packs the passed big-endian value into the internal field value
+// representation.  Only the first 32-bytes are used.
+func (f *fieldVal5x52) SetByteSlice(b []byte) *fieldVal5x52 {
+	var b32 [32]byte
+	for i := 0; i < len(b); i++ {
+		if i < 32 {
+			b32[i+(32-len(b))] = b[i]
+		}
+	}
+	return f.SetBytes(&b32)
+}
+
+// SetHex decodes the passed big-endian hex string into the internal field value
+// representation.  Only the first 32-bytes are used.
+func (f *fieldVal5x52) SetHex(hexString string) *fieldVal5x52 {
+	if len(hexString)%2 != 0 {
+		hexString = "0" + hexString
+	}
+	bytes, _ := hex.DecodeString(hexString)
+	return f.SetByteSlice(bytes)
+}
+
+// Normalize normalizes the internal field words into the desired range and
+// performs fast modular reduction over the secp256k1 prime by making use of the
+// special form of the prime.  See fieldVal10x26.Normalize for the details.
+func (f *fieldVal5x52) Normalize() *fieldVal5x52 {
+	t0, t1, t2, t3, t4 := f.n[0], f.n[1], f.n[2], f.n[3], f.n[4]
+
+	// Reduce the bits above 2^256 into the low word and propagate the
+	// carries, after which the magnitude is one.
+	m := t4 >> 48
+	t4 &= field52MSBMask
+	t0 += m * 0x1000003d1
+	t1 += t0 >> 52
+	t0 &= field52BaseMask
+	t2 += t1 >> 52
+	t1 &= field52BaseMask
+	t3 += t2 >> 52
+	t2 &= field52BaseMask
+	t4 += t3 >> 52
+	t3 &= field52BaseMask
+
+	// The value could still be greater than the prime if there was a carry
+	// through to bit 256 or the value is at least the prime.  Do the final
+	// reduction in constant time.
+	m = t4 >> 48
+	if t4 == field52MSBMask && t1&t2&t3 == field52BaseMask &&
+		t0 >= field52PrimeWordZero {
+		m |= 1
+	}
+	t0 += m * 0x1000003d1
+	t1 += t0 >> 52
+	t0 &= field52BaseMask
+	t2 += t1 >> 52
+	t1 &= field52BaseMask
+	t3 += t2 >> 52
+	t2 &= field52BaseMask
+	t4 += t3 >> 52
+	t3 &= field52BaseMask
+	t4 &= field52MSBMask // Remove potential multiple of 2^256.
+
+	f.n = [5]uint64{t0, t1, t2, t3, t4}
+	return f
+}
+
+// PutBytes unpacks the field value to a 32-byte big-endian value using the
+// passed byte array.
+//
+// The field value must be normalized for this function to return the correct
+// result.
+func (f *fieldVal5x52) PutBytes(b *[32]byte) {
+	putBE64(b[24:], f.n[0]|f.n[1]<<52)
+	putBE64(b[16:], f.n[1]>>12|f.n[2]<<40)
+	putBE64(b[8:], f.n[2]>>24|f.n[3]<<28)
+	putBE64(b[0:], f.n[3]>>36|f.n[4]<<16)
+}
+
+// Bytes unpacks the field value to a 32-byte big-endian value.
+//
+// The field value must be normalized for this function to return correct
+// result.
+func (f *fieldVal5x52) Bytes() *[32]byte {
+	b := new([32]byte)
+	f.PutBytes(b)
+	return b
+}
+
+// IsZero returns whether or not the field value is equal to zero.
+func (f *fieldVal5x52) IsZero() bool {
+	return f.n[0]|f.n[1]|f.n[2]|f.n[3]|f.n[4] == 0
+}
+
+// IsOdd returns whether or not the field value is an odd number.
+//
+// The field value must be normalized for this function to return correct
+// result.
+func (f *fieldVal5x52) IsOdd() bool {
+	return f.n[0]&1 == 1
+}
+
+// Equals returns whether or not the two field values are the same.  Both
+// field values being compared must be normalized for this function to return
+// the correct result.
+func (f *fieldVal5x52) Equals(val *fieldVal5x52) bool {
+	bits := (f.n[0] ^ val.n[0]) | (f.n[1] ^ val.n[1]) | (f.n[2] ^ val.n[2]) |
+		(f.n[3] ^ val.n[3]) | (f.n[4] ^ val.n[4])
+	return bits == 0
+}
+
+// NegateVal negates the passed value and stores the result in f.  The caller
+// must provide the magnitude of the passed value for a correct result.
+func (f *fieldVal5x52) NegateVal(val *fieldVal5x52, magnitude uint32) *fieldVal5x52 {
+	// Subtract from 2*(magnitude+1) times the prime, which is at least the
+	// value and keeps every word positive.
+	m := 2 * (uint64(magnitude) + 1)
+	f.n[0] = m*field52PrimeWordZero - val.n[0]
+	f.n[1] = m*field52BaseMask - val.n[1]
+	f.n[2] = m*field52BaseMask - val.n[2]
+	f.n[3] = m*field52BaseMask - val.n[3]
+	f.n[4] = m*field52MSBMask - val.n[4]
+	return f
+}
+
+// Negate negates the field value.  The existing field value is modified.  The
+// caller must provide the magnitude of the field value for a correct result.
+func (f *fieldVal5x52) Negate(magnitude uint32) *fieldVal5x52 {
+	return f.NegateVal(f, magnitude)
+}
+
+// AddInt adds the passed integer to the existing field value and stores the
+// result in f.
+func (f *fieldVal5x52) AddInt(ui uint) *fieldVal5x52 {
+	f.n[0] += uint64(ui)
+	return f
+}
+
+// Add adds the passed value to the existing field value and stores the result
+// in f.
+func (f *fieldVal5x52) Add(val *fieldVal5x52) *fieldVal5x52 {
+	f.n[0] += val.n[0]
+	f.n[1] += val.n[1]
+	f.n[2] += val.n[2]
+	f.n[3] += val.n[3]
+	f.n[4] += val.n[4]
+	return f
+}
+
+// Add2 adds the passed two field values together and stores the result in f.
+func (f *fieldVal5x52) Add2(val *fieldVal5x52, val2 *fieldVal5x52) *fieldVal5x52 {
+	f.n[0] = val.n[0] + val2.n[0]
+	f.n[1] = val.n[1] + val2.n[1]
+	f.n[2] = val.n[2] + val2.n[2]
+	f.n[3] = val.n[3] + val2.n[3]
+	f.n[4] = val.n[4] + val2.n[4]
+	return f
+}
+
+// MulInt multiplies the field value by the passed int and stores the result in
+// f.  The caller must ensure the resulting magnitude stays within the
+// overflow bits.
+func (f *fieldVal5x52) MulInt(val uint) *fieldVal5x52 {
+	ui := uint64(val)
+	f.n[0] *= ui
+	f.n[1] *= ui
+	f.n[2] *= ui
+	f.n[3] *= ui
+	f.n[4] *= ui
+	return f
+}
+
+// Mul multiplies the passed value to the existing field value and stores the
+// result in f.  The magnitude of either value involved in the multiplication
+// must be a max of 8.
+func (f *fieldVal5x52) Mul(val *fieldVal5x52) *fieldVal5x52 {
+	return f.Mul2(f, val)
+}
+
+// Square squares the field value.  The existing field value is modified.  The
+// magnitude of the field must be a max of 8.
+func (f *fieldVal5x52) Square() *fieldVal5x52 {
+	return f.Mul2(f, f)
+}
+
+// SquareVal squares the passed value and stores the result in f.  The
+// magnitude of the field being squared must be a max of 8.
+func (f *fieldVal5x52) SquareVal(val *fieldVal5x52) *fieldVal5x52 {
+	return f.Mul2(val, val)
+}
+
+// uint128 is an unsigned 128-bit accumulator for Mul2.
+type uint128 struct {
+	hi, lo uint64
+}
+
+// mul64 returns a*b.
+func mul64(a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	return uint128{hi, lo}
+}
+
+// addMul returns u + a*b.
+func (u uint128) addMul(a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	var carry uint64
+	u.lo, carry = bits.Add64(u.lo, lo, 0)
+	u.hi += hi + carry
+	return u
+}
+
+// add64 returns u + a.
+func (u uint128) add64(a uint64) uint128 {
+	var carry uint64
+	u.lo, carry = bits.Add64(u.lo, a, 0)
+	u.hi += carry
+	return u
+}
+
+// rsh52 returns u >> 52.
+func (u uint128) rsh52() uint128 {
+	return uint128{u.hi >> 52, u.lo>>52 | u.hi<<12}
+}
+
+// Mul2 multiplies the passed two field values together and stores the result
+// result in f.  The magnitude of either value involved in the multiplication
+// must be a max of 8.
+func (f *fieldVal5x52) Mul2(val *fieldVal5x52, val2 *fieldVal5x52) *fieldVal5x52 {
+	// The product is sum(a[i]*b[j]*2^(52*(i+j))), whose columns 5 to 8
+	// are above 2^260.  Since 2^260 = 2^4 * 2^256 = field52R (mod p), every
+	// such column is folded into the column five below it by multiplying
+	// it by field52R while the columns are accumulated, so only two 128-bit
+	// accumulators are needed: c for the low columns and d for the high
+	// ones.
+	const M = field52BaseMask
+	const R = field52R
+	a0, a1, a2, a3, a4 := val.n[0], val.n[1], val.n[2], val.n[3], val.n[4]
+	b0, b1, b2, b3, b4 := val2.n[0], val2.n[1], val2.n[2], val2.n[3], val2.n[4]
+
+	// Column 3, with column 8 folded in.
+	d := mul64(a0, b3).addMul(a1, b2).addMul(a2, b1).addMul(a3, b0)
+	c := mul64(a4, b4)
+	d = d.addMul(c.lo&M, R)
+	c = c.rsh52()
+	t3 := d.lo & M
+	d = d.rsh52()
+
+	// Column 4, with the rest of column 8 folded in.
+	d = d.addMul(a0, b4).addMul(a1, b3).addMul(a2, b2).addMul(a3, b1).addMul(a4, b0)
+	d = d.addMul(c.lo, R)
+	t4 := d.lo & M
+	d = d.rsh52()
+	tx := t4 >> 48
+	t4 &= M >> 4
+
+	// Column 0, with column 5 folded in.  The bits of column 4 above 2^256
+	// (tx) are folded in at the same time, which is why R is shifted back
+	// by 4 bits here.
+	c = mul64(a0, b0)
+	d = d.addMul(a1, b4).addMul(a2, b3).addMul(a3, b2).addMul(a4, b1)
+	u0 := d.lo & M
+	d = d.rsh52()
+	u0 = u0<<4 | tx
+	c = c.addMul(u0, R>>4)
+	r0 := c.lo & M
+	c = c.rsh52()
+
+	// Column 1, with column 6 folded in.
+	c = c.addMul(a0, b1).addMul(a1, b0)
+	d = d.addMul(a2, b4).addMul(a3, b3).addMul(a4, b2)
+	c = c.addMul(d.lo&M, R)
+	d = d.rsh52()
+	r1 := c.lo & M
+	c = c.rsh52()
+
+	// Column 2, with column 7 folded in.
+	c = c.addMul(a0, b2).addMul(a1, b1).addMul(a2, b0)
+	d = d.addMul(a3, b4).addMul(a4, b3)
+	c = c.addMul(d.lo&M, R)
+	d = d.rsh52()
+	r2 := c.lo & M
+	c = c.rsh52()
+
+	// Columns 3 and 4 from the values saved above.
+	c = c.addMul(d.lo, R).add64(t3)
+	r3 := c.lo & M
+	c = c.rsh52()
+	r4 := c.lo + t4
+
+	f.n = [5]uint64{r0, r1, r2, r3, r4}
+	return f
+}
+
+// Inverse finds the modular multiplicative inverse of the field value.  The
+// existing field value is modified.  It uses the same addition chain as
+// fieldVal10x26.Inverse.
+func (f *fieldVal5x52) Inverse() *fieldVal5x52 {
+	var a2, a3, a4, a10, a11, a21, a42, a45, a63, a1019, a1023 fieldVal5x52
+	a2.SquareVal(f)
+	a3.Mul2(&a2, f)
+	a4.SquareVal(&a2)
+	a10.SquareVal(&a4).Mul(&a2)
+	a11.Mul2(&a10, f)
+	a21.Mul2(&a10, &a11)
+	a42.SquareVal(&a21)
+	a45.Mul2(&a42, &a3)
+	a63.Mul2(&a42, &a21)
+	a1019.SquareVal(&a63).Square().Square().Square().Mul(&a11)
+	a1023.Mul2(&a1019, &a4)
+	f.Set(&a63) // f = a^(2^6 - 1)
+
+	// f = a^(2^216 - 1) after 21 rounds of f = f^1024 * a^1023.
+	for i := 0; i < 21; i++ {
+		f.Square().Square().Square().Square().Square()
+		f.Square().Square().Square().Square().Square()
+		f.Mul(&a1023)
+	}
+	f.Square().Square().Square().Square().Square()
+	f.Square().Square().Square().Square().Square()
+	f.Mul(&a1019) // f = a^(2^226 - 5)
+	f.Square().Square().Square().Square().Square()
+	f.Square().Square().Square().Square().Square()
+	f.Mul(&a1023) // f = a^(2^236 - 4097)
+	f.Square().Square().Square().Square().Square()
+	f.Square().Square().Square().Square().Square()
+	f.Mul(&a1023) // f = a^(2^246 - 4194305)
+	f.Square().Square().Square().Square().Square()
+	f.Square().Square().Square().Square().Square()
+	return f.Mul(&a45) // f = a^(2^256 - 4294968275) = a^(p-2)
+}
+
+// be64 returns the big endian uint64 in b[:8].
+func be64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(b[7]) | uint64(b[6])<<8 | uint64(b[5])<<16 | uint64(b[4])<<24 |
+		uint64(b[3])<<32 | uint64(b[2])<<40 | uint64(b[1])<<48 | uint64(b[0])<<56
+}
+
+// putBE64 stores v in b[:8] in big endian order.
+func putBE64(b []byte, v uint64) {
+	_ = b[7]
+	b[0] = byte(v >> 56)
+	b[1] = byte(v >> 48)
+	b[2] = byte(v >> 40)
+	b[3] = byte(v >> 32)
+	b[4] = byte(v >> 24)
+	b[5] = byte(v >> 16)
+	b[6] = byte(v >> 8)
+	b[7] = byte(v)
+}
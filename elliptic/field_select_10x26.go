@@ -0,0 +1,8 @@
+//go:build !(amd64 || arm64 || ppc64 || ppc64le || mips64 || mips64le || riscv64 || s390x || loong64) || field10x26
+
+package elliptic
+
+// fieldVal is the field element type used by the curve arithmetic.  32-bit
+// platforms, and builds with the field10x26 tag, use the 10x26
+// representation.
+type fieldVal = fieldVal10x26
@@ -0,0 +1,9 @@
+//go:build (amd64 || arm64 || ppc64 || ppc64le || mips64 || mips64le || riscv64 || s390x || loong64) && !field10x26
+
+package elliptic
+
+// fieldVal is the field element type used by the curve arithmetic.  64-bit
+// platforms use the 5x52 representation, which does the multiplications with
+// native 64x64->128 bit instructions.  Build with the field10x26 tag to use
+// the 10x26 representation instead.
+type fieldVal = fieldVal5x52
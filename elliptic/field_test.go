@@ -0,0 +1,263 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// fieldPair holds the same value in both field representations.
+type fieldPair struct {
+	a fieldVal10x26
+	b fieldVal5x52
+}
+
+// setBytes sets both representations to the big endian value v.
+func (p *fieldPair) setBytes(v *[32]byte) *fieldPair {
+	p.a.SetBytes(v)
+	p.b.SetBytes(v)
+	return p
+}
+
+// check fails the test if the two representations of p hold different
+// values.
+func (p *fieldPair) check(t *testing.T, what string) {
+	t.Helper()
+	a := new(fieldVal10x26).Set(&p.a).Normalize().Bytes()
+	b := new(fieldVal5x52).Set(&p.b).Normalize().Bytes()
+	if *a != *b {
+		t.Fatalf("%s: 10x26 gives %x, 5x52 gives %x", what, a[:], b[:])
+	}
+}
+
+// testFieldValues returns field element encodings covering the edge cases of
+// the reduction as well as hash-derived ones.
+func testFieldValues() []*[32]byte {
+	p := S256().P
+	vals := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(p, big.NewInt(1)),
+		new(big.Int).Sub(p, big.NewInt(2)),
+		new(big.Int).Set(p), // Not reduced.
+		new(big.Int).Add(p, big.NewInt(1)),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+		new(big.Int).Lsh(big.NewInt(1), 52),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 208), big.NewInt(1)),
+	}
+	var out []*[32]byte
+	for _, v := range vals {
+		b := new([32]byte)
+		v.FillBytes(b[:])
+		out = append(out, b)
+	}
+	for i := 0; i < 16; i++ {
+		h := sha256.Sum256([]byte{byte(i)})
+		out = append(out, &h)
+	}
+	return out
+}
+
+func TestFieldEncoding(t *testing.T) {
+	p := S256().P
+	for _, v := range testFieldValues() {
+		var f fieldPair
+		f.setBytes(v)
+		f.check(t, "SetBytes")
+
+		want := new(big.Int).SetBytes(v[:])
+		want.Mod(want, p)
+		got := new(fieldVal5x52).SetBytes(v).Normalize().Bytes()
+		if new(big.Int).SetBytes(got[:]).Cmp(want) != 0 {
+			t.Errorf("Normalize(%x): got %x, want %x", v[:], got[:], want)
+		}
+		if s := new(fieldVal5x52).SetBytes(v).String(); s != new(fieldVal10x26).SetBytes(v).String() {
+			t.Errorf("String(%x): got %s", v[:], s)
+		}
+		a := new(fieldVal10x26).SetBytes(v).Normalize()
+		b := new(fieldVal5x52).SetBytes(v).Normalize()
+		if a.IsOdd() != b.IsOdd() || a.IsZero() != b.IsZero() {
+			t.Errorf("IsOdd/IsZero(%x) differ", v[:])
+		}
+		if !new(fieldVal5x52).SetByteSlice(v[:]).Normalize().Equals(b) {
+			t.Errorf("SetByteSlice(%x) != SetBytes", v[:])
+		}
+	}
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	vals := testFieldValues()
+	for _, x := range vals {
+		var fx fieldPair
+		fx.setBytes(x)
+
+		var r fieldPair
+		r.a.SquareVal(&fx.a)
+		r.b.SquareVal(&fx.b)
+		r.check(t, "SquareVal")
+		r.a.Square()
+		r.b.Square()
+		r.check(t, "Square")
+		r.a.Set(&fx.a).Normalize().Inverse()
+		r.b.Set(&fx.b).Normalize().Inverse()
+		r.check(t, "Inverse")
+		r.a.Set(&fx.a).MulInt(7).AddInt(3)
+		r.b.Set(&fx.b).MulInt(7).AddInt(3)
+		r.check(t, "MulInt/AddInt")
+		r.a.NegateVal(&fx.a, 1)
+		r.b.NegateVal(&fx.b, 1)
+		r.check(t, "NegateVal")
+		r.a.Negate(8)
+		r.b.Negate(8)
+		r.check(t, "Negate")
+
+		for _, y := range vals {
+			var fy fieldPair
+			fy.setBytes(y)
+			r.a.Mul2(&fx.a, &fy.a)
+			r.b.Mul2(&fx.b, &fy.b)
+			r.check(t, "Mul2")
+			r.a.Set(&fx.a).Mul(&fy.a)
+			r.b.Set(&fx.b).Mul(&fy.b)
+			r.check(t, "Mul")
+			r.a.Add2(&fx.a, &fy.a)
+			r.b.Add2(&fx.b, &fy.b)
+			r.check(t, "Add2")
+			r.a.Set(&fx.a).Add(&fy.a)
+			r.b.Set(&fx.b).Add(&fy.b)
+			r.check(t, "Add")
+		}
+	}
+}
+
+// TestFieldMagnitudes follows values through chains of operations without
+// normalizing in between, the way the point formulas use them.
+func TestFieldMagnitudes(t *testing.T) {
+	vals := testFieldValues()
+	for i := range vals {
+		x, y := vals[i], vals[(i+1)%len(vals)]
+		var fx, fy, r fieldPair
+		fx.setBytes(x)
+		fy.setBytes(y)
+
+		// r = 8*(x*y) - 3*x^2 + y, negated with magnitude 8, times x.
+		r.a.Mul2(&fx.a, &fy.a).MulInt(8)
+		r.b.Mul2(&fx.b, &fy.b).MulInt(8)
+		var t1a fieldVal10x26
+		var t1b fieldVal5x52
+		t1a.SquareVal(&fx.a).MulInt(3).Negate(3)
+		t1b.SquareVal(&fx.b).MulInt(3).Negate(3)
+		r.a.Add(&t1a).Add(&fy.a).Negate(13).Mul(&fx.a)
+		r.b.Add(&t1b).Add(&fy.b).Negate(13).Mul(&fx.b)
+		r.check(t, "chain")
+	}
+}
+
+func TestFieldAgainstBigInt(t *testing.T) {
+	p := S256().P
+	vals := testFieldValues()
+	for _, x := range vals {
+		for _, y := range vals {
+			want := new(big.Int).Mul(new(big.Int).SetBytes(x[:]), new(big.Int).SetBytes(y[:]))
+			want.Mod(want, p)
+			got := new(fieldVal5x52).SetBytes(x).Mul(new(fieldVal5x52).SetBytes(y)).Normalize().Bytes()
+			if !bytes.Equal(want.FillBytes(make([]byte, 32)), got[:]) {
+				t.Fatalf("%x * %x: got %x, want %x", x[:], y[:], got[:], want)
+			}
+		}
+	}
+}
+
+func BenchmarkFieldMul10x26(b *testing.B) {
+	h := sha256.Sum256([]byte("field"))
+	var x, y fieldVal10x26
+	x.SetBytes(&h)
+	y.SetBytes(&h)
+	for i := 0; i < b.N; i++ {
+		x.Mul(&y)
+	}
+}
+
+func BenchmarkFieldMul5x52(b *testing.B) {
+	h := sha256.Sum256([]byte("field"))
+	var x, y fieldVal5x52
+	x.SetBytes(&h)
+	y.SetBytes(&h)
+	for i := 0; i < b.N; i++ {
+		x.Mul(&y)
+	}
+}
+
+func BenchmarkFieldSquare10x26(b *testing.B) {
+	h := sha256.Sum256([]byte("field"))
+	var x fieldVal10x26
+	x.SetBytes(&h)
+	for i := 0; i < b.N; i++ {
+		x.Square()
+	}
+}
+
+func BenchmarkFieldSquare5x52(b *testing.B) {
+	h := sha256.Sum256([]byte("field"))
+	var x fieldVal5x52
+	x.SetBytes(&h)
+	for i := 0; i < b.N; i++ {
+		x.Square()
+	}
+}
+
+func BenchmarkFieldInverse10x26(b *testing.B) {
+	h := sha256.Sum256([]byte("field"))
+	var x fieldVal10x26
+	x.SetBytes(&h)
+	for i := 0; i < b.N; i++ {
+		x.Inverse()
+	}
+}
+
+func BenchmarkFieldInverse5x52(b *testing.B) {
+	h := sha256.Sum256([]byte("field"))
+	var x fieldVal5x52
+	x.SetBytes(&h)
+	for i := 0; i < b.N; i++ {
+		x.Inverse()
+	}
+}
+
+// The benchmarks below measure the operations that dominate signing,
+// verification and key derivation with the field representation selected by
+// the build, so running them with and without the field10x26 tag compares
+// the two backends end to end.
+
+func BenchmarkSign(b *testing.B) {
+	priv, _ := PrivKeyFromBytes(S256(), bytes.Repeat([]byte{0xab}, 32))
+	hash := sha256.Sum256([]byte("benchmark"))
+	for i := 0; i < b.N; i++ {
+		if _, err := priv.Sign(hash[:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	priv, pub := PrivKeyFromBytes(S256(), bytes.Repeat([]byte{0xab}, 32))
+	hash := sha256.Sum256([]byte("benchmark"))
+	sig, err := priv.Sign(hash[:])
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if !sig.Verify(hash[:], pub) {
+			b.Fatal("signature did not verify")
+		}
+	}
+}
+
+func BenchmarkPubKeyDerive(b *testing.B) {
+	k := bytes.Repeat([]byte{0xab}, 32)
+	for i := 0; i < b.N; i++ {
+		PrivKeyFromBytes(S256(), k)
+	}
+}
@@ -0,0 +1,286 @@
+package elliptic
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// This file implements the secp256k1 suites of RFC 9380, "Hashing to
+// Elliptic Curves":
+//
+//   secp256k1_XMD:SHA-256_SSWU_RO_   HashToCurve
+//   secp256k1_XMD:SHA-256_SSWU_NU_   EncodeToCurve
+//
+// secp256k1 has A = 0, which the simplified SWU map cannot handle, so field
+// elements are mapped to the 3-isogenous curve E': y^2 = x^3 + A'x + B' and
+// from there to secp256k1 with the isogeny of RFC 9380 appendix E.1.  The
+// cofactor is 1, so no cofactor clearing is needed.
+//
+// The implementation uses math/big and is not constant time; it must not be
+// used on secret inputs.
+
+// Suite identifiers, to be used as the suffix of domain separation tags.
+const (
+	HashToCurveSuite   = "secp256k1_XMD:SHA-256_SSWU_RO_"
+	EncodeToCurveSuite = "secp256k1_XMD:SHA-256_SSWU_NU_"
+)
+
+var (
+	// ErrInvalidDST is returned for an empty domain separation tag.  Tags
+	// longer than 255 bytes are hashed as RFC 9380 section 5.3.3 requires.
+	ErrInvalidDST = errors.New("hash to curve: domain separation tag must not be empty")
+
+	// ErrExpandLength is returned when expand_message_xmd is asked for
+	// more than 255 hash blocks or more than 65535 bytes.
+	ErrExpandLength = errors.New("hash to curve: requested length is too large")
+)
+
+// sswuParams are the constants of the simplified SWU map to E' and of the
+// isogeny map back to secp256k1.
+var sswuParams = struct {
+	a, b, z *big.Int
+	xNum    [4]*big.Int
+	xDen    [2]*big.Int
+	yNum    [4]*big.Int
+	yDen    [3]*big.Int
+}{
+	a: fromHex("3f8731abdd661adca08a5558f0f5d272e953d363cb6f0e5d405447c01a444533"),
+	b: big.NewInt(1771),
+	z: fromHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc24"), // -11
+	xNum: [4]*big.Int{
+		fromHex("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa8c7"),
+		fromHex("07d3d4c80bc321d5b9f315cea7fd44c5d595d2fc0bf63b92dfff1044f17c6581"),
+		fromHex("534c328d23f234e6e2a413deca25caece4506144037c40314ecbd0b53d9dd262"),
+		fromHex("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa88c"),
+	},
+	xDen: [2]*big.Int{
+		fromHex("d35771193d94918a9ca34ccbb7b640dd86cd409542f8487d9fe6b745781eb49b"),
+		fromHex("edadc6f64383dc1df7c4b2d51b54225406d36b641f5e41bbc52a56612a8c6d14"),
+	},
+	yNum: [4]*big.Int{
+		fromHex("4bda12f684bda12f684bda12f684bda12f684bda12f684bda12f684b8e38e23c"),
+		fromHex("c75e0c32d5cb7c0fa9d0a54b12a0a6d5647ab046d686da6fdffc90fc201d71a3"),
+		fromHex("29a6194691f91a73715209ef6512e576722830a201be2018a765e85a9ecee931"),
+		fromHex("2f684bda12f684bda12f684bda12f684bda12f684bda12f684bda12f38e38d84"),
+	},
+	yDen: [3]*big.Int{
+		fromHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffff93b"),
+		fromHex("7a06534bb8bdb49fd5e9e6632722c2989467c1bfc8e8d978dfb425d2685c2573"),
+		fromHex("6484aa716545ca2cf3a70c3fa8fe337e0a3d21162f0d6299a7bf8192bfd2a76f"),
+	},
+}
+
+// HashToCurve hashes msg to a point with the secp256k1_XMD:SHA-256_SSWU_RO_
+// suite, whose output is indistinguishable from a random oracle.  dst is
+// the application's domain separation tag.
+func HashToCurve(msg, dst []byte) (*PublicKey, error) {
+	u, err := hashToField(msg, dst, 2)
+	if err != nil {
+		return nil, err
+	}
+	curve := S256()
+	x0, y0 := mapToCurve(u[0])
+	x1, y1 := mapToCurve(u[1])
+	x, y := curve.Add(x0, y0, x1, y1)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, errors.New("hash to curve: result is the point at infinity")
+	}
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// EncodeToCurve hashes msg to a point with the
+// secp256k1_XMD:SHA-256_SSWU_NU_ suite.  It is cheaper than HashToCurve but
+// its output only covers about half of the curve, which is enough for
+// protocols such as VRFs that only need a point nobody knows the discrete
+// logarithm of.
+func EncodeToCurve(msg, dst []byte) (*PublicKey, error) {
+	u, err := hashToField(msg, dst, 1)
+	if err != nil {
+		return nil, err
+	}
+	x, y := mapToCurve(u[0])
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, errors.New("hash to curve: result is the point at infinity")
+	}
+	return &PublicKey{Curve: S256(), X: x, Y: y}, nil
+}
+
+// ExpandMessageXMD implements expand_message_xmd of RFC 9380 section 5.3.1
+// with SHA-256, expanding msg into lenInBytes uniformly random bytes.
+func ExpandMessageXMD(msg, dst []byte, lenInBytes int) ([]byte, error) {
+	const bInBytes = sha256.Size
+	const sInBytes = sha256.BlockSize
+
+	if len(dst) == 0 {
+		return nil, ErrInvalidDST
+	}
+	if len(dst) > 255 {
+		h := sha256.New()
+		h.Write([]byte("H2C-OVERSIZE-DST-"))
+		h.Write(dst)
+		dst = h.Sum(nil)
+	}
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	if ell > 255 || lenInBytes > 65535 || lenInBytes < 0 {
+		return nil, ErrExpandLength
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
+	h := sha256.New()
+	h.Write(make([]byte, sInBytes))
+	h.Write(msg)
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime)
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	out := make([]byte, 0, ell*bInBytes)
+	out = append(out, bi...)
+	for i := 2; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i - 1)) || I2OSP(i, 1) || DST_prime)
+		x := make([]byte, bInBytes)
+		for j := range x {
+			x[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(x)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:lenInBytes], nil
+}
+
+// hashToField implements hash_to_field of RFC 9380 section 5.2 for the
+// secp256k1 base field, with L = 48 bytes per element.
+func hashToField(msg, dst []byte, count int) ([]*big.Int, error) {
+	const l = 48
+	uniform, err := ExpandMessageXMD(msg, dst, count*l)
+	if err != nil {
+		return nil, err
+	}
+	p := S256().P
+	u := make([]*big.Int, count)
+	for i := range u {
+		e := new(big.Int).SetBytes(uniform[i*l : (i+1)*l])
+		u[i] = e.Mod(e, p)
+	}
+	return u, nil
+}
+
+// mapToCurve maps a field element to secp256k1: the simplified SWU map to
+// E' of RFC 9380 section 6.6.2 followed by the 3-isogeny map.
+func mapToCurve(u *big.Int) (*big.Int, *big.Int) {
+	xp, yp := mapToCurveSSWU(u)
+	return isoMap(xp, yp)
+}
+
+// mapToCurveSSWU is the straight-line simplified SWU map to E'.
+func mapToCurveSSWU(u *big.Int) (*big.Int, *big.Int) {
+	p := S256().P
+	a, b, z := sswuParams.a, sswuParams.b, sswuParams.z
+	mod := func(v *big.Int) *big.Int { return v.Mod(v, p) }
+
+	u2 := mod(new(big.Int).Mul(u, u))
+	zu2 := mod(new(big.Int).Mul(z, u2))
+	// tv1 = inv0(Z^2 * u^4 + Z * u^2)
+	tv1 := mod(new(big.Int).Mul(zu2, zu2))
+	tv1 = mod(tv1.Add(tv1, zu2))
+	if tv1.Sign() != 0 {
+		tv1.ModInverse(tv1, p)
+	}
+
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z * A)
+		x1 = mod(new(big.Int).Mul(z, a))
+		x1 = mod(x1.Mul(x1.ModInverse(x1, p), b))
+	} else {
+		// x1 = (-B / A) * (1 + tv1)
+		x1 = new(big.Int).ModInverse(a, p)
+		x1 = mod(x1.Mul(x1, new(big.Int).Sub(p, b)))
+		x1 = mod(x1.Mul(x1, new(big.Int).Add(tv1, big.NewInt(1))))
+	}
+
+	x, y := x1, sqrtField(sswuCurveEq(x1))
+	if y == nil {
+		x = mod(new(big.Int).Mul(zu2, x1))
+		y = sqrtField(sswuCurveEq(x))
+	}
+	if u.Bit(0) != y.Bit(0) {
+		y = mod(y.Neg(y))
+	}
+	return x, y
+}
+
+// sswuCurveEq returns x^3 + A'x + B' for E'.
+func sswuCurveEq(x *big.Int) *big.Int {
+	p := S256().P
+	gx := new(big.Int).Mul(x, x)
+	gx.Add(gx, sswuParams.a)
+	gx.Mul(gx, x)
+	gx.Add(gx, sswuParams.b)
+	return gx.Mod(gx, p)
+}
+
+// sqrtField returns a square root of v mod P, or nil if v is not a square.
+// P = 3 mod 4, so the root is v^((P+1)/4).
+func sqrtField(v *big.Int) *big.Int {
+	curve := S256()
+	y := new(big.Int).Exp(v, curve.QPlus1Div4(), curve.P)
+	check := new(big.Int).Mul(y, y)
+	if check.Mod(check, curve.P).Cmp(v) != 0 {
+		return nil
+	}
+	return y
+}
+
+// isoMap evaluates the 3-isogeny map from E' to secp256k1 of RFC 9380
+// appendix E.1.  Points where a denominator vanishes are in the kernel of
+// the isogeny and map to the point at infinity, returned as (0, 0), as
+// section 6.6.3 requires.
+func isoMap(xp, yp *big.Int) (*big.Int, *big.Int) {
+	p := S256().P
+	// Horner evaluation of a polynomial with the given coefficients,
+	// lowest degree first; monic adds a leading coefficient of 1.
+	poly := func(coeffs []*big.Int, monic bool) *big.Int {
+		acc := new(big.Int)
+		if monic {
+			acc.SetInt64(1)
+		}
+		for i := len(coeffs) - 1; i >= 0; i-- {
+			if i == len(coeffs)-1 && !monic {
+				acc.Set(coeffs[i])
+				continue
+			}
+			acc.Mul(acc, xp)
+			acc.Add(acc, coeffs[i])
+			acc.Mod(acc, p)
+		}
+		return acc
+	}
+
+	xNum := poly(sswuParams.xNum[:], false)
+	xDen := poly(sswuParams.xDen[:], true)
+	yNum := poly(sswuParams.yNum[:], false)
+	yDen := poly(sswuParams.yDen[:], true)
+	if xDen.Sign() == 0 || yDen.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+
+	x := xNum.Mul(xNum, xDen.ModInverse(xDen, p))
+	x.Mod(x, p)
+	y := yNum.Mul(yNum, yDen.ModInverse(yDen, p))
+	y.Mul(y, yp)
+	y.Mod(y, p)
+	return x, y
+}
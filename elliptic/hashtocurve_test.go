@@ -0,0 +1,106 @@
+package elliptic
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// TestExpandMessageXMD checks the expand_message_xmd(SHA-256) vectors of
+// RFC 9380 appendix K.1.
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	tests := []struct {
+		msg        string
+		lenInBytes int
+		uniform    string
+	}{
+		{"", 0x20, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", 0x20, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+		{"abcdef0123456789", 0x20, "eff31487c770a893cfb36f912fbfcbff40d5661771ca4b2cb4eafe524333f5c1"},
+		{"", 0x80, "af84c27ccfd45d41914fdff5df25293e221afc53d8ad2ac06d5e3e29485dadbee0d121587713a3e0dd4d5e69e93eb7cd4f5df4cd103e188cf60cb02edc3edf18eda8576c412b18ffb658e3dd6ec849469b979d444cf7b26911a08e63cf31f9dcc541708d3491184472c2c29bb749d4286b004ceb5ee6b9a7fa5b646c993f0ced"},
+	}
+
+	for i, test := range tests {
+		got, err := ExpandMessageXMD([]byte(test.msg), dst, test.lenInBytes)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, hexToBytes(test.uniform)) {
+			t.Errorf("#%d: got %x, want %s", i, got, test.uniform)
+		}
+	}
+
+	if _, err := ExpandMessageXMD(nil, nil, 32); err != ErrInvalidDST {
+		t.Errorf("empty DST: got %v, want %v", err, ErrInvalidDST)
+	}
+	if _, err := ExpandMessageXMD(nil, dst, 255*32+1); err != ErrExpandLength {
+		t.Errorf("oversized output: got %v, want %v", err, ErrExpandLength)
+	}
+}
+
+var hashToCurveMsgs = []string{
+	"",
+	"abc",
+	"abcdef0123456789",
+	"q128_" + strings.Repeat("q", 128),
+	"a512_" + strings.Repeat("a", 512),
+}
+
+// TestHashToCurve checks the secp256k1_XMD:SHA-256_SSWU_RO_ vectors of
+// RFC 9380 appendix J.8.1.
+func TestHashToCurve(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-" + HashToCurveSuite)
+	points := [][2]string{
+		{"c1cae290e291aee617ebaef1be6d73861479c48b841eaba9b7b5852ddfeb1346", "64fa678e07ae116126f08b022a94af6de15985c996c3a91b64c406a960e51067"},
+		{"3377e01eab42db296b512293120c6cee72b6ecf9f9205760bd9ff11fb3cb2c4b", "7f95890f33efebd1044d382a01b1bee0900fb6116f94688d487c6c7b9c8371f6"},
+		{"bac54083f293f1fe08e4a70137260aa90783a5cb84d3f35848b324d0674b0e3a", "4436476085d4c3c4508b60fcf4389c40176adce756b398bdee27bca19758d828"},
+		{"e2167bc785333a37aa562f021f1e881defb853839babf52a7f72b102e41890e9", "f2401dd95cc35867ffed4f367cd564763719fbc6a53e969fb8496a1e6685d873"},
+		{"e3c8d35aaaf0b9b647e88a0a0a7ee5d5bed5ad38238152e4e6fd8c1f8cb7c998", "8446eeb6181bf12f56a9d24e262221cc2f0c4725c7e3803024b5888ee5823aa6"},
+	}
+
+	for i, msg := range hashToCurveMsgs {
+		p, err := HashToCurve([]byte(msg), dst)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if p.X.Cmp(fromHex(points[i][0])) != 0 || p.Y.Cmp(fromHex(points[i][1])) != 0 {
+			t.Errorf("#%d: got (%x, %x), want (%s, %s)", i, p.X, p.Y, points[i][0], points[i][1])
+		}
+	}
+}
+
+// TestEncodeToCurve checks the secp256k1_XMD:SHA-256_SSWU_NU_ vectors of
+// RFC 9380 appendix J.8.2.
+func TestEncodeToCurve(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-" + EncodeToCurveSuite)
+	points := [][2]string{
+		{"a4792346075feae77ac3b30026f99c1441b4ecf666ded19b7522cf65c4c55c5b", "62c59e2a6aeed1b23be5883e833912b08ba06be7f57c0e9cdc663f31639ff3a7"},
+		{"3f3b5842033fff837d504bb4ce2a372bfeadbdbd84a1d2b678b6e1d7ee426b9d", "902910d1fef15d8ae2006fc84f2a5a7bda0e0407dc913062c3a493c4f5d876a5"},
+		{"07644fa6281c694709f53bdd21bed94dab995671e4a8cd1904ec4aa50c59bfdf", "c79f8d1dad79b6540426922f7fbc9579c3018dafeffcd4552b1626b506c21e7b"},
+	}
+
+	for i, want := range points {
+		p, err := EncodeToCurve([]byte(hashToCurveMsgs[i]), dst)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if p.X.Cmp(fromHex(want[0])) != 0 || p.Y.Cmp(fromHex(want[1])) != 0 {
+			t.Errorf("#%d: got (%x, %x), want (%s, %s)", i, p.X, p.Y, want[0], want[1])
+		}
+		if !S256().IsOnCurve(p.X, p.Y) {
+			t.Errorf("#%d: point is not on the curve", i)
+		}
+	}
+}
+
+// TestIsoMapExceptional checks that a root of the x denominator of the
+// isogeny maps to the point at infinity rather than dividing by zero.
+func TestIsoMapExceptional(t *testing.T) {
+	root := fromHex("89291c84de3e11f1041da6957255eed5fc964a4df050df221d6ad4ce6ab9c5a5")
+	x, y := isoMap(root, big.NewInt(1))
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("got (%x, %x), want the point at infinity", x, y)
+	}
+}
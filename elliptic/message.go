@@ -0,0 +1,91 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
+
+// writeVarInt serializes val to w using the bitcoin variable length integer
+// encoding: a single byte below 0xfd, otherwise a 0xfd, 0xfe or 0xff marker
+// followed by a little endian uint16, uint32 or uint64.
+func writeVarInt(w *bytes.Buffer, val uint64) {
+	var buf [8]byte
+	switch {
+	case val < 0xfd:
+		w.WriteByte(uint8(val))
+	case val <= 0xffff:
+		w.WriteByte(0xfd)
+		binary.LittleEndian.PutUint16(buf[:], uint16(val))
+		w.Write(buf[:2])
+	case val <= 0xffffffff:
+		w.WriteByte(0xfe)
+		binary.LittleEndian.PutUint32(buf[:], uint32(val))
+		w.Write(buf[:4])
+	default:
+		w.WriteByte(0xff)
+		binary.LittleEndian.PutUint64(buf[:], val)
+		w.Write(buf[:])
+	}
+}
+
+// writeVarString serializes s to w as a varint length followed by the bytes
+// of the string.
+func writeVarString(w *bytes.Buffer, s string) {
+	writeVarInt(w, uint64(len(s)))
+	w.WriteString(s)
+}
+
+// MessageHash returns the hash that SignMessage signs for message on the given
+// network: double SHA-256 of the varint framed magic prefix followed by the
+// varint framed message.
+func MessageHash(message string, net *NetParams) []byte {
+	var buf bytes.Buffer
+	writeVarString(&buf, net.SignedMessageMagic)
+	writeVarString(&buf, message)
+
+	first := sha256.Sum256(buf.Bytes())
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// SignMessage signs message with key the way `bitcoin-cli signmessage` does and
+// returns the base64 encoded 65-byte compact signature. isCompressedKey
+// selects whether the signature commits to the address of the compressed or
+// the uncompressed public key, see ToAddressCompressed and ToAddress.
+func SignMessage(key *PrivateKey, message string, net *NetParams, isCompressedKey bool) (string, error) {
+	sig, err := SignCompact(S256(), key, MessageHash(message, net), isCompressedKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// VerifyMessage checks that signature is a base64 encoded compact signature of
+// message on the given network made by the owner of address. The public key
+// is recovered from the signature and its address, compressed or not depending
+// on the signature header, must equal address.
+func VerifyMessage(address, signature, message string, net *NetParams) (bool, error) {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, fmt.Errorf("malformed base64 signature: %v", err)
+	}
+	if len(sig) == 0 || sig[0] < 27 || sig[0] >= 35 {
+		return false, fmt.Errorf("invalid compact signature header")
+	}
+
+	pubKey, wasCompressed, err := RecoverCompact(S256(), sig, MessageHash(message, net))
+	if err != nil {
+		return false, err
+	}
+
+	var recovered string
+	if wasCompressed {
+		recovered = pubKey.ToAddressCompressed()
+	} else {
+		recovered = pubKey.ToAddress()
+	}
+	return recovered == address, nil
+}
@@ -0,0 +1,85 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSignMessage(t *testing.T) {
+	seed := sha256.Sum256([]byte("key"))
+	priv, pub := PrivKeyFromBytes(S256(), seed[:])
+
+	// Produced for the same key and message by btcd's ecdsa.SignCompact over
+	// the bitcoin message hash.
+	const want = "H1ElyhW2MUsEQa6LaxOcDJr3ILuKD/TYIkyhDh7Nk2lyAAws77gzy5UeZLcJ8LFkM/3Fn1NtMjKwvQ2L6fj3sd0="
+	const address = "12mMYDFWjdB2aeNH1HVNgxasGq4cypHVUS"
+	const message = "vires in numeris"
+
+	sig, err := SignMessage(priv, message, &BitcoinNetParams, true)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if sig != want {
+		t.Fatalf("SignMessage: got %s, want %s", sig, want)
+	}
+	if pub.ToAddressCompressed() != address {
+		t.Fatalf("unexpected address %s", pub.ToAddressCompressed())
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		sig     string
+		message string
+		net     *NetParams
+		valid   bool
+	}{
+		{"valid", address, sig, message, &BitcoinNetParams, true},
+		{"other message", address, sig, "vires in numeri", &BitcoinNetParams, false},
+		{"other network", address, sig, message, &SymphonyNetParams, false},
+		{"uncompressed address", pub.ToAddress(), sig, message, &BitcoinNetParams, false},
+	}
+	for _, test := range tests {
+		valid, err := VerifyMessage(test.address, test.sig, test.message, test.net)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if valid != test.valid {
+			t.Errorf("%s: got %v, want %v", test.name, valid, test.valid)
+		}
+	}
+
+	// Uncompressed keys commit to the uncompressed address.
+	sig, err = SignMessage(priv, message, &SymphonyNetParams, false)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if valid, err := VerifyMessage(pub.ToAddress(), sig, message, &SymphonyNetParams); !valid || err != nil {
+		t.Errorf("uncompressed: got %v, %v", valid, err)
+	}
+
+	for _, bad := range []string{"", "!!!", "AAAA", want[:40]} {
+		if _, err := VerifyMessage(address, bad, message, &BitcoinNetParams); err == nil {
+			t.Errorf("malformed signature %q: expected error", bad)
+		}
+	}
+}
+
+func TestWriteVarInt(t *testing.T) {
+	tests := []struct {
+		val  uint64
+		want int
+	}{
+		{0, 1}, {0xfc, 1}, {0xfd, 3}, {0xffff, 3},
+		{0x10000, 5}, {0xffffffff, 5}, {0x100000000, 9},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		writeVarInt(&buf, test.val)
+		if buf.Len() != test.want {
+			t.Errorf("writeVarInt(%d): got %d bytes, want %d", test.val, buf.Len(), test.want)
+		}
+	}
+}
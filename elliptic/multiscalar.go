@@ -0,0 +1,279 @@
+package elliptic
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Multi-scalar multiplication computes sum(k_i * P_i) far faster than n
+// separate ScalarMult calls followed by n-1 additions: all terms share one
+// chain of point doublings, and everything stays in Jacobian coordinates
+// until the single conversion back to affine at the end.
+//
+// Every term is first split with the endomorphism as in ScalarMult, so
+// k*P = k1*P + k2*ϕ(P) with k1 and k2 of about 128 bits, which halves the
+// number of doublings.  Small batches then use Strauss' algorithm
+// (interleaved width-w NAF, [GECC] algorithm 3.51) and large ones use
+// Pippenger's bucket method with signed digits, whose cost per term
+// shrinks as the batch grows.
+
+// straussMaxTerms is the number of terms, after the endomorphism split,
+// below which Strauss' algorithm is faster than Pippenger's.
+const straussMaxTerms = 64
+
+// straussWindow is the width of the NAF used by Strauss' algorithm, which
+// needs a table of 2^(straussWindow-2) odd multiples of every point.
+const straussWindow = 5
+
+// msmTerm is one term k*P of a multi-scalar multiplication after the
+// endomorphism split, with the sign of k folded into P so that k >= 0.  P is
+// affine (z = 1) and negY caches -y.
+type msmTerm struct {
+	k    *big.Int
+	p    Point
+	negY fieldVal
+}
+
+// addJacobianPoints sets r = p + q.  r may alias p but not q.
+func (curve *KoblitzCurve) addJacobianPoints(p, q, r *Point) {
+	curve.addJacobian(&p.x, &p.y, &p.z, &q.x, &q.y, &q.z, &r.x, &r.y, &r.z)
+}
+
+// MultiScalarMult returns sum(ks[i] * (xs[i], ys[i])) where every ks[i] is
+// a big endian integer.  It panics if the slices differ in length.  Points
+// at infinity and zero scalars contribute nothing, and the sum of no terms
+// is the point at infinity (0, 0).
+func (curve *KoblitzCurve) MultiScalarMult(xs, ys []*big.Int, ks [][]byte) (*big.Int, *big.Int) {
+	if len(xs) != len(ys) || len(xs) != len(ks) {
+		panic("elliptic: MultiScalarMult called with slices of different lengths")
+	}
+
+	terms := make([]msmTerm, 0, 2*len(xs))
+	for i := range xs {
+		if xs[i].Sign() == 0 && ys[i].Sign() == 0 {
+			continue
+		}
+		px, py := curve.bigAffineToField(xs[i], ys[i])
+		terms = curve.appendTerms(terms, curve.moduloReduce(ks[i]), px, py)
+	}
+
+	var q Point
+	curve.sumTerms(terms, &q)
+	return curve.fieldJacobianToBigAffine(&q.x, &q.y, &q.z)
+}
+
+// MultiScalarMult sets p = sum(ks[i] * points[i]).  It panics if the slices
+// differ in length.
+func (p *Point) MultiScalarMult(ks []*Scalar, points []*Point) *Point {
+	if len(ks) != len(points) {
+		panic("elliptic: MultiScalarMult called with slices of different lengths")
+	}
+
+	curve := S256()
+	terms := make([]msmTerm, 0, 2*len(points))
+	for i := range points {
+		a := *points[i]
+		if !a.toAffine() {
+			continue
+		}
+		k := ks[i].Bytes()
+		terms = curve.appendTerms(terms, k[:], &a.x, &a.y)
+	}
+	curve.sumTerms(terms, p)
+	return p
+}
+
+// appendTerms splits k*(x, y) with the endomorphism and appends the terms
+// k1*(x, y) and k2*(βx, y).  x is modified.
+func (curve *KoblitzCurve) appendTerms(terms []msmTerm, k []byte, x, y *fieldVal) []msmTerm {
+	k1, k2, signK1, signK2 := curve.splitK(k)
+	terms = curve.appendTerm(terms, k1, signK1, x, y)
+
+	// ϕ(x, y) = (βx, y)
+	x.Mul(curve.beta).Normalize()
+	return curve.appendTerm(terms, k2, signK2, x, y)
+}
+
+// sumTerms sets q to the sum of the terms with the algorithm that suits
+// their number.
+func (curve *KoblitzCurve) sumTerms(terms []msmTerm, q *Point) {
+	switch {
+	case len(terms) == 0:
+		*q = Point{}
+	case len(terms) <= straussMaxTerms:
+		curve.strauss(terms, q)
+	default:
+		curve.pippenger(terms, q)
+	}
+}
+
+// appendTerm appends the term sign*k*(x, y) to terms unless k is zero.
+func (curve *KoblitzCurve) appendTerm(terms []msmTerm, k []byte, sign int, x, y *fieldVal) []msmTerm {
+	if sign == 0 {
+		return terms
+	}
+	t := msmTerm{k: new(big.Int).SetBytes(k)}
+	t.p.x.Set(x)
+	t.p.y.Set(y)
+	t.p.z.SetInt(1)
+	t.negY.NegateVal(y, 1).Normalize()
+	if sign < 0 {
+		t.p.y, t.negY = t.negY, t.p.y
+	}
+	return append(terms, t)
+}
+
+// strauss sets q to the sum of the terms using interleaved width-w NAFs.
+func (curve *KoblitzCurve) strauss(terms []msmTerm, q *Point) {
+	const tableSize = 1 << (straussWindow - 2)
+
+	// tables[i][j] = (2j+1) * P_i, and negTables holds their negations.
+	tables := make([][tableSize]Point, len(terms))
+	negTables := make([][tableSize]Point, len(terms))
+	nafs := make([][]int8, len(terms))
+	maxLen := 0
+	for i := range terms {
+		t := &terms[i]
+		var twoP Point
+		curve.doubleJacobian(&t.p.x, &t.p.y, &t.p.z, &twoP.x, &twoP.y, &twoP.z)
+		tables[i][0] = t.p
+		for j := 1; j < tableSize; j++ {
+			tables[i][j] = tables[i][j-1]
+			curve.addJacobianPoints(&tables[i][j], &twoP, &tables[i][j])
+		}
+		for j := range tables[i] {
+			negTables[i][j] = tables[i][j]
+			negTables[i][j].y.NegateVal(&tables[i][j].y, 1).Normalize()
+		}
+
+		nafs[i] = wNAF(t.k, straussWindow)
+		if len(nafs[i]) > maxLen {
+			maxLen = len(nafs[i])
+		}
+	}
+
+	*q = Point{}
+	for bit := maxLen - 1; bit >= 0; bit-- {
+		curve.doubleJacobian(&q.x, &q.y, &q.z, &q.x, &q.y, &q.z)
+		for i, naf := range nafs {
+			if bit >= len(naf) {
+				continue
+			}
+			switch d := naf[bit]; {
+			case d > 0:
+				curve.addJacobianPoints(q, &tables[i][d/2], q)
+			case d < 0:
+				curve.addJacobianPoints(q, &negTables[i][-d/2], q)
+			}
+		}
+	}
+}
+
+// wNAF returns the width-w NAF of k >= 0, least significant digit first:
+// every non-zero digit is odd and less than 2^(w-1) in absolute value, and
+// any w consecutive digits contain at most one non-zero digit.  This is
+// algorithm 3.35 from [GECC].
+func wNAF(k *big.Int, w uint) []int8 {
+	k = new(big.Int).Set(k)
+	naf := make([]int8, 0, k.BitLen()+1)
+	mod := int64(1) << w
+	for k.Sign() > 0 {
+		var d int64
+		if k.Bit(0) == 1 {
+			d = int64(k.Uint64() & uint64(mod-1))
+			if d >= mod/2 {
+				d -= mod
+			}
+			k.Sub(k, big.NewInt(d))
+		}
+		naf = append(naf, int8(d))
+		k.Rsh(k, 1)
+	}
+	return naf
+}
+
+// pippengerWindow returns the digit width for n terms, which balances the
+// n additions per window against the 2^c bucket additions per window.
+func pippengerWindow(n int) uint {
+	c := bits.Len(uint(n)) - 3
+	if c < 4 {
+		c = 4
+	}
+	if c > 16 {
+		c = 16
+	}
+	return uint(c)
+}
+
+// pippenger sets q to the sum of the terms with the bucket method.  The
+// scalars are written with signed base 2^c digits in [-2^(c-1), 2^(c-1)),
+// so every window only needs 2^(c-1) buckets and negative digits add the
+// negated point.
+func (curve *KoblitzCurve) pippenger(terms []msmTerm, q *Point) {
+	c := pippengerWindow(len(terms))
+	maxBits := 0
+	for i := range terms {
+		if l := terms[i].k.BitLen(); l > maxBits {
+			maxBits = l
+		}
+	}
+	// Leave at least two zero bits at the top so the carry of the signed
+	// digits never overflows the last window.
+	windows := (maxBits+1)/int(c) + 1
+
+	digits := make([][]int32, len(terms))
+	for i := range terms {
+		digits[i] = signedDigits(terms[i].k, c, windows)
+	}
+
+	buckets := make([]Point, 1<<(c-1))
+	*q = Point{}
+	for w := windows - 1; w >= 0; w-- {
+		for j := uint(0); j < c; j++ {
+			curve.doubleJacobian(&q.x, &q.y, &q.z, &q.x, &q.y, &q.z)
+		}
+
+		for j := range buckets {
+			buckets[j] = Point{}
+		}
+		for i := range terms {
+			d := digits[i][w]
+			switch {
+			case d > 0:
+				curve.addJacobianPoints(&buckets[d-1], &terms[i].p, &buckets[d-1])
+			case d < 0:
+				p := Point{x: terms[i].p.x, y: terms[i].negY, z: terms[i].p.z}
+				curve.addJacobianPoints(&buckets[-d-1], &p, &buckets[-d-1])
+			}
+		}
+
+		// sum(j * bucket_j) as running sums, from the top bucket down.
+		var running, sum Point
+		for j := len(buckets) - 1; j >= 0; j-- {
+			curve.addJacobianPoints(&running, &buckets[j], &running)
+			curve.addJacobianPoints(&sum, &running, &sum)
+		}
+		curve.addJacobianPoints(q, &sum, q)
+	}
+}
+
+// signedDigits writes k >= 0 as sum(d_i * 2^(c*i)) for i < windows with
+// every d_i in [-2^(c-1), 2^(c-1)).
+func signedDigits(k *big.Int, c uint, windows int) []int32 {
+	digits := make([]int32, windows)
+	var carry int32
+	for i := range digits {
+		var d int32
+		for j := int(c) - 1; j >= 0; j-- {
+			d = d<<1 | int32(k.Bit(i*int(c)+j))
+		}
+		d += carry
+		carry = 0
+		if d >= 1<<(c-1) {
+			d -= 1 << c
+			carry = 1
+		}
+		digits[i] = d
+	}
+	return digits
+}
@@ -0,0 +1,136 @@
+package elliptic
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// msmInputs returns n deterministic points and 32 byte scalars.
+func msmInputs(n int) ([]*big.Int, []*big.Int, [][]byte) {
+	curve := S256()
+	xs := make([]*big.Int, n)
+	ys := make([]*big.Int, n)
+	ks := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		var seed [8]byte
+		binary.BigEndian.PutUint64(seed[:], uint64(i))
+		p := sha256.Sum256(append([]byte("msm point"), seed[:]...))
+		k := sha256.Sum256(append([]byte("msm scalar"), seed[:]...))
+		xs[i], ys[i] = curve.ScalarBaseMult(p[:])
+		ks[i] = k[:]
+	}
+	return xs, ys, ks
+}
+
+// naiveMultiScalarMult is the reference sum of separate ScalarMult calls.
+func naiveMultiScalarMult(xs, ys []*big.Int, ks [][]byte) (*big.Int, *big.Int) {
+	curve := S256()
+	sumX, sumY := new(big.Int), new(big.Int)
+	for i := range xs {
+		x, y := curve.ScalarMult(xs[i], ys[i], ks[i])
+		sumX, sumY = curve.Add(sumX, sumY, x, y)
+	}
+	return sumX, sumY
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	curve := S256()
+	// Sizes on both sides of the switch from Strauss to Pippenger.
+	for _, n := range []int{0, 1, 2, 3, 17, straussMaxTerms / 2, straussMaxTerms/2 + 1, 200, 700} {
+		xs, ys, ks := msmInputs(n)
+		x, y := curve.MultiScalarMult(xs, ys, ks)
+		wantX, wantY := naiveMultiScalarMult(xs, ys, ks)
+		if x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+			t.Errorf("n=%d: got (%x, %x), want (%x, %x)", n, x, y, wantX, wantY)
+		}
+	}
+}
+
+func TestMultiScalarMultEdgeCases(t *testing.T) {
+	curve := S256()
+	n := curve.N
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	negGy := new(big.Int).Sub(curve.P, curve.Gy)
+	wide := append([]byte{0xff}, n.Bytes()...)
+
+	tests := []struct {
+		name   string
+		xs, ys []*big.Int
+		ks     [][]byte
+	}{
+		{"zero scalar", []*big.Int{curve.Gx}, []*big.Int{curve.Gy}, [][]byte{{}}},
+		{"scalar N", []*big.Int{curve.Gx}, []*big.Int{curve.Gy}, [][]byte{n.Bytes()}},
+		{"scalar N-1", []*big.Int{curve.Gx}, []*big.Int{curve.Gy}, [][]byte{nMinus1.Bytes()}},
+		{"scalar wider than N", []*big.Int{curve.Gx}, []*big.Int{curve.Gy}, [][]byte{wide}},
+		{"point at infinity", []*big.Int{new(big.Int), curve.Gx}, []*big.Int{new(big.Int), curve.Gy}, [][]byte{{7}, {5}}},
+		{"P + P", []*big.Int{curve.Gx, curve.Gx}, []*big.Int{curve.Gy, curve.Gy}, [][]byte{{3}, {3}}},
+		{"P - P", []*big.Int{curve.Gx, curve.Gx}, []*big.Int{curve.Gy, negGy}, [][]byte{{3}, {3}}},
+		{"k*P + (N-k)*P", []*big.Int{curve.Gx, curve.Gx}, []*big.Int{curve.Gy, curve.Gy}, [][]byte{{1}, nMinus1.Bytes()}},
+	}
+
+	for _, test := range tests {
+		x, y := curve.MultiScalarMult(test.xs, test.ys, test.ks)
+		wantX, wantY := naiveMultiScalarMult(test.xs, test.ys, test.ks)
+		if x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+			t.Errorf("%s: got (%x, %x), want (%x, %x)", test.name, x, y, wantX, wantY)
+		}
+	}
+
+	// Many copies of the same point cancelling out also exercise the
+	// doubling and infinity cases of the Pippenger buckets.
+	var xs, ys []*big.Int
+	var ks [][]byte
+	for i := 0; i < 150; i++ {
+		xs = append(xs, curve.Gx, curve.Gx)
+		ys = append(ys, curve.Gy, negGy)
+		ks = append(ks, []byte{byte(i)}, []byte{byte(i)})
+	}
+	if x, y := curve.MultiScalarMult(xs, ys, ks); x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("cancelling terms: got (%x, %x), want the point at infinity", x, y)
+	}
+}
+
+func TestWNAF(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		h := sha256.Sum256([]byte{byte(i)})
+		k := new(big.Int).SetBytes(h[:i/2+1])
+		naf := wNAF(k, straussWindow)
+		sum := new(big.Int)
+		for j := len(naf) - 1; j >= 0; j-- {
+			sum.Lsh(sum, 1)
+			sum.Add(sum, big.NewInt(int64(naf[j])))
+			if d := naf[j]; d != 0 && (d%2 == 0 || d >= 1<<(straussWindow-1) || d <= -1<<(straussWindow-1)) {
+				t.Fatalf("k=%x: invalid digit %d", k, d)
+			}
+		}
+		if sum.Cmp(k) != 0 {
+			t.Fatalf("k=%x: NAF sums to %x", k, sum)
+		}
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	curve := S256()
+	for _, n := range []int{1, 8, 64, 256, 1024, 4096, 10000} {
+		xs, ys, ks := msmInputs(n)
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.MultiScalarMult(xs, ys, ks)
+			}
+		})
+	}
+}
+
+func BenchmarkMultiScalarMultNaive(b *testing.B) {
+	for _, n := range []int{1, 8, 64, 256} {
+		xs, ys, ks := msmInputs(n)
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveMultiScalarMult(xs, ys, ks)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package elliptic
+
+// NetParams groups the constants that differ between the networks whose keys
+// and signatures this package produces.
+type NetParams struct {
+	// Name is a human readable identifier for the network.
+	Name string
+
+	// SignedMessageMagic is framed in front of every message passed to
+	// SignMessage and VerifyMessage so that a signed message can never be
+	// mistaken for a signed transaction.
+	SignedMessageMagic string
+
+	// Bech32HRPSegwit is the human-readable part of the bech32 segwit
+	// addresses of the network.
+	Bech32HRPSegwit string
+
+	// PubKeyHashAddrID and ScriptHashAddrID are the base58check version
+	// bytes of pay-to-pubkey-hash and pay-to-script-hash addresses.
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+
+	// NativeAddrs is set for networks whose pubkey hash addresses are
+	// Symphony-native account addresses, see AddressSymphony.  Such
+	// networks have no pay-to-script-hash addresses and ignore
+	// ScriptHashAddrID.
+	NativeAddrs bool
+}
+
+// SymphonyNetParams are the parameters of the symphony network.
+var SymphonyNetParams = NetParams{
+	Name:               "symphony",
+	SignedMessageMagic: "Symphony Signed Message:\n",
+	Bech32HRPSegwit:    "sy",
+	PubKeyHashAddrID:   WALLET_ADDRESS_FLAG,
+	NativeAddrs:        true,
+}
+
+// BitcoinNetParams are the parameters of the bitcoin main network. They allow
+// messages signed with `bitcoin-cli signmessage` and compatible wallets to be
+// verified.
+var BitcoinNetParams = NetParams{
+	Name:               "bitcoin",
+	SignedMessageMagic: "Bitcoin Signed Message:\n",
+	Bech32HRPSegwit:    "bc",
+	PubKeyHashAddrID:   0x00,
+	ScriptHashAddrID:   0x05,
+}
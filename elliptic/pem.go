@@ -0,0 +1,101 @@
+package elliptic
+
+import (
+	"encoding/pem"
+	"errors"
+)
+
+// PEM block types of the key encodings, as written by OpenSSL.
+const (
+	PEMTypeECPrivateKey        = "EC PRIVATE KEY"
+	PEMTypePrivateKey          = "PRIVATE KEY"
+	PEMTypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+	PEMTypePublicKey           = "PUBLIC KEY"
+
+	// pemTypeECParameters is the block that `openssl ecparam -genkey`
+	// writes in front of the key unless given -noout.
+	pemTypeECParameters = "EC PARAMETERS"
+)
+
+// ErrPEMPassphrase is returned by ParsePrivateKeyPEM for encrypted keys when
+// no passphrase is given.
+var ErrPEMPassphrase = errors.New("private key is encrypted, a passphrase is required")
+
+// MarshalSEC1PEM returns the private key as an "EC PRIVATE KEY" PEM block,
+// see MarshalSEC1.
+func (p *PrivateKey) MarshalSEC1PEM() ([]byte, error) {
+	return marshalPEM(PEMTypeECPrivateKey, p.MarshalSEC1)
+}
+
+// MarshalPKCS8PEM returns the private key as a "PRIVATE KEY" PEM block, see
+// MarshalPKCS8.
+func (p *PrivateKey) MarshalPKCS8PEM() ([]byte, error) {
+	return marshalPEM(PEMTypePrivateKey, p.MarshalPKCS8)
+}
+
+// MarshalEncryptedPKCS8PEM returns the private key encrypted with passphrase
+// as an "ENCRYPTED PRIVATE KEY" PEM block, see MarshalEncryptedPKCS8.
+func (p *PrivateKey) MarshalEncryptedPKCS8PEM(passphrase []byte) ([]byte, error) {
+	return marshalPEM(PEMTypeEncryptedPrivateKey, func() ([]byte, error) {
+		return p.MarshalEncryptedPKCS8(passphrase)
+	})
+}
+
+// MarshalPKIXPEM returns the public key as a "PUBLIC KEY" PEM block, see
+// MarshalPKIX.
+func (p *PublicKey) MarshalPKIXPEM() ([]byte, error) {
+	return marshalPEM(PEMTypePublicKey, p.MarshalPKIX)
+}
+
+// marshalPEM wraps the DER output of marshal in a PEM block of the type.
+func marshalPEM(blockType string, marshal func() ([]byte, error)) ([]byte, error) {
+	der, err := marshal()
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(der)
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses the first private key PEM block of data, which
+// may be an "EC PRIVATE KEY", a "PRIVATE KEY" or, given the passphrase, an
+// "ENCRYPTED PRIVATE KEY".  An "EC PARAMETERS" block in front of the key is
+// skipped.  Keys encrypted with the legacy OpenSSL Proc-Type headers are not
+// supported.
+func ParsePrivateKeyPEM(data, passphrase []byte) (*PrivateKey, error) {
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no private key PEM block found")
+		}
+		data = rest
+		if block.Type == pemTypeECParameters {
+			continue
+		}
+		if _, ok := block.Headers["Proc-Type"]; ok {
+			return nil, errors.New("legacy encrypted PEM blocks are not supported")
+		}
+
+		switch block.Type {
+		case PEMTypeECPrivateKey:
+			return ParseSEC1PrivateKey(block.Bytes)
+		case PEMTypePrivateKey:
+			return ParsePKCS8PrivateKey(block.Bytes)
+		case PEMTypeEncryptedPrivateKey:
+			if passphrase == nil {
+				return nil, ErrPEMPassphrase
+			}
+			return ParseEncryptedPKCS8PrivateKey(block.Bytes, passphrase)
+		}
+		return nil, errors.New("unexpected PEM block type " + block.Type)
+	}
+}
+
+// ParsePublicKeyPEM parses the first "PUBLIC KEY" PEM block of data.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != PEMTypePublicKey {
+		return nil, errors.New("no public key PEM block found")
+	}
+	return ParsePKIXPublicKey(block.Bytes)
+}
@@ -0,0 +1,267 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The fixtures in testdata were produced by OpenSSL 3.0 with:
+//
+//	openssl ecparam -name secp256k1 -genkey -out secp256k1_sec1.pem
+//	openssl ec -in secp256k1_sec1.pem -no_public -out secp256k1_sec1_nopub.pem
+//	openssl pkey -in secp256k1_sec1.pem -out secp256k1_pkcs8.pem
+//	openssl pkey -in secp256k1_sec1.pem -pubout -out secp256k1_pub.pem
+//	openssl ec -in secp256k1_sec1.pem -pubout -conv_form compressed -out secp256k1_pub_compressed.pem
+//	openssl pkcs8 -topk8 -in secp256k1_sec1.pem -v2 aes-256-cbc -v2prf hmacWithSHA256 \
+//		-passout pass:correct-horse -out secp256k1_pkcs8_aes256.pem
+//	openssl pkcs8 -topk8 -in secp256k1_sec1.pem -v2 aes-128-cbc -v2prf hmacWithSHA1 \
+//		-passout pass:correct-horse -out secp256k1_pkcs8_aes128_sha1.pem
+const (
+	fixtureKey        = "23b71c350e3ea8059eaff16f40524bffe703dc197748cca8e936b19519decd09"
+	fixturePassphrase = "correct-horse"
+)
+
+// readPEMFixture returns the contents of a testdata file and the DER bytes
+// of its last PEM block.
+func readPEMFixture(t *testing.T, name string) ([]byte, []byte) {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var der []byte
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		der = block.Bytes
+	}
+	return data, der
+}
+
+func TestParsePrivateKeyPEMFixtures(t *testing.T) {
+	want := hexToBytes(fixtureKey)
+	tests := []struct {
+		file       string
+		passphrase []byte
+	}{
+		{"secp256k1_sec1.pem", nil},
+		{"secp256k1_sec1_nopub.pem", nil},
+		{"secp256k1_pkcs8.pem", nil},
+		{"secp256k1_pkcs8_aes256.pem", []byte(fixturePassphrase)},
+		{"secp256k1_pkcs8_aes128_sha1.pem", []byte(fixturePassphrase)},
+	}
+	for _, test := range tests {
+		data, _ := readPEMFixture(t, test.file)
+		priv, err := ParsePrivateKeyPEM(data, test.passphrase)
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if !bytes.Equal(priv.PrivatekeyToBytes(), want) {
+			t.Errorf("%s: key %x, want %s", test.file, priv.PrivatekeyToBytes(), fixtureKey)
+		}
+		if test.passphrase == nil {
+			continue
+		}
+		if _, err := ParsePrivateKeyPEM(data, nil); err != ErrPEMPassphrase {
+			t.Errorf("%s without passphrase: got %v, want %v", test.file, err, ErrPEMPassphrase)
+		}
+		if _, err := ParsePrivateKeyPEM(data, []byte("battery-staple")); err != ErrPKCS8Passphrase {
+			t.Errorf("%s with wrong passphrase: got %v, want %v", test.file, err, ErrPKCS8Passphrase)
+		}
+	}
+}
+
+func TestMarshalMatchesOpenSSL(t *testing.T) {
+	priv, pub := PrivKeyFromBytes(S256(), hexToBytes(fixtureKey))
+
+	tests := []struct {
+		file    string
+		marshal func() ([]byte, error)
+	}{
+		{"secp256k1_sec1.pem", priv.MarshalSEC1},
+		{"secp256k1_pkcs8.pem", priv.MarshalPKCS8},
+		{"secp256k1_pub.pem", pub.MarshalPKIX},
+	}
+	for _, test := range tests {
+		_, want := readPEMFixture(t, test.file)
+		got, err := test.marshal()
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: DER differs from OpenSSL\n got %x\nwant %x", test.file, got, want)
+		}
+	}
+
+	for _, file := range []string{"secp256k1_pub.pem", "secp256k1_pub_compressed.pem"} {
+		data, _ := readPEMFixture(t, file)
+		parsed, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if parsed.X.Cmp(pub.X) != 0 || parsed.Y.Cmp(pub.Y) != 0 {
+			t.Errorf("%s: parsed another public key", file)
+		}
+	}
+}
+
+func TestPEMRoundTrip(t *testing.T) {
+	priv, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := []byte("round trip")
+
+	tests := []struct {
+		name    string
+		marshal func() ([]byte, error)
+	}{
+		{"SEC1", priv.MarshalSEC1PEM},
+		{"PKCS8", priv.MarshalPKCS8PEM},
+		{"encrypted PKCS8", func() ([]byte, error) { return priv.MarshalEncryptedPKCS8PEM(passphrase) }},
+	}
+	for _, test := range tests {
+		data, err := test.marshal()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got, err := ParsePrivateKeyPEM(data, passphrase)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got.D.Cmp(priv.D) != 0 {
+			t.Errorf("%s: round trip changed the key", test.name)
+		}
+	}
+
+	data, err := priv.ECPubKey().MarshalPKIXPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePublicKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+		t.Error("public key round trip changed the key")
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	// Keys of another curve, as crypto/x509 writes them.
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(p256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePKCS8PrivateKey(der); err != ErrNotSecp256k1 {
+		t.Errorf("P-256 PKCS #8: got %v, want %v", err, ErrNotSecp256k1)
+	}
+	der, err = x509.MarshalECPrivateKey(p256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSEC1PrivateKey(der); err != ErrNotSecp256k1 {
+		t.Errorf("P-256 SEC 1: got %v, want %v", err, ErrNotSecp256k1)
+	}
+	der, err = x509.MarshalPKIXPublicKey(&p256.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePKIXPublicKey(der); err != ErrNotSecp256k1 {
+		t.Errorf("P-256 public key: got %v, want %v", err, ErrNotSecp256k1)
+	}
+
+	// An encrypted key of another curve decrypts, it is just not usable.
+	der, err = x509.MarshalPKCS8PrivateKey(p256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = encryptPKCS8(der, []byte(fixturePassphrase), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseEncryptedPKCS8PrivateKey(der, []byte(fixturePassphrase)); err != ErrNotSecp256k1 {
+		t.Errorf("encrypted P-256 PKCS #8: got %v, want %v", err, ErrNotSecp256k1)
+	}
+
+	// A public key that does not belong to the private key.
+	priv, _ := PrivKeyFromBytes(S256(), hexToBytes(fixtureKey))
+	other, _ := NewPrivateKey()
+	priv.PublicKey = other.PublicKey
+	der, err = priv.MarshalSEC1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSEC1PrivateKey(der); err == nil {
+		t.Error("accepted a mismatched public key")
+	}
+
+	_, der = readPEMFixture(t, "secp256k1_sec1.pem")
+	if _, err := ParseSEC1PrivateKey(append(der, 0)); err == nil {
+		t.Error("accepted trailing data")
+	}
+	if _, err := ParsePrivateKeyPEM([]byte("not PEM"), nil); err == nil {
+		t.Error("parsed a key from garbage")
+	}
+
+	// The PBKDF2 iteration count is bounded before any work is done.
+	der, err = other.MarshalEncryptedPKCS8([]byte(fixturePassphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	der = setPBKDF2Iterations(t, der, maxPBKDF2Iterations+1)
+	if _, err := ParseEncryptedPKCS8PrivateKey(der, []byte(fixturePassphrase)); err == nil {
+		t.Error("accepted a PBKDF2 iteration count above the limit")
+	}
+}
+
+// setPBKDF2Iterations rewrites the PBKDF2 iteration count of an encrypted
+// PKCS #8 key.
+func setPBKDF2Iterations(t *testing.T, der []byte, iterations int) []byte {
+	var info encryptedPrivateKeyInfo
+	var params pbes2Params
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		t.Fatal(err)
+	}
+	if err := unmarshalParams(info.Algo.Parameters, &params); err != nil {
+		t.Fatal(err)
+	}
+	if err := unmarshalParams(params.KeyDerivationFunc.Parameters, &kdf); err != nil {
+		t.Fatal(err)
+	}
+
+	kdf.IterationCount = iterations
+	b, err := asn1.Marshal(kdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params.KeyDerivationFunc.Parameters = asn1.RawValue{FullBytes: b}
+	if b, err = asn1.Marshal(params); err != nil {
+		t.Fatal(err)
+	}
+	info.Algo.Parameters = asn1.RawValue{FullBytes: b}
+	if der, err = asn1.Marshal(info); err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
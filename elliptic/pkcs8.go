@@ -0,0 +1,328 @@
+package elliptic
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// pkcs8 is the PrivateKeyInfo structure of RFC 5208.  The optional
+// attributes are not supported.
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// MarshalPKCS8 returns the DER encoding of the private key as an RFC 5208
+// PrivateKeyInfo wrapping an ECPrivateKey, the "PRIVATE KEY" format of
+// `openssl pkey`.
+func (p *PrivateKey) MarshalPKCS8() ([]byte, error) {
+	params, err := asn1.Marshal(oidNamedCurveSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	// As with OpenSSL, the curve is only given by the algorithm
+	// identifier and not repeated in the ECPrivateKey.
+	inner, err := p.marshalECPrivateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(inner)
+	return asn1.Marshal(pkcs8{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PrivateKey: inner,
+	})
+}
+
+// ParsePKCS8PrivateKey parses the DER encoding of an unencrypted RFC 5208
+// PrivateKeyInfo of a secp256k1 private key.
+func ParsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	var key pkcs8
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return nil, errors.New("malformed PKCS #8 private key: " + err.Error())
+	}
+	defer zeroBytes(key.PrivateKey)
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after PKCS #8 private key")
+	}
+	if key.Version != 0 {
+		return nil, errors.New("unsupported PKCS #8 version")
+	}
+	if err := checkSecp256k1Algorithm(key.Algo); err != nil {
+		return nil, err
+	}
+	return parseECPrivateKey(key.PrivateKey, oidNamedCurveSecp256k1)
+}
+
+// Encrypted PKCS #8 keys use PBES2 from RFC 8018: a PBKDF2 derived key
+// encrypts the PrivateKeyInfo with AES-CBC.
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// pbes2Iterations is the PBKDF2 iteration count of MarshalEncryptedPKCS8.
+const pbes2Iterations = 100000
+
+// pbes2SaltSize is the PBKDF2 salt size of MarshalEncryptedPKCS8.
+const pbes2SaltSize = 16
+
+// maxPBKDF2Iterations bounds the PBKDF2 iteration count accepted by
+// ParseEncryptedPKCS8PrivateKey, so that a crafted key cannot make it run
+// for hours.  It is a hundred times what MarshalEncryptedPKCS8 uses.
+const maxPBKDF2Iterations = 10000000
+
+// ErrPKCS8Passphrase is returned by ParseEncryptedPKCS8PrivateKey when the
+// key does not decrypt, which almost always means a wrong passphrase.
+var ErrPKCS8Passphrase = errors.New("encrypted private key does not decrypt, wrong passphrase")
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo structure of
+// RFC 5208.
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params is the PBES2-params structure of RFC 8018.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is the PBKDF2-params structure of RFC 8018.  The PRF
+// defaults to HMAC-SHA1 when absent.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// MarshalEncryptedPKCS8 returns the DER encoding of the private key as an
+// RFC 5208 EncryptedPrivateKeyInfo, the "ENCRYPTED PRIVATE KEY" format of
+// `openssl pkcs8 -topk8`.  The PrivateKeyInfo is encrypted with PBES2 using
+// PBKDF2 with HMAC-SHA256 and AES-256-CBC.
+func (p *PrivateKey) MarshalEncryptedPKCS8(passphrase []byte) ([]byte, error) {
+	plain, err := p.MarshalPKCS8()
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(plain)
+	return encryptPKCS8(plain, passphrase, pbes2Iterations)
+}
+
+// encryptPKCS8 wraps the DER encoding of a PrivateKeyInfo in an
+// EncryptedPrivateKeyInfo, see MarshalEncryptedPKCS8.
+func encryptPKCS8(plain, passphrase []byte, iterations int) ([]byte, error) {
+	salt := make([]byte, pbes2SaltSize)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2.Key(passphrase, salt, iterations, 32, sha256.New)
+	defer zeroBytes(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padded := pkcs7Pad(plain, aes.BlockSize)
+	defer zeroBytes(padded)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParam},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: encrypted,
+	})
+}
+
+// ParseEncryptedPKCS8PrivateKey decrypts and parses the DER encoding of an
+// RFC 5208 EncryptedPrivateKeyInfo of a secp256k1 private key.  Only PBES2
+// with PBKDF2 (HMAC-SHA1, HMAC-SHA256 or HMAC-SHA512) and AES-CBC is
+// supported, which covers the output of OpenSSL 1.1 and later.
+func ParseEncryptedPKCS8PrivateKey(der, passphrase []byte) (*PrivateKey, error) {
+	var info encryptedPrivateKeyInfo
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return nil, errors.New("malformed encrypted PKCS #8 private key: " + err.Error())
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after encrypted PKCS #8 private key")
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, errors.New("unsupported PKCS #8 encryption, only PBES2 is supported")
+	}
+	var params pbes2Params
+	if err := unmarshalParams(info.Algo.Parameters, &params); err != nil {
+		return nil, err
+	}
+
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.New("unsupported PBES2 key derivation, only PBKDF2 is supported")
+	}
+	var kdf pbkdf2Params
+	if err := unmarshalParams(params.KeyDerivationFunc.Parameters, &kdf); err != nil {
+		return nil, err
+	}
+	if kdf.IterationCount < 1 || kdf.IterationCount > maxPBKDF2Iterations {
+		return nil, errors.New("invalid PBKDF2 iteration count")
+	}
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0 || kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, errors.New("unsupported PBKDF2 pseudorandom function")
+	}
+
+	var keyLen int
+	switch {
+	case params.EncryptionScheme.Algorithm.Equal(oidAES128CBC):
+		keyLen = 16
+	case params.EncryptionScheme.Algorithm.Equal(oidAES192CBC):
+		keyLen = 24
+	case params.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		keyLen = 32
+	default:
+		return nil, errors.New("unsupported PBES2 cipher, only AES-CBC is supported")
+	}
+	if kdf.KeyLength != 0 && kdf.KeyLength != keyLen {
+		return nil, errors.New("PBKDF2 key length does not match the cipher")
+	}
+	var iv []byte
+	if err := unmarshalParams(params.EncryptionScheme.Parameters, &iv); err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid AES-CBC initialization vector")
+	}
+	if len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted private key is not a whole number of blocks")
+	}
+
+	key := pbkdf2.Key(passphrase, kdf.Salt, kdf.IterationCount, keyLen, prf)
+	defer zeroBytes(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(info.EncryptedData))
+	defer zeroBytes(plain)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+
+	// A wrong key passes the padding check once in about 256 tries and
+	// then yields garbage, which is not a PrivateKeyInfo.  Anything that
+	// is one decrypted fine, and its errors are those of the key.
+	unpadded, ok := pkcs7Unpad(plain, aes.BlockSize)
+	if !ok {
+		return nil, ErrPKCS8Passphrase
+	}
+	var keyInfo pkcs8
+	rest, err = asn1.Unmarshal(unpadded, &keyInfo)
+	zeroBytes(keyInfo.PrivateKey)
+	if err != nil || len(rest) > 0 {
+		return nil, ErrPKCS8Passphrase
+	}
+	return ParsePKCS8PrivateKey(unpadded)
+}
+
+// unmarshalParams parses the parameters of an algorithm identifier into out.
+func unmarshalParams(params asn1.RawValue, out interface{}) error {
+	rest, err := asn1.Unmarshal(params.FullBytes, out)
+	if err != nil {
+		return errors.New("malformed algorithm parameters: " + err.Error())
+	}
+	if len(rest) > 0 {
+		return errors.New("trailing data after algorithm parameters")
+	}
+	return nil
+}
+
+// pkcs7Pad appends the RFC 5652 padding of b to a multiple of the block
+// size.
+func pkcs7Pad(b []byte, blockSize int) []byte {
+	n := blockSize - len(b)%blockSize
+	out := make([]byte, len(b), len(b)+n)
+	copy(out, b)
+	for i := 0; i < n; i++ {
+		out = append(out, byte(n))
+	}
+	return out
+}
+
+// pkcs7Unpad strips the RFC 5652 padding of b and reports whether it was
+// well formed.
+func pkcs7Unpad(b []byte, blockSize int) ([]byte, bool) {
+	n := int(b[len(b)-1])
+	if n == 0 || n > blockSize || n > len(b) {
+		return nil, false
+	}
+	want := make([]byte, n)
+	for i := range want {
+		want[i] = byte(n)
+	}
+	if subtle.ConstantTimeCompare(b[len(b)-n:], want) != 1 {
+		return nil, false
+	}
+	return b[:len(b)-n], true
+}
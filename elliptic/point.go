@@ -0,0 +1,226 @@
+package elliptic
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrPointAtInfinity is returned when the point at infinity is converted to
+// a public key.
+var ErrPointAtInfinity = errors.New("point is the point at infinity")
+
+// Point is a point on secp256k1 in Jacobian coordinates, the affine point
+// (x/z^2, y/z^3).  The zero value, with z = 0, is the point at infinity,
+// the identity of the group.  Like big.Int, the arithmetic methods set the
+// receiver to the result and return it, and operands may alias the
+// receiver:
+//
+//	var p Point
+//	p.ScalarBaseMult(&k).Add(&p, &q) // p = k*G + q
+//
+// Points stay in Jacobian coordinates between operations, so chains of
+// additions and multiplications only pay for the conversion to affine
+// coordinates when the result is encoded.
+type Point struct {
+	x, y, z fieldVal
+}
+
+// NewPoint returns the point with the affine coordinates (x, y), which must
+// be on the curve.  (0, 0) is accepted as the point at infinity, following
+// the convention of the crypto/elliptic.Curve methods.
+func NewPoint(x, y *big.Int) (*Point, error) {
+	p := new(Point)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return p, nil
+	}
+	curve := S256()
+	if x.Sign() < 0 || x.Cmp(curve.P) >= 0 {
+		return nil, ErrPubKeyXTooBig
+	}
+	if y.Sign() < 0 || y.Cmp(curve.P) >= 0 {
+		return nil, ErrPubKeyYTooBig
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, ErrPubKeyNotOnCurve
+	}
+	p.x.SetByteSlice(x.Bytes())
+	p.y.SetByteSlice(y.Bytes())
+	p.z.SetInt(1)
+	return p, nil
+}
+
+// Generator returns the base point G.
+func Generator() *Point {
+	curve := S256()
+	p, _ := NewPoint(curve.Gx, curve.Gy)
+	return p
+}
+
+// ParsePoint parses a point in the compressed or uncompressed SEC1 format,
+// or the single zero byte that SEC1 uses for the point at infinity.
+func ParsePoint(b []byte) (*Point, error) {
+	if len(b) == 1 && b[0] == 0x00 {
+		return new(Point), nil
+	}
+	pub, err := ParsePubKey(b, S256())
+	if err != nil {
+		return nil, err
+	}
+	return NewPoint(pub.X, pub.Y)
+}
+
+// Set sets p = a.
+func (p *Point) Set(a *Point) *Point {
+	*p = *a
+	return p
+}
+
+// SetIdentity sets p to the point at infinity.
+func (p *Point) SetIdentity() *Point {
+	*p = Point{}
+	return p
+}
+
+// IsIdentity reports whether p is the point at infinity.
+func (p *Point) IsIdentity() bool {
+	var z fieldVal
+	return z.Set(&p.z).Normalize().IsZero()
+}
+
+// Equal reports whether p and a are the same point.  Jacobian coordinates
+// are not unique, so this compares x1*z2^2 with x2*z1^2 and y1*z2^3 with
+// y2*z1^3.
+func (p *Point) Equal(a *Point) bool {
+	pInf, aInf := p.IsIdentity(), a.IsIdentity()
+	if pInf || aInf {
+		return pInf == aInf
+	}
+	var z1z1, z2z2, u1, u2, s1, s2 fieldVal
+	z1z1.SquareVal(&p.z)
+	z2z2.SquareVal(&a.z)
+	u1.Set(&p.x).Mul(&z2z2).Normalize()
+	u2.Set(&a.x).Mul(&z1z1).Normalize()
+	s1.Set(&p.y).Mul(&z2z2).Mul(&a.z).Normalize()
+	s2.Set(&a.y).Mul(&z1z1).Mul(&p.z).Normalize()
+	return u1.Equals(&u2) && s1.Equals(&s2)
+}
+
+// Add sets p = a + b.
+func (p *Point) Add(a, b *Point) *Point {
+	// addJacobian normalizes the z values of its inputs and its result
+	// must not alias the second one, so work on copies.
+	t1, t2 := *a, *b
+	S256().addJacobian(&t1.x, &t1.y, &t1.z, &t2.x, &t2.y, &t2.z, &p.x, &p.y, &p.z)
+	return p
+}
+
+// Sub sets p = a - b.
+func (p *Point) Sub(a, b *Point) *Point {
+	var negB Point
+	negB.Negate(b)
+	return p.Add(a, &negB)
+}
+
+// Double sets p = 2*a.
+func (p *Point) Double(a *Point) *Point {
+	t := *a
+	S256().doubleJacobian(&t.x, &t.y, &t.z, &p.x, &p.y, &p.z)
+	return p
+}
+
+// Negate sets p = -a.
+func (p *Point) Negate(a *Point) *Point {
+	t := *a
+	p.x = t.x
+	p.y.NegateVal(t.y.Normalize(), 1).Normalize()
+	p.z = t.z
+	return p
+}
+
+// ScalarMult sets p = k*a.
+func (p *Point) ScalarMult(k *Scalar, a *Point) *Point {
+	return p.MultiScalarMult([]*Scalar{k}, []*Point{a})
+}
+
+// ScalarBaseMult sets p = k*G using the precomputed multiples of G.
+func (p *Point) ScalarBaseMult(k *Scalar) *Point {
+	curve := S256()
+	bytePoints := curve.precomputedBytePoints()
+	var q Point
+	for i, byteVal := range k.Bytes() {
+		b := bytePoints[i][byteVal]
+		curve.addJacobian(&q.x, &q.y, &q.z, &b[0], &b[1], &b[2], &q.x, &q.y, &q.z)
+	}
+	*p = q
+	return p
+}
+
+// Affine returns the affine coordinates of p, or (0, 0) for the point at
+// infinity.
+func (p *Point) Affine() (*big.Int, *big.Int) {
+	t := *p
+	if !t.toAffine() {
+		return new(big.Int), new(big.Int)
+	}
+	return new(big.Int).SetBytes(t.x.Bytes()[:]), new(big.Int).SetBytes(t.y.Bytes()[:])
+}
+
+// PubKey returns p as a public key.
+func (p *Point) PubKey() (*PublicKey, error) {
+	if p.IsIdentity() {
+		return nil, ErrPointAtInfinity
+	}
+	x, y := p.Affine()
+	return &PublicKey{Curve: S256(), X: x, Y: y}, nil
+}
+
+// SerializeCompressed returns the 33 byte compressed SEC1 encoding of p, or
+// a single zero byte for the point at infinity.
+func (p *Point) SerializeCompressed() []byte {
+	t := *p
+	if !t.toAffine() {
+		return []byte{0x00}
+	}
+	b := make([]byte, 0, LenPubKeyBytesCompressed)
+	format := PubkeyCompressed
+	if t.y.IsOdd() {
+		format |= 0x1
+	}
+	b = append(b, format)
+	return append(b, t.x.Bytes()[:]...)
+}
+
+// SerializeUncompressed returns the 65 byte uncompressed SEC1 encoding of p,
+// or a single zero byte for the point at infinity.
+func (p *Point) SerializeUncompressed() []byte {
+	t := *p
+	if !t.toAffine() {
+		return []byte{0x00}
+	}
+	b := make([]byte, 0, LenPubKeyBytesUnCompressed)
+	b = append(b, PubkeyUncompressed)
+	b = append(b, t.x.Bytes()[:]...)
+	return append(b, t.y.Bytes()[:]...)
+}
+
+// toAffine converts p to z = 1 with normalized coordinates, reporting false
+// and leaving p unchanged if it is the point at infinity.
+func (p *Point) toAffine() bool {
+	if p.IsIdentity() {
+		return false
+	}
+	if p.z.Normalize().Equals(fieldOne) {
+		p.x.Normalize()
+		p.y.Normalize()
+		return true
+	}
+	var zInv, tempZ fieldVal
+	zInv.Set(&p.z).Inverse()  // zInv = Z^-1
+	tempZ.SquareVal(&zInv)    // tempZ = Z^-2
+	p.x.Mul(&tempZ)           // X = X/Z^2
+	p.y.Mul(tempZ.Mul(&zInv)) // Y = Y/Z^3
+	p.z.SetInt(1)
+	p.x.Normalize()
+	p.y.Normalize()
+	return true
+}
@@ -0,0 +1,143 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// checkPoint fails the test if p is not the affine point (x, y).
+func checkPoint(t *testing.T, what string, p *Point, x, y *big.Int) {
+	t.Helper()
+	px, py := p.Affine()
+	if px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+		t.Errorf("%s: got (%x, %x), want (%x, %x)", what, px, py, x, y)
+	}
+}
+
+func TestPointArithmetic(t *testing.T) {
+	curve := S256()
+	for i := 0; i < 8; i++ {
+		h1 := sha256.Sum256([]byte{1, byte(i)})
+		h2 := sha256.Sum256([]byte{2, byte(i)})
+		var k1, k2 Scalar
+		k1.SetBytes(h1[:])
+		k2.SetBytes(h2[:])
+
+		var a, b Point
+		a.ScalarBaseMult(&k1)
+		b.ScalarBaseMult(&k2)
+		ax, ay := curve.ScalarBaseMult(h1[:])
+		bx, by := curve.ScalarBaseMult(h2[:])
+		checkPoint(t, "ScalarBaseMult", &a, ax, ay)
+
+		var r Point
+		sx, sy := curve.Add(ax, ay, bx, by)
+		checkPoint(t, "Add", r.Add(&a, &b), sx, sy)
+		dx, dy := curve.Double(ax, ay)
+		checkPoint(t, "Double", r.Double(&a), dx, dy)
+		checkPoint(t, "Negate", r.Negate(&a), ax, new(big.Int).Sub(curve.P, ay))
+		mx, my := curve.ScalarMult(bx, by, h1[:])
+		checkPoint(t, "ScalarMult", r.ScalarMult(&k1, &b), mx, my)
+
+		// a + b - b = a, with b in non-affine Jacobian coordinates.
+		if !r.Add(&a, &b).Sub(&r, &b).Equal(&a) {
+			t.Errorf("#%d: a + b - b != a", i)
+		}
+		if !r.Sub(&a, &a).IsIdentity() {
+			t.Errorf("#%d: a - a is not the identity", i)
+		}
+		if r.Add(&a, new(Point)); !r.Equal(&a) {
+			t.Errorf("#%d: a + 0 != a", i)
+		}
+		if !r.Add(&a, &a).Equal(new(Point).Double(&a)) {
+			t.Errorf("#%d: a + a != 2a", i)
+		}
+	}
+}
+
+func TestPointMultiScalarMult(t *testing.T) {
+	curve := S256()
+	xs, ys, ks := msmInputs(40)
+	points := make([]*Point, len(xs))
+	scalars := make([]*Scalar, len(xs))
+	for i := range xs {
+		var err error
+		if points[i], err = NewPoint(xs[i], ys[i]); err != nil {
+			t.Fatal(err)
+		}
+		scalars[i] = new(Scalar).SetBytes(ks[i])
+	}
+	// Mix in a point at infinity and a point with z != 1.
+	points[3] = new(Point)
+	points[5] = new(Point).Double(points[5])
+	xs[3], ys[3] = new(big.Int), new(big.Int)
+	xs[5], ys[5] = curve.Double(xs[5], ys[5])
+
+	wantX, wantY := curve.MultiScalarMult(xs, ys, ks)
+	checkPoint(t, "MultiScalarMult", new(Point).MultiScalarMult(scalars, points), wantX, wantY)
+}
+
+func TestPointEncoding(t *testing.T) {
+	curve := S256()
+	g := Generator()
+	checkPoint(t, "Generator", g, curve.Gx, curve.Gy)
+
+	var p Point
+	p.Double(g).Add(&p, g) // 3G in Jacobian coordinates
+	pub := &PublicKey{Curve: curve}
+	pub.X, pub.Y = curve.ScalarBaseMult([]byte{3})
+
+	if !bytes.Equal(p.SerializeCompressed(), pub.SerializeCompressed()) {
+		t.Errorf("SerializeCompressed: got %x, want %x", p.SerializeCompressed(), pub.SerializeCompressed())
+	}
+	if !bytes.Equal(p.SerializeUncompressed(), pub.SerializeUncompressed()) {
+		t.Errorf("SerializeUncompressed: got %x, want %x", p.SerializeUncompressed(), pub.SerializeUncompressed())
+	}
+	for _, enc := range [][]byte{p.SerializeCompressed(), p.SerializeUncompressed()} {
+		q, err := ParsePoint(enc)
+		if err != nil || !q.Equal(&p) {
+			t.Errorf("ParsePoint(%x): got %v, %v", enc, q, err)
+		}
+	}
+	if got, err := p.PubKey(); err != nil || got.X.Cmp(pub.X) != 0 || got.Y.Cmp(pub.Y) != 0 {
+		t.Errorf("PubKey: got %v, %v", got, err)
+	}
+
+	var inf Point
+	if !bytes.Equal(inf.SerializeCompressed(), []byte{0}) {
+		t.Errorf("identity encodes as %x", inf.SerializeCompressed())
+	}
+	if q, err := ParsePoint([]byte{0}); err != nil || !q.IsIdentity() {
+		t.Errorf("ParsePoint(00): got %v, %v", q, err)
+	}
+	if x, y := inf.Affine(); x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("identity affine coordinates (%x, %x)", x, y)
+	}
+	if _, err := inf.PubKey(); err != ErrPointAtInfinity {
+		t.Errorf("identity PubKey: got %v, want %v", err, ErrPointAtInfinity)
+	}
+	if _, err := NewPoint(big.NewInt(1), big.NewInt(1)); err != ErrPubKeyNotOnCurve {
+		t.Errorf("NewPoint off the curve: got %v, want %v", err, ErrPubKeyNotOnCurve)
+	}
+}
+
+func BenchmarkPointScalarMult(b *testing.B) {
+	var k Scalar
+	k.SetBytes(bytes.Repeat([]byte{0xab}, 32))
+	g := Generator()
+	var p Point
+	for i := 0; i < b.N; i++ {
+		p.ScalarMult(&k, g)
+	}
+}
+
+func BenchmarkPointScalarBaseMult(b *testing.B) {
+	var k Scalar
+	k.SetBytes(bytes.Repeat([]byte{0xab}, 32))
+	var p Point
+	for i := 0; i < b.N; i++ {
+		p.ScalarBaseMult(&k)
+	}
+}
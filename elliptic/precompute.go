@@ -1,63 +1,73 @@
 package elliptic
 
 import (
-	"compress/zlib"
-	"encoding/base64"
-	"encoding/binary"
-	"io/ioutil"
-	"strings"
+	_ "embed"
+	"errors"
+	"sync"
 )
 
-//go:generate go run -tags gensecp256k1 genprecomps.go
+//go:generate go run ../cmd/genprecomps -o secp256k1_bytepoints.bin
 
-// loadS256BytePoints decompresses and deserializes the pre-computed byte points
-// used to accelerate scalar base multiplication for the secp256k1 curve.  This
-// approach is used since it allows the compile to use significantly less ram
-// and be performed much faster than it is with hard-coding the final in-memory
-// data structure.  At the same time, it is quite fast to generate the in-memory
-// data structure at init time with this approach versus computing the table.
+// bytePointsSize is the size of the serialized byte points table: 32 8-bit
+// windows of 256 affine points with 32 byte x and y coordinates each.
+const bytePointsSize = 32 * 256 * 64
+
+// secp256k1BytePoints is the table produced by SerializedBytePoints, see
+// cmd/genprecomps.
+//
+//go:embed secp256k1_bytepoints.bin
+var secp256k1BytePoints []byte
+
+// bytePointsOnce guards the deserialization of secp256k1BytePoints.
+var bytePointsOnce sync.Once
+
+// loadS256BytePoints deserializes the pre-computed byte points used to
+// accelerate scalar base multiplication for the secp256k1 curve.  Storing the
+// table as a binary asset rather than Go source keeps compiles fast and
+// cheap, and decoding it is still much faster than computing the table.
 func loadS256BytePoints() error {
 	// There will be no byte points to load when generating them.
-	bp := secp256k1BytePoints
-	if len(bp) == 0 {
+	serialized := secp256k1BytePoints
+	if len(serialized) == 0 {
 		return nil
 	}
-
-	// Decompress the pre-computed table used to accelerate scalar base
-	// multiplication.
-	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(bp))
-	r, err := zlib.NewReader(decoder)
-	if err != nil {
-		return err
-	}
-	serialized, err := ioutil.ReadAll(r)
-	if err != nil {
-		return err
+	if len(serialized) != bytePointsSize {
+		return errors.New("malformed secp256k1 byte points table")
 	}
 
 	// Deserialize the precomputed byte points and set the curve to them.
+	// The points are stored in affine coordinates, so z is one except for
+	// the point at infinity, which is stored as (0, 0).
 	offset := 0
 	var bytePoints [32][256][3]fieldVal
+	var buf [32]byte
 	for byteNum := 0; byteNum < 32; byteNum++ {
 		// All points in this window.
 		for i := 0; i < 256; i++ {
-			px := &bytePoints[byteNum][i][0]
-			py := &bytePoints[byteNum][i][1]
-			pz := &bytePoints[byteNum][i][2]
-			for i := 0; i < 10; i++ {
-				px.n[i] = binary.LittleEndian.Uint32(serialized[offset:])
-				offset += 4
-			}
-			for i := 0; i < 10; i++ {
-				py.n[i] = binary.LittleEndian.Uint32(serialized[offset:])
-				offset += 4
-			}
-			for i := 0; i < 10; i++ {
-				pz.n[i] = binary.LittleEndian.Uint32(serialized[offset:])
-				offset += 4
+			p := &bytePoints[byteNum][i]
+			copy(buf[:], serialized[offset:])
+			p[0].SetBytes(&buf)
+			copy(buf[:], serialized[offset+32:])
+			p[1].SetBytes(&buf)
+			offset += 64
+			if !p[0].IsZero() || !p[1].IsZero() {
+				p[2].SetInt(1)
 			}
 		}
 	}
 	secp256k1.bytePoints = &bytePoints
 	return nil
 }
+
+// precomputedBytePoints returns the table of byte points, loading it first
+// if the curve was initialized without it (see lazyBytePoints).
+func (curve *KoblitzCurve) precomputedBytePoints() *[32][256][3]fieldVal {
+	bytePointsOnce.Do(func() {
+		// This is hard-coded data, so any errors are panics because it
+		// means something is wrong in the source code.
+		if err := loadS256BytePoints(); err != nil {
+			panic(err)
+		}
+	})
+	return curve.bytePoints
+}
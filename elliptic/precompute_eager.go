@@ -0,0 +1,10 @@
+//go:build !lazyprecomp
+
+package elliptic
+
+// lazyBytePoints reports whether loading the precomputed byte points is
+// deferred from the initialization of the curve to the first scalar base
+// multiplication.  Build with the lazyprecomp tag to defer it, which saves
+// programs that never multiply the base point the time and the memory of
+// the table.
+const lazyBytePoints = false
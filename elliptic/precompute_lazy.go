@@ -0,0 +1,8 @@
+//go:build lazyprecomp
+
+package elliptic
+
+// lazyBytePoints reports whether loading the precomputed byte points is
+// deferred from the initialization of the curve to the first scalar base
+// multiplication.
+const lazyBytePoints = true
@@ -0,0 +1,41 @@
+package elliptic
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBytePointsTable ensures the embedded table matches a fresh computation,
+// so a stale or corrupted secp256k1_bytepoints.bin is caught.  Regenerate it
+// with go generate if this fails after a deliberate change of the format.
+func TestBytePointsTable(t *testing.T) {
+	want := S256().SerializedBytePoints()
+	if len(secp256k1BytePoints) != len(want) {
+		t.Fatalf("table is %d bytes, want %d", len(secp256k1BytePoints), len(want))
+	}
+	if !bytes.Equal(secp256k1BytePoints, want) {
+		for i := range want {
+			if secp256k1BytePoints[i] != want[i] {
+				t.Fatalf("table differs from a fresh computation at window %d, point %d",
+					i/(256*64), i/64%256)
+			}
+		}
+	}
+
+	// The loaded table holds k*256^(31-i)*G for the byte k in window i.
+	curve := S256()
+	bytePoints := curve.precomputedBytePoints()
+	for _, w := range []int{0, 17, 31} {
+		for _, k := range []int{0, 1, 2, 0x80, 0xff} {
+			scalar := make([]byte, 32)
+			scalar[w] = byte(k)
+			wantX, wantY := curve.ScalarMult(curve.Gx, curve.Gy, scalar)
+			p := bytePoints[w][k]
+			gotX, gotY := curve.fieldJacobianToBigAffine(&p[0], &p[1], &p[2])
+			if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+				t.Errorf("window %d, point %d: got (%x, %x), want (%x, %x)",
+					w, k, gotX, gotY, wantX, wantY)
+			}
+		}
+	}
+}
@@ -3,6 +3,9 @@ package elliptic
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
 	"math/big"
 	"bytes"
 	// b38 "../bip38"
@@ -31,6 +34,102 @@ func PrivKeyFromBytes(curve elliptic.Curve, pk []byte) (*PrivateKey, *PublicKey)
 	return (*PrivateKey)(priv), (*PublicKey)(&priv.PublicKey)
 }
 
+// Errors returned by the validating private key constructors.
+var (
+	// ErrPrivKeyLength is returned for encodings that are not exactly as
+	// long as the curve order.
+	ErrPrivKeyLength = errors.New("private key has invalid length")
+
+	// ErrPrivKeyOutOfRange is returned for private keys that are zero or
+	// not less than the curve order.
+	ErrPrivKeyOutOfRange = errors.New("private key is not in the range [1, N-1]")
+
+	// ErrPrivKeyEntropy is returned by GenerateKey when the source of
+	// randomness keeps producing candidates outside of the valid range,
+	// which only happens with a broken source.
+	ErrPrivKeyEntropy = errors.New("random source produced no valid private key")
+)
+
+// generateKeyAttempts bounds the rejection sampling of GenerateKey.  A
+// uniform candidate is rejected with a probability of about 2^-128, so
+// running out of attempts means the source of randomness is broken.
+const generateKeyAttempts = 64
+
+// NewPrivateKey returns a new random secp256k1 private key read from
+// crypto/rand.
+func NewPrivateKey() (*PrivateKey, error) {
+	return GenerateKey(rand.Reader)
+}
+
+// GenerateKey returns a new random secp256k1 private key read from the
+// passed source of randomness.  Candidates of 32 bytes are drawn until one
+// is in the range [1, N-1], so the key is uniformly distributed instead of
+// biased by a modular reduction.
+func GenerateKey(r io.Reader) (*PrivateKey, error) {
+	var b [32]byte
+	defer zeroArray32(&b)
+	for i := 0; i < generateKeyAttempts; i++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		if s, err := ParseScalar(b[:]); err == nil && !s.IsZero() {
+			s.Zero()
+			priv, _ := PrivKeyFromBytes(S256(), b[:])
+			return priv, nil
+		}
+	}
+	return nil, ErrPrivKeyEntropy
+}
+
+// PrivKeyFromBytesStrict is like PrivKeyFromBytes, but rejects encodings
+// that are not exactly as long as the curve order and private keys that are
+// zero or not less than the curve order, which PrivKeyFromBytes silently
+// turns into an invalid or different key.
+func PrivKeyFromBytesStrict(curve elliptic.Curve, pk []byte) (*PrivateKey, *PublicKey, error) {
+	n := curve.Params().N
+	if len(pk) != (n.BitLen()+7)/8 {
+		return nil, nil, ErrPrivKeyLength
+	}
+	d := new(big.Int).SetBytes(pk)
+	defer ZeroBigInt(d)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, nil, ErrPrivKeyOutOfRange
+	}
+	priv, pub := PrivKeyFromBytes(curve, pk)
+	return priv, pub, nil
+}
+
+// Zero wipes the private scalar so that it does not linger in memory once
+// the key is no longer needed.  The key must not be used afterwards; the
+// public key is kept.
+func (p *PrivateKey) Zero() {
+	if p.D != nil {
+		ZeroBigInt(p.D)
+	}
+}
+
+// ZeroBigInt overwrites the words of b and sets it to zero, for wiping
+// secret scalars such as nonces once they are no longer needed.
+func ZeroBigInt(b *big.Int) {
+	words := b.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	b.SetInt64(0)
+}
+
+// zeroBytes overwrites b.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroArray32 overwrites a 32 byte array.
+func zeroArray32(b *[32]byte) {
+	*b = [32]byte{}
+}
+
 func (p *PrivateKey) PrivatekeyToBytes() ([] byte){
 	pri_bytes := p.D.Bytes()
 	padding_pri_bytes := append(bytes.Repeat([]byte{0x00}, 32-len(pri_bytes)), pri_bytes...)
@@ -40,6 +139,7 @@ func (p *PrivateKey) PrivatekeyToBytes() ([] byte){
 //convert private key to wallet import format string
 func (p *PrivateKey) ToWIF() (wif string){
 	pri_bytes := p.PrivatekeyToBytes()
+	defer zeroBytes(pri_bytes)
 	wif = base58CheckEncode(WIF_VERSION, pri_bytes)
 	return wif
 }
@@ -49,6 +149,7 @@ func (p *PrivateKey) ToWIFCompressed() (wif string){
 	pri_bytes := p.PrivatekeyToBytes()
 	// to tell wallet use compressed public keys
 	pri_bytes = append(pri_bytes, []byte{WIF_COMPRESSED_FLAG}...)
+	defer zeroBytes(pri_bytes)
 	wif = base58CheckEncode(WIF_VERSION, pri_bytes)
 	return wif
 }
@@ -56,10 +157,12 @@ func (p *PrivateKey) ToWIFCompressed() (wif string){
 func (p *PrivateKey) ToBip38Encrypt(passphrase string) string{
 	bip38 := new(b38.BIP38Key)
 	priv_bytes := p.PrivatekeyToBytes()
+	defer zeroBytes(priv_bytes)
 	pub_key := (*PublicKey)(&p.PublicKey)
 	pub_bytes := pub_key.SerializeUncompressed()
 	ah := b38.DoubleHash256(pub_bytes)[:4]
 	dh, _ := scrypt.Key([]byte(passphrase), ah, 16384, 8, 8, 64)
+	defer zeroBytes(dh)
 
 	bip38.Flag = byte(0xC0)
 	copy(bip38.Hash[:], ah)
@@ -67,18 +170,64 @@ func (p *PrivateKey) ToBip38Encrypt(passphrase string) string{
 	return bip38.String()
 }
 
-func  Bip38Decrypt(bipstr string, passphrase string) (string) {
+// Errors returned by Bip38DecryptBytes.
+var (
+	// ErrBip38Format is returned for strings that are not BIP38 encrypted
+	// keys as produced by ToBip38Encrypt.
+	ErrBip38Format = errors.New("bip38: malformed encrypted key")
+
+	// ErrBip38Passphrase is returned when the decrypted key does not match
+	// the public key hash stored with it, which means the passphrase is
+	// wrong.
+	ErrBip38Passphrase = errors.New("bip38: wrong passphrase")
+)
+
+// Bip38DecryptBytes decrypts a key encrypted by ToBip38Encrypt and returns
+// the 32 private key bytes.  The key derived from the passphrase is wiped
+// before returning, and the caller should wipe the result once done with it,
+// which is not possible with the string returned by Bip38Decrypt.
+func Bip38DecryptBytes(bipstr string, passphrase string) ([]byte, error) {
 	b, err := b58.B58decode(bipstr)
 	if err != nil {
-		// return nil, err
+		return nil, err
+	}
+	if len(b) != 39 || b[0] != 0x01 || b[1] != 0x42 {
+		return nil, ErrBip38Format
 	}
 	bip38 := new(b38.BIP38Key)
 	bip38.Flag = b[2]
 	copy(bip38.Hash[:], b[3:7])
 	copy(bip38.Data[:], b[7:])
 
-	dh, _ := scrypt.Key([]byte(passphrase), bip38.Hash[:], 16384, 8, 8, 64)
+	dh, err := scrypt.Key([]byte(passphrase), bip38.Hash[:], 16384, 8, 8, 64)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(dh)
 	p := b38.Decrypt(bip38.Data[:], dh[:32], dh[32:])
+
+	// Check the passphrase by comparing the hash of the public key.
+	priv, pub := PrivKeyFromBytes(S256(), p)
+	priv.Zero()
+	ah := b38.DoubleHash256(pub.SerializeUncompressed())[:4]
+	if !bytes.Equal(ah, bip38.Hash[:]) {
+		zeroBytes(p)
+		return nil, ErrBip38Passphrase
+	}
+	return p, nil
+}
+
+// Bip38Decrypt is like Bip38DecryptBytes, but returns the private key as an
+// upper case hex string, or an empty string if decryption fails.
+//
+// Deprecated: the returned string cannot be wiped from memory; use
+// Bip38DecryptBytes.
+func  Bip38Decrypt(bipstr string, passphrase string) (string) {
+	p, err := Bip38DecryptBytes(bipstr, passphrase)
+	if err != nil {
+		return ""
+	}
+	defer zeroBytes(p)
 	priv_key_str := byteToString(p)
 	return priv_key_str
 }
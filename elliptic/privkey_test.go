@@ -0,0 +1,124 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	priv, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.D.Sign() <= 0 || priv.D.Cmp(S256().N) >= 0 {
+		t.Fatalf("key %x out of range", priv.D)
+	}
+	x, y := S256().ScalarBaseMult(priv.PrivatekeyToBytes())
+	if x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+		t.Fatal("public key does not match the private key")
+	}
+
+	// Out of range candidates are rejected and the next one is used.
+	n := S256().N.Bytes()
+	want := bytes.Repeat([]byte{0x42}, 32)
+	stream := append(append(append(make([]byte, 32), n...), bytes.Repeat([]byte{0xff}, 32)...), want...)
+	priv, err = GenerateKey(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.PrivatekeyToBytes(), want) {
+		t.Fatalf("got %x, want %x", priv.PrivatekeyToBytes(), want)
+	}
+
+	// A source that never produces a valid key fails instead of looping.
+	if _, err := GenerateKey(bytes.NewReader(make([]byte, 32*generateKeyAttempts))); err != ErrPrivKeyEntropy {
+		t.Errorf("all-zero source: got %v, want %v", err, ErrPrivKeyEntropy)
+	}
+	if _, err := GenerateKey(bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("short source: expected an error")
+	}
+}
+
+func TestPrivKeyFromBytesStrict(t *testing.T) {
+	curve := S256()
+	nMinus1 := new(big.Int).Sub(curve.N, big.NewInt(1)).Bytes()
+	tests := []struct {
+		name string
+		key  []byte
+		err  error
+	}{
+		{"one", append(make([]byte, 31), 1), nil},
+		{"N-1", nMinus1, nil},
+		{"zero", make([]byte, 32), ErrPrivKeyOutOfRange},
+		{"N", curve.N.Bytes(), ErrPrivKeyOutOfRange},
+		{"all ones", bytes.Repeat([]byte{0xff}, 32), ErrPrivKeyOutOfRange},
+		{"short", []byte{1}, ErrPrivKeyLength},
+		{"long", append([]byte{0}, nMinus1...), ErrPrivKeyLength},
+		{"empty", nil, ErrPrivKeyLength},
+	}
+	for _, test := range tests {
+		priv, pub, err := PrivKeyFromBytesStrict(curve, test.key)
+		if err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		wantPriv, wantPub := PrivKeyFromBytes(curve, test.key)
+		if priv.D.Cmp(wantPriv.D) != 0 || pub.X.Cmp(wantPub.X) != 0 || pub.Y.Cmp(wantPub.Y) != 0 {
+			t.Errorf("%s: key differs from PrivKeyFromBytes", test.name)
+		}
+	}
+}
+
+func TestPrivateKeyZero(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := priv.D.Bits()
+	priv.Zero()
+	if priv.D.Sign() != 0 {
+		t.Errorf("D is %x after Zero", priv.D)
+	}
+	for i, w := range words[:cap(words)] {
+		if w != 0 {
+			t.Errorf("word %d of D was not wiped", i)
+		}
+	}
+	if priv.X == nil || priv.Y == nil {
+		t.Error("Zero dropped the public key")
+	}
+}
+
+func TestBip38DecryptBytes(t *testing.T) {
+	priv, _, err := PrivKeyFromBytesStrict(S256(), hexToBytes("cbf4b9f70470856bb4f40f80b87edb90865997ffee6df315ab166d713af433a5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := priv.ToBip38Encrypt("TestingOneTwoThree")
+
+	got, err := Bip38DecryptBytes(encrypted, "TestingOneTwoThree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, priv.PrivatekeyToBytes()) {
+		t.Errorf("got %x, want %x", got, priv.PrivatekeyToBytes())
+	}
+	if s := Bip38Decrypt(encrypted, "TestingOneTwoThree"); s != "CBF4B9F70470856BB4F40F80B87EDB90865997FFEE6DF315AB166D713AF433A5" {
+		t.Errorf("Bip38Decrypt: got %s", s)
+	}
+
+	if _, err := Bip38DecryptBytes(encrypted, "wrong"); err != ErrBip38Passphrase {
+		t.Errorf("wrong passphrase: got %v, want %v", err, ErrBip38Passphrase)
+	}
+	if s := Bip38Decrypt(encrypted, "wrong"); s != "" {
+		t.Errorf("Bip38Decrypt with a wrong passphrase: got %s", s)
+	}
+	if _, err := Bip38DecryptBytes(priv.ToWIF(), "TestingOneTwoThree"); err != ErrBip38Format {
+		t.Errorf("WIF: got %v, want %v", err, ErrBip38Format)
+	}
+}
@@ -9,12 +9,15 @@ import "golang.org/x/crypto/ripemd160"
 import b58 "github.com/symphonyprotocol/sutil/base58"
 import "log"
 import "bytes"
+import "errors"
 
 
+const LenPubKeyBytesXOnly        = 32
 const LenPubKeyBytesCompressed   = 33
 const LenPubKeyBytesUnCompressed = 65
 const PubkeyCompressed   byte = 0x2
 const PubkeyUncompressed byte = 0x4
+const PubkeyHybrid       byte = 0x6
 const WALLET_ADDRESS_FLAG = 0x00
 const CHECKSUM_LEN = 4
 
@@ -41,6 +44,11 @@ func (p *PublicKey) SerializeCompressed() []byte {
 	return paddedAppend(32, b, p.X.Bytes())
 }
 
+// SerializeXOnly 序列化为 BIP340 的 32 字节 x-only 公钥, 只保留 X
+func (p *PublicKey) SerializeXOnly() []byte {
+	return paddedAppend(32, make([]byte, 0, LenPubKeyBytesXOnly), p.X.Bytes())
+}
+
 func isOdd(a *big.Int) bool {
 	return a.Bit(0) == 1
 }
@@ -52,13 +60,59 @@ func paddedAppend(size uint, dst, src []byte) []byte {
 	return append(dst, src...)
 }
 
+// Errors returned when parsing a serialized public key.  Every malformed input
+// is reported with one of these so callers can tell the cases apart.
+var (
+	ErrPubKeyEmpty             = errors.New("pubkey string is empty")
+	ErrPubKeyInvalidLength     = errors.New("pubkey has invalid length")
+	ErrPubKeyInvalidFormat     = errors.New("pubkey has invalid format flag")
+	ErrPubKeyHybrid            = errors.New("hybrid pubkey format is not allowed")
+	ErrPubKeyMismatchedOddness = errors.New("hybrid pubkey y parity doesn't match format flag")
+	ErrPubKeyXTooBig           = errors.New("pubkey X parameter is >= to P")
+	ErrPubKeyYTooBig           = errors.New("pubkey Y parameter is >= to P")
+	ErrPubKeyNotOnCurve        = errors.New("pubkey isn't on secp256k1 curve")
+)
+
 // 把public key 字节数组转化为 publickey ， 包含曲线，X， Y
+// 支持 33 字节压缩格式 (0x02/0x03) 和 65 字节非压缩格式 (0x04)
 func ParsePubKey(pubKeyBytes []byte,  curve *KoblitzCurve ) (key *PublicKey, err error) {
+	return parsePubKey(pubKeyBytes, curve, false)
+}
+
+// ParsePubKeyAllowHybrid is like ParsePubKey but also accepts the 65 byte
+// hybrid format (0x06/0x07) from X9.62, in which the low bit of the format
+// flag must match the parity of Y.
+func ParsePubKeyAllowHybrid(pubKeyBytes []byte, curve *KoblitzCurve) (*PublicKey, error) {
+	return parsePubKey(pubKeyBytes, curve, true)
+}
+
+// ParseXOnlyPubKey parses a 32 byte x-only public key as defined by BIP340:
+// the X coordinate of the point whose Y coordinate is even.
+func ParseXOnlyPubKey(pubKeyBytes []byte) (*PublicKey, error) {
+	if len(pubKeyBytes) == 0 {
+		return nil, ErrPubKeyEmpty
+	}
+	if len(pubKeyBytes) != LenPubKeyBytesXOnly {
+		return nil, ErrPubKeyInvalidLength
+	}
+	curve := S256()
+	x := new(big.Int).SetBytes(pubKeyBytes)
+	if x.Cmp(curve.P) >= 0 {
+		return nil, ErrPubKeyXTooBig
+	}
+	y, err := decompressPoint(curve, x, false)
+	if err != nil {
+		return nil, ErrPubKeyNotOnCurve
+	}
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func parsePubKey(pubKeyBytes []byte, curve *KoblitzCurve, allowHybrid bool) (*PublicKey, error) {
 	pubkey := PublicKey{}
 	pubkey.Curve = curve
 
 	if len(pubKeyBytes) == 0 {
-		return nil, fmt.Errorf("pubkey string is empty")
+		return nil, ErrPubKeyEmpty
 	}
 
 	format := pubKeyBytes[0]
@@ -70,37 +124,53 @@ func ParsePubKey(pubKeyBytes []byte,  curve *KoblitzCurve ) (key *PublicKey, err
 	format &= ^byte(0x1)
 
 
-	pubkeyLen := len(pubKeyBytes)
-
-	if pubkeyLen == LenPubKeyBytesCompressed{
+	switch len(pubKeyBytes) {
+	case LenPubKeyBytesCompressed:
 		// y^2 = x^3 + Curve.B
-		if format != PubkeyCompressed{
-			return nil, fmt.Errorf("invalid compressed format flag: %d", pubKeyBytes[0])
+		if format != PubkeyCompressed {
+			return nil, ErrPubKeyInvalidFormat
 		}
 		pubkey.X = new(big.Int).SetBytes(pubKeyBytes[1:33])
-		pubkey.Y, err = decompressPoint(curve, pubkey.X, ybit)
+		if pubkey.X.Cmp(curve.Params().P) >= 0 {
+			return nil, ErrPubKeyXTooBig
+		}
+		y, err := decompressPoint(curve, pubkey.X, ybit)
 		if err != nil {
-			return nil, err
+			return nil, ErrPubKeyNotOnCurve
 		}
-
-	}else if pubkeyLen == LenPubKeyBytesUnCompressed{
-		if format != PubkeyUncompressed{
-			return nil, fmt.Errorf("invalid compressed format flag: %d", pubKeyBytes[0])
+		pubkey.Y = y
+
+	case LenPubKeyBytesUnCompressed:
+		switch {
+		case pubKeyBytes[0] == PubkeyUncompressed:
+		case format == PubkeyHybrid:
+			if !allowHybrid {
+				return nil, ErrPubKeyHybrid
+			}
+		default:
+			return nil, ErrPubKeyInvalidFormat
 		}
 		pubkey.X = new(big.Int).SetBytes(pubKeyBytes[1:33])
 		pubkey.Y = new(big.Int).SetBytes(pubKeyBytes[33:])
+		// 混合格式的标识位必须和 Y 的奇偶性一致
+		if format == PubkeyHybrid && ybit != isOdd(pubkey.Y) {
+			return nil, ErrPubKeyMismatchedOddness
+		}
+
+	default:
+		return nil, ErrPubKeyInvalidLength
 	}
 
 	// 保证X， Y 在曲线范围内
 	if pubkey.X.Cmp(pubkey.Curve.Params().P) >= 0 {
-		return nil, fmt.Errorf("pubkey X parameter is >= to P")
+		return nil, ErrPubKeyXTooBig
 	}
 	if pubkey.Y.Cmp(pubkey.Curve.Params().P) >= 0 {
-		return nil, fmt.Errorf("pubkey Y parameter is >= to P")
+		return nil, ErrPubKeyYTooBig
 	}
 	// 保证点在曲线上
 	if !pubkey.Curve.IsOnCurve(pubkey.X, pubkey.Y) {
-		return nil, fmt.Errorf("pubkey isn't on secp256k1 curve")
+		return nil, ErrPubKeyNotOnCurve
 	}
 
 	return &pubkey, nil
@@ -0,0 +1,126 @@
+package elliptic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+const (
+	genX = "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	genY = "483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	// fieldP is the field prime, which is never a valid coordinate.
+	fieldP = "fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"
+)
+
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in test source: " + s)
+	}
+	return b
+}
+
+var pubKeyTests = []struct {
+	name        string
+	key         string
+	allowHybrid bool
+	err         error
+}{
+	{"uncompressed", "04" + genX + genY, false, nil},
+	{"compressed", "02" + genX, false, nil},
+	{"hybrid", "06" + genX + genY, true, nil},
+	{"hybrid not allowed", "06" + genX + genY, false, ErrPubKeyHybrid},
+	{"hybrid wrong parity", "07" + genX + genY, true, ErrPubKeyMismatchedOddness},
+	{"empty", "", false, ErrPubKeyEmpty},
+	{"short", "02" + genX[:62], false, ErrPubKeyInvalidLength},
+	{"long", "04" + genX + genY + "00", false, ErrPubKeyInvalidLength},
+	{"x-only passed to ParsePubKey", genX, false, ErrPubKeyInvalidLength},
+	{"uncompressed with 0x05", "05" + genX + genY, false, ErrPubKeyInvalidFormat},
+	{"compressed with 0x04", "04" + genX, false, ErrPubKeyInvalidFormat},
+	{"uncompressed with 0x02", "02" + genX + genY, false, ErrPubKeyInvalidFormat},
+	{"compressed X >= P", "02" + fieldP, false, ErrPubKeyXTooBig},
+	{"uncompressed X >= P", "04" + fieldP + genY, false, ErrPubKeyXTooBig},
+	{"uncompressed Y >= P", "04" + genX + fieldP, false, ErrPubKeyYTooBig},
+	{"compressed not on curve", "02" + genX[:62] + "00", false, ErrPubKeyNotOnCurve},
+	{"uncompressed not on curve", "04" + genX + genY[:62] + "00", false, ErrPubKeyNotOnCurve},
+}
+
+func TestParsePubKey(t *testing.T) {
+	for _, test := range pubKeyTests {
+		var err error
+		var pk *PublicKey
+		if test.allowHybrid {
+			pk, err = ParsePubKeyAllowHybrid(hexToBytes(test.key), S256())
+		} else {
+			pk, err = ParsePubKey(hexToBytes(test.key), S256())
+		}
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if pk.X.Cmp(S256().Gx) != 0 || pk.Y.Cmp(S256().Gy) != 0 {
+			t.Errorf("%s: parsed the wrong point", test.name)
+		}
+	}
+}
+
+func TestParseXOnlyPubKey(t *testing.T) {
+	pk, err := ParseXOnlyPubKey(hexToBytes(genX))
+	if err != nil {
+		t.Fatalf("ParseXOnlyPubKey: %v", err)
+	}
+	if pk.X.Cmp(S256().Gx) != 0 || pk.Y.Cmp(S256().Gy) != 0 {
+		t.Fatalf("parsed the wrong point")
+	}
+	if !bytes.Equal(pk.SerializeXOnly(), hexToBytes(genX)) {
+		t.Fatalf("SerializeXOnly doesn't round trip")
+	}
+
+	tests := []struct {
+		key string
+		err error
+	}{
+		{"", ErrPubKeyEmpty},
+		{"02" + genX, ErrPubKeyInvalidLength},
+		{fieldP, ErrPubKeyXTooBig},
+		// x = 5 has no square root for x^3 + 7.
+		{"0000000000000000000000000000000000000000000000000000000000000005", ErrPubKeyNotOnCurve},
+	}
+	for _, test := range tests {
+		if _, err := ParseXOnlyPubKey(hexToBytes(test.key)); err != test.err {
+			t.Errorf("ParseXOnlyPubKey(%s): got %v, want %v", test.key, err, test.err)
+		}
+	}
+}
+
+func FuzzParsePubKey(f *testing.F) {
+	for _, test := range pubKeyTests {
+		f.Add(hexToBytes(test.key))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, parse := range []func([]byte, *KoblitzCurve) (*PublicKey, error){
+			ParsePubKey, ParsePubKeyAllowHybrid,
+		} {
+			pk, err := parse(data, S256())
+			if err != nil {
+				continue
+			}
+			// Anything that parses must be on the curve and round trip
+			// through the compressed encoding.
+			if !S256().IsOnCurve(pk.X, pk.Y) {
+				t.Fatalf("parsed point %x is not on the curve", data)
+			}
+			again, err := ParsePubKey(pk.SerializeCompressed(), S256())
+			if err != nil || again.X.Cmp(pk.X) != 0 || again.Y.Cmp(pk.Y) != 0 {
+				t.Fatalf("%x doesn't round trip: %v", data, err)
+			}
+		}
+		if pk, err := ParseXOnlyPubKey(data); err == nil && isOdd(pk.Y) {
+			t.Fatalf("x-only key %x parsed with odd Y", data)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package elliptic
+
+import (
+	"errors"
+	"math/big"
+)
+
+// LenEcrecoverSignature is the length of the [R || S || V] signatures taken by
+// Ecrecover.
+const LenEcrecoverSignature = 65
+
+// RecoveredPublicKey is a public key that was recovered from a signature
+// together with the recovery id that selects it among the candidates.
+type RecoveredPublicKey struct {
+	PubKey *PublicKey
+
+	// RecoveryID is the iteration of the recovery algorithm that produced
+	// PubKey.  Bit 0 is the parity of the Y coordinate of R and bit 1 is set
+	// when the X coordinate of R is at least N.  It is the value encoded in
+	// the header byte of SignCompact and the V value of Ecrecover.
+	RecoveryID int
+}
+
+// RecoverPublicKeys returns every public key for which sig is a valid
+// signature of hash, along with the recovery id of each.  There are at most
+// four candidates and in practice almost always exactly two.
+func RecoverPublicKeys(sig *Signature, hash []byte) ([]RecoveredPublicKey, error) {
+	curve := S256()
+	if !sigInRange(sig) {
+		return nil, errors.New("signature R or S is out of range")
+	}
+
+	var keys []RecoveredPublicKey
+	for i := 0; i < (curve.H+1)*2; i++ {
+		pk, err := recoverKeyFromSignature(curve, sig, hash, i, true)
+		if err != nil || !isValidRecoveredKey(pk) {
+			continue
+		}
+		keys = append(keys, RecoveredPublicKey{PubKey: pk, RecoveryID: i})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no public key can be recovered from signature")
+	}
+	return keys, nil
+}
+
+// SigToPub recovers the public key that produced the Ethereum style signature
+// sig, which is 65 bytes of [R || S || V], over hash.  V may either be the raw
+// recovery id (0-3) or the id offset by 27 as produced by most Ethereum
+// tooling.
+func SigToPub(hash, sig []byte) (*PublicKey, error) {
+	if len(sig) != LenEcrecoverSignature {
+		return nil, errors.New("invalid signature length, expected 65 bytes")
+	}
+	v := int(sig[64])
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 3 {
+		return nil, errors.New("invalid signature recovery id")
+	}
+
+	s := &Signature{
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	}
+	if !sigInRange(s) {
+		return nil, errors.New("signature R or S is out of range")
+	}
+
+	pk, err := recoverKeyFromSignature(S256(), s, hash, v, false)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidRecoveredKey(pk) {
+		return nil, errors.New("recovered public key is invalid")
+	}
+	return pk, nil
+}
+
+// Ecrecover is the equivalent of the EVM ecrecover precompile and of
+// go-ethereum's crypto.Ecrecover: it returns the 65 byte uncompressed public
+// key that produced the [R || S || V] signature sig over hash.
+func Ecrecover(hash, sig []byte) ([]byte, error) {
+	pk, err := SigToPub(hash, sig)
+	if err != nil {
+		return nil, err
+	}
+	return pk.SerializeUncompressed(), nil
+}
+
+// sigInRange returns whether R and S are both in [1, N-1].
+func sigInRange(sig *Signature) bool {
+	n := S256().N
+	return sig.R.Sign() > 0 && sig.R.Cmp(n) < 0 &&
+		sig.S.Sign() > 0 && sig.S.Cmp(n) < 0
+}
+
+// isValidRecoveredKey rejects the point at infinity, which the recovery
+// algorithm yields for some malicious signatures.
+func isValidRecoveredKey(pk *PublicKey) bool {
+	return !(pk.X.Sign() == 0 && pk.Y.Sign() == 0) && pk.Curve.IsOnCurve(pk.X, pk.Y)
+}
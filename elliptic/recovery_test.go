@@ -0,0 +1,77 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestEcrecover(t *testing.T) {
+	// Test vector from go-ethereum's crypto package.
+	hash := hexToBytes("ce0677bb30baa8cf067c88db9811f4333d131bf8bcf12fe7065d211dce971008")
+	sig := hexToBytes("90f27b8b488db00b00606796d2987f6a5f59ae62ea05effe84fef5b8b0e549984a691139ad57a3f0b906637673aa2f63d1f55cb1a69199d4009eea23ceaddc9301")
+	want := hexToBytes("04e32df42865e97135acfb65f3bae71bdc86f4d49150ad6a440b6f15878109880a0a2b2667f7e725ceea70c673093bf67663e0312623c8e091b13cf2c0f11ef652")
+
+	pub, err := Ecrecover(hash, sig)
+	if err != nil {
+		t.Fatalf("Ecrecover: %v", err)
+	}
+	if !bytes.Equal(pub, want) {
+		t.Fatalf("Ecrecover: got %x, want %x", pub, want)
+	}
+
+	// V offset by 27 recovers the same key.
+	sig27 := append(append([]byte{}, sig[:64]...), sig[64]+27)
+	if pub, err := Ecrecover(hash, sig27); err != nil || !bytes.Equal(pub, want) {
+		t.Errorf("Ecrecover with V+27: got %x, %v", pub, err)
+	}
+
+	bad := [][]byte{
+		sig[:64],
+		append(append([]byte{}, sig[:64]...), 4),
+		append(make([]byte, 32), sig[32:]...),
+	}
+	for i, b := range bad {
+		if _, err := Ecrecover(hash, b); err == nil {
+			t.Errorf("bad signature #%d: expected error", i)
+		}
+	}
+}
+
+func TestRecoverPublicKeys(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		seed := sha256.Sum256([]byte{byte(i)})
+		priv, pub := PrivKeyFromBytes(S256(), seed[:])
+		hash := sha256.Sum256(seed[:])
+
+		sig, err := priv.Sign(hash[:])
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		compact, err := SignCompact(S256(), priv, hash[:], false)
+		if err != nil {
+			t.Fatalf("SignCompact: %v", err)
+		}
+
+		keys, err := RecoverPublicKeys(sig, hash[:])
+		if err != nil {
+			t.Fatalf("RecoverPublicKeys: %v", err)
+		}
+		found := false
+		for _, key := range keys {
+			if !sig.Verify(hash[:], key.PubKey) {
+				t.Errorf("candidate %d doesn't verify", key.RecoveryID)
+			}
+			if key.PubKey.X.Cmp(pub.X) == 0 && key.PubKey.Y.Cmp(pub.Y) == 0 {
+				found = true
+				if int(compact[0]-27) != key.RecoveryID {
+					t.Errorf("recovery id %d doesn't match compact header %d",
+						key.RecoveryID, compact[0])
+				}
+			}
+		}
+		if !found {
+			t.Errorf("signing key is not among the candidates")
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package elliptic
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+// ErrScalarOutOfRange is returned by ParseScalar for encodings that are not
+// 32 bytes long or not less than the group order.
+var ErrScalarOutOfRange = errors.New("scalar is not less than the group order")
+
+// Scalar is an integer modulo the group order N.  The zero value is zero and
+// is ready to use.  Like big.Int, the arithmetic methods set the receiver to
+// the result and return it, and operands may alias the receiver:
+//
+//	var s Scalar
+//	s.Mul(&a, &b).Add(&s, &c) // s = a*b + c
+//
+// Unlike big.Int, a Scalar is a fixed size value that can be copied freely.
+type Scalar struct {
+	// n holds the value in little endian 64-bit limbs and is always
+	// fully reduced.
+	n [4]uint64
+}
+
+// The group order, 2^256 - N and (N-1)/2 in little endian limbs.
+var (
+	scalarN         = [4]uint64{0xbfd25e8cd0364141, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+	scalarNC        = [4]uint64{0x402da1732fc9bebf, 0x4551231950b75fc4, 0x1, 0x0}
+	scalarHalfOrder = [4]uint64{0xdfe92f46681b20a0, 0x5d576e7357a4501d, 0xffffffffffffffff, 0x7fffffffffffffff}
+)
+
+// ParseScalar parses a 32 byte big endian scalar, rejecting values that are
+// not less than N instead of reducing them.
+func ParseScalar(b []byte) (*Scalar, error) {
+	if len(b) != 32 {
+		return nil, ErrScalarOutOfRange
+	}
+	s := new(Scalar)
+	s.setBytes32(b)
+	if !s.less(&scalarN) {
+		return nil, ErrScalarOutOfRange
+	}
+	return s, nil
+}
+
+// Set sets s = a.
+func (s *Scalar) Set(a *Scalar) *Scalar {
+	*s = *a
+	return s
+}
+
+// SetInt sets s = v.
+func (s *Scalar) SetInt(v uint64) *Scalar {
+	s.n = [4]uint64{v, 0, 0, 0}
+	return s
+}
+
+// SetBytes sets s to the big endian integer b reduced modulo N.
+func (s *Scalar) SetBytes(b []byte) *Scalar {
+	if len(b) > 32 {
+		return s.SetBigInt(new(big.Int).SetBytes(b))
+	}
+	var padded [32]byte
+	copy(padded[32-len(b):], b)
+	s.setBytes32(padded[:])
+	s.reduceOnce(0)
+	return s
+}
+
+// SetBigInt sets s = v mod N.  v may be negative or larger than N.
+func (s *Scalar) SetBigInt(v *big.Int) *Scalar {
+	r := new(big.Int).Mod(v, S256().N)
+	var b [32]byte
+	r.FillBytes(b[:])
+	s.setBytes32(b[:])
+	return s
+}
+
+// setBytes32 loads 32 big endian bytes without reducing them.
+func (s *Scalar) setBytes32(b []byte) {
+	for i := 0; i < 4; i++ {
+		var limb uint64
+		for _, c := range b[24-8*i : 32-8*i] {
+			limb = limb<<8 | uint64(c)
+		}
+		s.n[i] = limb
+	}
+}
+
+// Bytes returns the 32 byte big endian encoding of s.
+func (s *Scalar) Bytes() [32]byte {
+	var b [32]byte
+	for i := 0; i < 4; i++ {
+		limb := s.n[i]
+		for j := 31 - 8*i; j >= 24-8*i; j-- {
+			b[j] = byte(limb)
+			limb >>= 8
+		}
+	}
+	return b
+}
+
+// BigInt returns s as a big.Int.
+func (s *Scalar) BigInt() *big.Int {
+	b := s.Bytes()
+	return new(big.Int).SetBytes(b[:])
+}
+
+// IsZero reports whether s is zero.
+func (s *Scalar) IsZero() bool {
+	return s.n[0]|s.n[1]|s.n[2]|s.n[3] == 0
+}
+
+// Equal reports whether s and a are the same scalar.
+func (s *Scalar) Equal(a *Scalar) bool {
+	return s.n == a.n
+}
+
+// IsOverHalfOrder reports whether s > (N-1)/2, i.e. whether s is the high
+// value of an ECDSA S that low-S normalization would negate.
+func (s *Scalar) IsOverHalfOrder() bool {
+	return scalarHalfOrder != s.n && !s.less(&scalarHalfOrder)
+}
+
+// Zero sets s to zero, wiping the value it held.
+func (s *Scalar) Zero() {
+	s.n = [4]uint64{}
+}
+
+// Add sets s = a + b mod N.
+func (s *Scalar) Add(a, b *Scalar) *Scalar {
+	var carry uint64
+	s.n[0], carry = bits.Add64(a.n[0], b.n[0], 0)
+	s.n[1], carry = bits.Add64(a.n[1], b.n[1], carry)
+	s.n[2], carry = bits.Add64(a.n[2], b.n[2], carry)
+	s.n[3], carry = bits.Add64(a.n[3], b.n[3], carry)
+	s.reduceOnce(carry)
+	return s
+}
+
+// Sub sets s = a - b mod N.
+func (s *Scalar) Sub(a, b *Scalar) *Scalar {
+	var negB Scalar
+	negB.Negate(b)
+	return s.Add(a, &negB)
+}
+
+// Negate sets s = -a mod N.
+func (s *Scalar) Negate(a *Scalar) *Scalar {
+	if a.IsZero() {
+		s.n = [4]uint64{}
+		return s
+	}
+	var borrow uint64
+	s.n[0], borrow = bits.Sub64(scalarN[0], a.n[0], 0)
+	s.n[1], borrow = bits.Sub64(scalarN[1], a.n[1], borrow)
+	s.n[2], borrow = bits.Sub64(scalarN[2], a.n[2], borrow)
+	s.n[3], _ = bits.Sub64(scalarN[3], a.n[3], borrow)
+	return s
+}
+
+// Mul sets s = a * b mod N.
+func (s *Scalar) Mul(a, b *Scalar) *Scalar {
+	var w [8]uint64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			mulAddAt(&w, i+j, a.n[i], b.n[j])
+		}
+	}
+	s.reduceWide(&w)
+	return s
+}
+
+// Square sets s = a^2 mod N.
+func (s *Scalar) Square(a *Scalar) *Scalar {
+	return s.Mul(a, a)
+}
+
+// Inverse sets s = a^-1 mod N, or zero if a is zero, computed as a^(N-2)
+// by Fermat's little theorem.
+func (s *Scalar) Inverse(a *Scalar) *Scalar {
+	exp := scalarN
+	exp[0] -= 2
+
+	var r Scalar
+	r.SetInt(1)
+	base := *a
+	for i := 3; i >= 0; i-- {
+		for j := 63; j >= 0; j-- {
+			r.Square(&r)
+			if exp[i]>>uint(j)&1 == 1 {
+				r.Mul(&r, &base)
+			}
+		}
+	}
+	*s = r
+	return s
+}
+
+// less reports whether s < v as 256-bit integers.
+func (s *Scalar) less(v *[4]uint64) bool {
+	_, borrow := bits.Sub64(s.n[0], v[0], 0)
+	_, borrow = bits.Sub64(s.n[1], v[1], borrow)
+	_, borrow = bits.Sub64(s.n[2], v[2], borrow)
+	_, borrow = bits.Sub64(s.n[3], v[3], borrow)
+	return borrow == 1
+}
+
+// reduceOnce subtracts N from carry*2^256 + s if that is at least N, which
+// fully reduces any value less than 2N.
+func (s *Scalar) reduceOnce(carry uint64) {
+	if carry == 0 && s.less(&scalarN) {
+		return
+	}
+	var borrow uint64
+	s.n[0], borrow = bits.Sub64(s.n[0], scalarN[0], 0)
+	s.n[1], borrow = bits.Sub64(s.n[1], scalarN[1], borrow)
+	s.n[2], borrow = bits.Sub64(s.n[2], scalarN[2], borrow)
+	s.n[3], _ = bits.Sub64(s.n[3], scalarN[3], borrow)
+}
+
+// reduceWide sets s to the 512-bit value w reduced modulo N.  Since
+// 2^256 = NC (mod N) with NC about 2^128, w = lo + hi*2^256 reduces to
+// lo + hi*NC, which is about 128 bits shorter, until the high half is
+// zero.
+func (s *Scalar) reduceWide(w *[8]uint64) {
+	for w[4]|w[5]|w[6]|w[7] != 0 {
+		var t [8]uint64
+		copy(t[:4], w[:4])
+		for i := 0; i < 4; i++ {
+			if w[4+i] == 0 {
+				continue
+			}
+			for j := 0; j < 3; j++ {
+				mulAddAt(&t, i+j, w[4+i], scalarNC[j])
+			}
+		}
+		*w = t
+	}
+	copy(s.n[:], w[:4])
+	s.reduceOnce(0)
+}
+
+// mulAddAt adds a*b to w at limb pos, propagating the carry.
+func mulAddAt(w *[8]uint64, pos int, a, b uint64) {
+	hi, lo := bits.Mul64(a, b)
+	var carry uint64
+	w[pos], carry = bits.Add64(w[pos], lo, 0)
+	w[pos+1], carry = bits.Add64(w[pos+1], hi, carry)
+	for k := pos + 2; carry != 0 && k < 8; k++ {
+		w[k], carry = bits.Add64(w[k], 0, carry)
+	}
+}
@@ -0,0 +1,145 @@
+package elliptic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// testScalars returns scalars covering the edge cases of the reduction as
+// well as hash-derived ones.
+func testScalars() []*big.Int {
+	n := S256().N
+	vals := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(n, big.NewInt(1)),
+		new(big.Int).Sub(n, big.NewInt(2)),
+		new(big.Int).Rsh(n, 1),
+		new(big.Int).Add(new(big.Int).Rsh(n, 1), big.NewInt(1)),
+		new(big.Int).Lsh(big.NewInt(1), 128),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1)),
+	}
+	for i := 0; i < 16; i++ {
+		h := sha256.Sum256([]byte{byte(i)})
+		v := new(big.Int).SetBytes(h[:])
+		vals = append(vals, v.Mod(v, n))
+	}
+	return vals
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	n := S256().N
+	vals := testScalars()
+	for _, a := range vals {
+		var sa Scalar
+		sa.SetBigInt(a)
+		if sa.BigInt().Cmp(a) != 0 {
+			t.Fatalf("SetBigInt(%x) round trips to %x", a, sa.BigInt())
+		}
+
+		var neg Scalar
+		want := new(big.Int).Neg(a)
+		if got := neg.Negate(&sa).BigInt(); got.Cmp(want.Mod(want, n)) != 0 {
+			t.Errorf("-%x: got %x, want %x", a, got, want)
+		}
+		if over := sa.IsOverHalfOrder(); over != (a.Cmp(new(big.Int).Rsh(n, 1)) > 0) {
+			t.Errorf("IsOverHalfOrder(%x) = %v", a, over)
+		}
+		if a.Sign() != 0 {
+			var inv, one Scalar
+			inv.Inverse(&sa)
+			if !one.Mul(&inv, &sa).Equal(new(Scalar).SetInt(1)) {
+				t.Errorf("%x * %x^-1 != 1", a, a)
+			}
+		}
+
+		for _, b := range vals {
+			var sb, r Scalar
+			sb.SetBigInt(b)
+
+			want := new(big.Int).Add(a, b)
+			if got := r.Add(&sa, &sb).BigInt(); got.Cmp(want.Mod(want, n)) != 0 {
+				t.Errorf("%x + %x: got %x, want %x", a, b, got, want)
+			}
+			want = new(big.Int).Sub(a, b)
+			if got := r.Sub(&sa, &sb).BigInt(); got.Cmp(want.Mod(want, n)) != 0 {
+				t.Errorf("%x - %x: got %x, want %x", a, b, got, want)
+			}
+			want = new(big.Int).Mul(a, b)
+			if got := r.Mul(&sa, &sb).BigInt(); got.Cmp(want.Mod(want, n)) != 0 {
+				t.Errorf("%x * %x: got %x, want %x", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestScalarAliasing(t *testing.T) {
+	var a, b Scalar
+	a.SetInt(6)
+	b.SetInt(7)
+	a.Mul(&a, &b).Add(&a, &a).Sub(&a, &b)
+	if want := new(Scalar).SetInt(6*7*2 - 7); !a.Equal(want) {
+		t.Errorf("got %x, want %x", a.BigInt(), want.BigInt())
+	}
+}
+
+func TestScalarEncoding(t *testing.T) {
+	n := S256().N
+	nBytes := n.Bytes()
+
+	if _, err := ParseScalar(nBytes); err != ErrScalarOutOfRange {
+		t.Errorf("ParseScalar(N): got %v, want %v", err, ErrScalarOutOfRange)
+	}
+	if _, err := ParseScalar(nBytes[1:]); err != ErrScalarOutOfRange {
+		t.Errorf("ParseScalar(31 bytes): got %v, want %v", err, ErrScalarOutOfRange)
+	}
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1)).Bytes()
+	s, err := ParseScalar(nMinus1)
+	if err != nil {
+		t.Fatalf("ParseScalar(N-1): %v", err)
+	}
+	if b := s.Bytes(); !bytes.Equal(b[:], nMinus1) {
+		t.Errorf("Bytes: got %x, want %x", b, nMinus1)
+	}
+
+	// SetBytes reduces instead of rejecting.
+	if !new(Scalar).SetBytes(nBytes).IsZero() {
+		t.Error("SetBytes(N) is not zero")
+	}
+	wide := append([]byte{1}, make([]byte, 32)...)
+	want := new(big.Int).Mod(new(big.Int).SetBytes(wide), n)
+	if got := new(Scalar).SetBytes(wide).BigInt(); got.Cmp(want) != 0 {
+		t.Errorf("SetBytes(2^256): got %x, want %x", got, want)
+	}
+	if got := new(Scalar).SetBytes([]byte{1, 2}).BigInt(); got.Int64() != 0x102 {
+		t.Errorf("SetBytes(0x0102): got %x", got)
+	}
+	if got := new(Scalar).SetBigInt(big.NewInt(-1)).BigInt(); got.Cmp(new(big.Int).Sub(n, big.NewInt(1))) != 0 {
+		t.Errorf("SetBigInt(-1): got %x", got)
+	}
+
+	s.Zero()
+	if !s.IsZero() {
+		t.Error("Zero did not clear the scalar")
+	}
+}
+
+func BenchmarkScalarMul(b *testing.B) {
+	var x, y Scalar
+	x.SetBytes(bytes.Repeat([]byte{0xab}, 32))
+	y.SetBytes(bytes.Repeat([]byte{0xcd}, 32))
+	for i := 0; i < b.N; i++ {
+		x.Mul(&x, &y)
+	}
+}
+
+func BenchmarkScalarInverse(b *testing.B) {
+	var x Scalar
+	x.SetBytes(bytes.Repeat([]byte{0xab}, 32))
+	for i := 0; i < b.N; i++ {
+		x.Inverse(&x)
+	}
+}
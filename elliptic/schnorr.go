@@ -0,0 +1,159 @@
+package elliptic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// LenSchnorrSignature is the length of a serialized BIP340 signature.
+const LenSchnorrSignature = 64
+
+// SchnorrSignature is a BIP340 Schnorr signature.  R is the X coordinate of
+// the nonce point, whose Y coordinate is always even.
+type SchnorrSignature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// TaggedHash implements the tagged hash of BIP340:
+// SHA256(SHA256(tag) || SHA256(tag) || msgs...).  Tagging the hash with the
+// name of its purpose keeps hashes from different protocols apart.
+func TaggedHash(tag string, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// Serialize returns the 64 byte encoding bytes(R) || bytes(S) of the
+// signature.
+func (sig *SchnorrSignature) Serialize() []byte {
+	b := make([]byte, 0, LenSchnorrSignature)
+	b = paddedAppend(32, b, sig.R.Bytes())
+	return paddedAppend(32, b, sig.S.Bytes())
+}
+
+// ParseSchnorrSignature parses a 64 byte BIP340 signature, checking that R is
+// a field element and S is a scalar.
+func ParseSchnorrSignature(sig []byte) (*SchnorrSignature, error) {
+	if len(sig) != LenSchnorrSignature {
+		return nil, errors.New("malformed schnorr signature: invalid length")
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	if r.Cmp(S256().P) >= 0 {
+		return nil, errors.New("schnorr signature R is >= curve.P")
+	}
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(S256().N) >= 0 {
+		return nil, errors.New("schnorr signature S is >= curve.N")
+	}
+	return &SchnorrSignature{R: r, S: s}, nil
+}
+
+// IsEqual compares this SchnorrSignature instance to the one passed, returning
+// true if both have the same R and S.
+func (sig *SchnorrSignature) IsEqual(otherSig *SchnorrSignature) bool {
+	return sig.R.Cmp(otherSig.R) == 0 && sig.S.Cmp(otherSig.S) == 0
+}
+
+// Verify checks the signature of hash against the x-only form of pubKey as
+// described in BIP340.  The parity of pubKey's Y coordinate is ignored.
+func (sig *SchnorrSignature) Verify(hash []byte, pubKey *PublicKey) bool {
+	curve := S256()
+	if sig.R.Cmp(curve.P) >= 0 || sig.S.Cmp(curve.N) >= 0 {
+		return false
+	}
+	pk, err := ParseXOnlyPubKey(pubKey.SerializeXOnly())
+	if err != nil {
+		return false
+	}
+
+	e := schnorrChallenge(paddedAppend(32, nil, sig.R.Bytes()), pk.SerializeXOnly(), hash)
+
+	// R = s*G - e*P
+	sGx, sGy := curve.ScalarBaseMult(sig.S.Bytes())
+	e.Sub(curve.N, e)
+	ePx, ePy := curve.ScalarMult(pk.X, pk.Y, e.Bytes())
+	rx, ry := curve.Add(sGx, sGy, ePx, ePy)
+
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return false
+	}
+	return !isOdd(ry) && rx.Cmp(sig.R) == 0
+}
+
+// SignSchnorr generates a BIP340 Schnorr signature for hash using the private
+// key and 32 bytes of fresh auxiliary randomness.
+func (p *PrivateKey) SignSchnorr(hash []byte) (*SchnorrSignature, error) {
+	var auxRand [32]byte
+	if _, err := io.ReadFull(rand.Reader, auxRand[:]); err != nil {
+		return nil, err
+	}
+	return SignSchnorrWithAuxRand(p, hash, auxRand[:])
+}
+
+// SignSchnorrWithAuxRand generates a BIP340 Schnorr signature for hash using
+// the given 32 bytes of auxiliary randomness, which makes the signature
+// deterministic.  This is mostly useful to reproduce the BIP340 test vectors.
+func SignSchnorrWithAuxRand(privateKey *PrivateKey, hash, auxRand []byte) (*SchnorrSignature, error) {
+	curve := S256()
+	if len(auxRand) != 32 {
+		return nil, errors.New("auxiliary randomness must be 32 bytes")
+	}
+	d := new(big.Int).Set(privateKey.D)
+	if d.Sign() == 0 || d.Cmp(curve.N) >= 0 {
+		return nil, errors.New("private key is out of range")
+	}
+
+	// Negate the key if its public key has an odd Y so that the x-only
+	// public key corresponds to it.
+	px, py := curve.ScalarBaseMult(paddedAppend(32, nil, d.Bytes()))
+	if isOdd(py) {
+		d.Sub(curve.N, d)
+	}
+	pkBytes := paddedAppend(32, nil, px.Bytes())
+
+	t := paddedAppend(32, nil, d.Bytes())
+	auxHash := TaggedHash("BIP0340/aux", auxRand)
+	for i := range t {
+		t[i] ^= auxHash[i]
+	}
+
+	k := new(big.Int).SetBytes(TaggedHash("BIP0340/nonce", t, pkBytes, hash))
+	k.Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil, errors.New("calculated nonce is zero")
+	}
+
+	rx, ry := curve.ScalarBaseMult(k.Bytes())
+	if isOdd(ry) {
+		k.Sub(curve.N, k)
+	}
+	rBytes := paddedAppend(32, nil, rx.Bytes())
+
+	e := schnorrChallenge(rBytes, pkBytes, hash)
+	s := e.Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+
+	sig := &SchnorrSignature{R: rx, S: s}
+	pk := PublicKey{Curve: curve, X: px, Y: py}
+	if !sig.Verify(hash, &pk) {
+		return nil, errors.New("generated schnorr signature doesn't verify")
+	}
+	return sig, nil
+}
+
+// schnorrChallenge computes the BIP340 challenge
+// int(hash_BIP0340/challenge(bytes(R) || bytes(P) || m)) mod n.
+func schnorrChallenge(r, pk, msg []byte) *big.Int {
+	e := new(big.Int).SetBytes(TaggedHash("BIP0340/challenge", r, pk, msg))
+	return e.Mod(e, S256().N)
+}
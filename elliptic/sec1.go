@@ -0,0 +1,168 @@
+package elliptic
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+)
+
+// crypto/x509 only knows the NIST curves, so the ASN.1 structures of SEC 1
+// (RFC 5915) and of subject public key info (RFC 5480) are encoded here for
+// secp256k1 keys, the same way OpenSSL does.
+
+var (
+	// oidPublicKeyECDSA is id-ecPublicKey from RFC 5480.
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+	// oidNamedCurveSecp256k1 is the secp256k1 curve OID from SEC 2.
+	oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ecPrivKeyVersion is the version of the RFC 5915 ECPrivateKey structure.
+const ecPrivKeyVersion = 1
+
+// ErrNotSecp256k1 is returned when parsing ASN.1 keys of another curve or
+// algorithm, or with explicit instead of named curve parameters.
+var ErrNotSecp256k1 = errors.New("key is not a named curve secp256k1 key")
+
+// ecPrivateKey is the ECPrivateKey structure of RFC 5915.
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// subjectPublicKeyInfo is the SubjectPublicKeyInfo structure of RFC 5280.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// MarshalSEC1 returns the DER encoding of the private key as an RFC 5915
+// ECPrivateKey with the secp256k1 curve OID and the uncompressed public key,
+// the "EC PRIVATE KEY" format of `openssl ecparam -genkey`.
+func (p *PrivateKey) MarshalSEC1() ([]byte, error) {
+	return p.marshalECPrivateKey(oidNamedCurveSecp256k1)
+}
+
+// marshalECPrivateKey encodes an ECPrivateKey, with the curve OID unless it
+// is nil.
+func (p *PrivateKey) marshalECPrivateKey(oid asn1.ObjectIdentifier) ([]byte, error) {
+	priv := p.PrivatekeyToBytes()
+	defer zeroBytes(priv)
+	pub := p.ECPubKey().SerializeUncompressed()
+	return asn1.Marshal(ecPrivateKey{
+		Version:       ecPrivKeyVersion,
+		PrivateKey:    priv,
+		NamedCurveOID: oid,
+		PublicKey:     asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+	})
+}
+
+// ParseSEC1PrivateKey parses the DER encoding of an RFC 5915 ECPrivateKey.
+// The curve parameters, when present, must name secp256k1 and the public
+// key, when present, must match the private key.
+func ParseSEC1PrivateKey(der []byte) (*PrivateKey, error) {
+	return parseECPrivateKey(der, nil)
+}
+
+// parseECPrivateKey parses an ECPrivateKey whose curve, if not given by the
+// enclosing PKCS #8 structure as curveOID, must be in its parameters.
+func parseECPrivateKey(der []byte, curveOID asn1.ObjectIdentifier) (*PrivateKey, error) {
+	var key ecPrivateKey
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return nil, errors.New("malformed SEC 1 private key: " + err.Error())
+	}
+	defer zeroBytes(key.PrivateKey)
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after SEC 1 private key")
+	}
+	if key.Version != ecPrivKeyVersion {
+		return nil, errors.New("unsupported SEC 1 private key version")
+	}
+	if len(key.NamedCurveOID) > 0 {
+		curveOID = key.NamedCurveOID
+	}
+	if curveOID != nil && !curveOID.Equal(oidNamedCurveSecp256k1) {
+		return nil, ErrNotSecp256k1
+	}
+
+	// RFC 5915 fixes the length of the scalar, but some encoders strip
+	// its leading zeros.
+	if len(key.PrivateKey) > 32 {
+		return nil, ErrPrivKeyLength
+	}
+	var d [32]byte
+	defer zeroArray32(&d)
+	copy(d[32-len(key.PrivateKey):], key.PrivateKey)
+	priv, pub, err := PrivKeyFromBytesStrict(S256(), d[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if key.PublicKey.BitLength > 0 {
+		embedded, err := ParsePubKey(key.PublicKey.RightAlign(), S256())
+		if err != nil {
+			return nil, err
+		}
+		if embedded.X.Cmp(pub.X) != 0 || embedded.Y.Cmp(pub.Y) != 0 {
+			return nil, errors.New("SEC 1 public key does not match the private key")
+		}
+	}
+	return priv, nil
+}
+
+// MarshalPKIX returns the DER encoding of the public key as an RFC 5480
+// SubjectPublicKeyInfo with the secp256k1 curve OID and the uncompressed
+// point, the "PUBLIC KEY" format of `openssl pkey -pubout`.
+func (p *PublicKey) MarshalPKIX() ([]byte, error) {
+	params, err := asn1.Marshal(oidNamedCurveSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	pub := p.SerializeUncompressed()
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PublicKey: asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+	})
+}
+
+// ParsePKIXPublicKey parses the DER encoding of an RFC 5480
+// SubjectPublicKeyInfo of a secp256k1 public key, either compressed or
+// uncompressed.
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	var spki subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil {
+		return nil, errors.New("malformed subject public key info: " + err.Error())
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after subject public key info")
+	}
+	if err := checkSecp256k1Algorithm(spki.Algorithm); err != nil {
+		return nil, err
+	}
+	if spki.PublicKey.BitLength%8 != 0 {
+		return nil, errors.New("malformed subject public key")
+	}
+	return ParsePubKey(spki.PublicKey.Bytes, S256())
+}
+
+// checkSecp256k1Algorithm returns ErrNotSecp256k1 unless the algorithm is
+// id-ecPublicKey with the secp256k1 named curve as parameters.
+func checkSecp256k1Algorithm(algo pkix.AlgorithmIdentifier) error {
+	if !algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return ErrNotSecp256k1
+	}
+	var curveOID asn1.ObjectIdentifier
+	rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &curveOID)
+	if err != nil || len(rest) > 0 || !curveOID.Equal(oidNamedCurveSecp256k1) {
+		return ErrNotSecp256k1
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package elliptic
+
+import (
+	"crypto/sha256"
+
+	// bech32 "../bech32"
+	"github.com/symphonyprotocol/sutil/bech32"
+)
+
+// opCheckSig is the OP_CHECKSIG script opcode.
+const opCheckSig = 0xac
+
+// ToP2WPKHAddress returns the segwit version 0 pay-to-witness-pubkey-hash
+// address of the compressed public key on the network.
+func (p *PublicKey) ToP2WPKHAddress(net *NetParams) (string, error) {
+	return bech32.EncodeSegwitAddress(net.Bech32HRPSegwit, 0, Hash160(p.SerializeCompressed()))
+}
+
+// ToP2WSHAddress returns the segwit version 0 pay-to-witness-script-hash
+// address of the single key script <compressed public key> OP_CHECKSIG on
+// the network.
+func (p *PublicKey) ToP2WSHAddress(net *NetParams) (string, error) {
+	script := append(append([]byte{LenPubKeyBytesCompressed}, p.SerializeCompressed()...), opCheckSig)
+	hash := sha256.Sum256(script)
+	return bech32.EncodeSegwitAddress(net.Bech32HRPSegwit, 0, hash[:])
+}
+
+// ToTaprootAddress returns the segwit version 1 pay-to-taproot address of
+// the output key that ComputeTaprootOutputKey derives from the public key as
+// internal key and the merkle root of its script tree, which is nil for the
+// key path only outputs of BIP86.
+func (p *PublicKey) ToTaprootAddress(net *NetParams, merkleRoot []byte) (string, error) {
+	q, err := ComputeTaprootOutputKey(p, merkleRoot)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeSegwitAddress(net.Bech32HRPSegwit, 1, q.SerializeXOnly())
+}
@@ -0,0 +1,40 @@
+package elliptic
+
+import (
+	"testing"
+)
+
+func TestSegwitAddresses(t *testing.T) {
+	// The addresses of the generator from BIP173 and of the first BIP86
+	// key of the "abandon ... about" mnemonic.
+	g, err := ParsePubKey(hexToBytes("0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"), S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bip86, err := ParsePubKey(hexToBytes("03cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115"), S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		addr func(*NetParams) (string, error)
+		want string
+	}{
+		{"P2WPKH", g.ToP2WPKHAddress, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"P2WSH", g.ToP2WSHAddress, "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3"},
+		{"P2TR", func(net *NetParams) (string, error) {
+			return bip86.ToTaprootAddress(net, nil)
+		}, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+	for _, test := range tests {
+		got, err := test.addr(&BitcoinNetParams)
+		if err != nil || got != test.want {
+			t.Errorf("%s: got %s, %v, want %s", test.name, got, err, test.want)
+		}
+		got, err = test.addr(&SymphonyNetParams)
+		if err != nil || got[:3] != SymphonyNetParams.Bech32HRPSegwit+"1" {
+			t.Errorf("%s: symphony address %s, %v", test.name, got, err)
+		}
+	}
+}
@@ -108,11 +108,14 @@ func parseSig(sigStr []byte, curve elliptic.Curve, der bool) (*Signature, error)
 	// length of remaining message
 	siglen := sigStr[index]
 	index++
-	if int(siglen+2) > len(sigStr) {
+	if int(siglen)+2 > len(sigStr) {
 		return nil, errors.New("malformed signature: bad length")
 	}
 	// trim the slice we're working on so we only look at what matters.
-	sigStr = sigStr[:siglen+2]
+	sigStr = sigStr[:int(siglen)+2]
+	if len(sigStr) < 8 {
+		return nil, errors.New("malformed signature: too short")
+	}
 
 	// 0x02
 	if sigStr[index] != 0x02 {
@@ -399,6 +402,9 @@ func RecoverCompact(curve *KoblitzCurve, signature,
 		R: new(big.Int).SetBytes(signature[1 : bitlen+1]),
 		S: new(big.Int).SetBytes(signature[bitlen+1:]),
 	}
+	if !sigInRange(sig) {
+		return nil, false, errors.New("signature R or S is out of range")
+	}
 	// The iteration used here was encoded
 	key, err := recoverKeyFromSignature(curve, sig, hash, iteration, false)
 	if err != nil {
@@ -440,6 +446,13 @@ func signRFC6979(privateKey *PrivateKey, hash []byte) (*Signature, error) {
 	return &Signature{R: r, S: s}, nil
 }
 
+// NonceRFC6979 returns the deterministic RFC 6979 nonce for privkey and the
+// 32-byte hash, for protocols other than ECDSA that specify it, such as
+// ECVRF.
+func NonceRFC6979(privkey *big.Int, hash []byte) *big.Int {
+	return nonceRFC6979(privkey, hash)
+}
+
 // nonceRFC6979 generates an ECDSA nonce (`k`) deterministically according to RFC 6979.
 // It takes a 32-byte hash as an input and returns 32-byte nonce to be used in ECDSA algorithm.
 func nonceRFC6979(privkey *big.Int, hash []byte) *big.Int {
@@ -0,0 +1,182 @@
+package elliptic
+
+import (
+	"crypto/elliptic"
+	"fmt"
+)
+
+// SignaturePolicy is a bitmask of the encoding rules enforced by
+// ParseSignatureWithPolicy on top of the basic sanity checks done by
+// ParseSignature.
+type SignaturePolicy uint32
+
+const (
+	// PolicyStrictDER requires the signature to be encoded in the strict
+	// DER form of BIP66: no excess padding, no negative integers and
+	// lengths that exactly describe the signature.
+	PolicyStrictDER SignaturePolicy = 1 << iota
+
+	// PolicyLowS requires S to be at most N/2 as per BIP62, which removes
+	// the malleability of (R, N-S).
+	PolicyLowS
+
+	// PolicyNoTrailingBytes rejects any bytes after the end of the sequence
+	// given by the DER length byte, which ParseSignature otherwise ignores.
+	// It is implied by PolicyStrictDER.
+	PolicyNoTrailingBytes
+
+	// PolicyConsensus is the set of rules required for signatures that are
+	// checked by consensus code.
+	PolicyConsensus = PolicyStrictDER | PolicyLowS | PolicyNoTrailingBytes
+)
+
+// Names of the rules a SignatureRuleError can report.
+const (
+	SigRuleStrictDER       = "BIP66 strict DER"
+	SigRuleLowS            = "BIP62 low S"
+	SigRuleNoTrailingBytes = "no trailing bytes"
+)
+
+// SignatureRuleError is returned by ParseSignatureWithPolicy and
+// ValidateStrictDER when a signature violates one of the enforced rules.
+type SignatureRuleError struct {
+	Rule        string // one of the SigRule constants
+	Description string // what exactly is wrong with the signature
+}
+
+// Error implements the error interface.
+func (e *SignatureRuleError) Error() string {
+	return fmt.Sprintf("signature violates %s: %s", e.Rule, e.Description)
+}
+
+func strictDERError(format string, args ...interface{}) error {
+	return &SignatureRuleError{Rule: SigRuleStrictDER, Description: fmt.Sprintf(format, args...)}
+}
+
+// ValidateStrictDER checks that sig is a DER signature that satisfies the
+// encoding rules of BIP66.  Unlike the check in BIP66 itself sig must not
+// include the trailing hash type byte used in bitcoin scripts, which is also
+// how Serialize returns it.  The values of R and S are not range checked.
+//
+// The format is:
+//
+// 0x30 <length> 0x02 <length r> r 0x02 <length s> s
+func ValidateStrictDER(sig []byte) error {
+	// Minimum and maximum size constraints: 1 byte integers at minimum,
+	// 33 byte (zero padded) integers at maximum.
+	if len(sig) < 8 {
+		return strictDERError("too short: %d < 8", len(sig))
+	}
+	if len(sig) > 72 {
+		return strictDERError("too long: %d > 72", len(sig))
+	}
+
+	// A signature is of type 0x30 (compound).
+	if sig[0] != 0x30 {
+		return strictDERError("wrong type 0x%02x, expected 0x30", sig[0])
+	}
+
+	// Make sure the length covers the entire signature.
+	if int(sig[1]) != len(sig)-2 {
+		return strictDERError("length byte %d doesn't match remaining %d bytes",
+			sig[1], len(sig)-2)
+	}
+
+	// Extract the length of the R element and make sure the length of the
+	// S element is still inside the signature.
+	lenR := int(sig[3])
+	if 5+lenR >= len(sig) {
+		return strictDERError("R length %d overflows the signature", lenR)
+	}
+
+	// Extract the length of the S element and verify that the length of
+	// the signature matches the sum of the lengths of the elements.
+	lenS := int(sig[5+lenR])
+	if lenR+lenS+6 != len(sig) {
+		return strictDERError("R length %d and S length %d don't add up to %d",
+			lenR, lenS, len(sig))
+	}
+
+	// Check whether the R element is an integer.
+	if sig[2] != 0x02 {
+		return strictDERError("R has type 0x%02x, expected integer 0x02", sig[2])
+	}
+
+	// Zero-length integers are not allowed for R.
+	if lenR == 0 {
+		return strictDERError("R is zero length")
+	}
+
+	// Negative numbers are not allowed for R.
+	if sig[4]&0x80 != 0 {
+		return strictDERError("R is negative")
+	}
+
+	// Null bytes at the start of R are not allowed, unless R would
+	// otherwise be interpreted as a negative number.
+	if lenR > 1 && sig[4] == 0x00 && sig[5]&0x80 == 0 {
+		return strictDERError("R is excessively padded")
+	}
+
+	// Check whether the S element is an integer.
+	if sig[lenR+4] != 0x02 {
+		return strictDERError("S has type 0x%02x, expected integer 0x02", sig[lenR+4])
+	}
+
+	// Zero-length integers are not allowed for S.
+	if lenS == 0 {
+		return strictDERError("S is zero length")
+	}
+
+	// Negative numbers are not allowed for S.
+	if sig[lenR+6]&0x80 != 0 {
+		return strictDERError("S is negative")
+	}
+
+	// Null bytes at the start of S are not allowed, unless S would
+	// otherwise be interpreted as a negative number.
+	if lenS > 1 && sig[lenR+6] == 0x00 && sig[lenR+7]&0x80 == 0 {
+		return strictDERError("S is excessively padded")
+	}
+
+	return nil
+}
+
+// IsLowS returns whether S is at most half the order of the secp256k1 group,
+// which is the canonical choice between S and N-S required by BIP62.
+func (sig *Signature) IsLowS() bool {
+	return sig.S.Cmp(S256().halfOrder) <= 0
+}
+
+// ParseSignatureWithPolicy parses a DER signature like ParseSignature and
+// then enforces every rule set in policy.  Violations are reported as a
+// *SignatureRuleError naming the rule.
+func ParseSignatureWithPolicy(sigStr []byte, curve elliptic.Curve, policy SignaturePolicy) (*Signature, error) {
+	strict := policy&PolicyStrictDER != 0
+	if strict {
+		if err := ValidateStrictDER(sigStr); err != nil {
+			return nil, err
+		}
+	}
+	if policy&PolicyNoTrailingBytes != 0 && len(sigStr) >= 2 &&
+		len(sigStr) > int(sigStr[1])+2 {
+		return nil, &SignatureRuleError{
+			Rule: SigRuleNoTrailingBytes,
+			Description: fmt.Sprintf("%d bytes after the end of the signature",
+				len(sigStr)-int(sigStr[1])-2),
+		}
+	}
+
+	sig, err := parseSig(sigStr, curve, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy&PolicyLowS != 0 && !sig.IsLowS() {
+		return nil, &SignatureRuleError{
+			Rule:        SigRuleLowS,
+			Description: "S is greater than N/2",
+		}
+	}
+	return sig, nil
+}
@@ -0,0 +1,106 @@
+package elliptic
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// derSig assembles a DER-like signature from its parts without any
+// validation so that every BIP66 rule can be violated on purpose.
+func derSig(rType byte, r []byte, sType byte, s []byte) []byte {
+	b := []byte{0x30, byte(4 + len(r) + len(s)), rType, byte(len(r))}
+	b = append(b, r...)
+	b = append(b, sType, byte(len(s)))
+	return append(b, s...)
+}
+
+func TestSignaturePolicy(t *testing.T) {
+	r := hexToBytes("4e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd41")
+	s := hexToBytes("181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d09")
+	highS := canonicalizeInt(new(big.Int).Sub(S256().N, new(big.Int).SetBytes(s)))
+	valid := derSig(0x02, r, 0x02, s)
+
+	tests := []struct {
+		name   string
+		sig    []byte
+		policy SignaturePolicy
+		rule   string // expected violated rule, "" for none
+		lax    bool   // whether ParseSignature accepts it
+	}{
+		{"valid", valid, PolicyConsensus, "", true},
+		{"high S", derSig(0x02, r, 0x02, highS), PolicyConsensus, SigRuleLowS, true},
+		{"high S without low S rule", derSig(0x02, r, 0x02, highS), PolicyStrictDER, "", true},
+		{"trailing byte", append(append([]byte{}, valid...), 0x01), PolicyStrictDER, SigRuleStrictDER, true},
+		{"trailing byte lax", append(append([]byte{}, valid...), 0x01), PolicyNoTrailingBytes, SigRuleNoTrailingBytes, true},
+		{"too short", []byte{0x30, 0x05, 0x02, 0x01, 0x01, 0x02, 0x00}, PolicyStrictDER, SigRuleStrictDER, false},
+		{"too long", derSig(0x02, append(make([]byte, 2), r...), 0x02, append(make([]byte, 2), s...)), PolicyStrictDER, SigRuleStrictDER, true},
+		{"wrong type", append([]byte{0x31}, valid[1:]...), PolicyStrictDER, SigRuleStrictDER, false},
+		{"bad total length", append([]byte{0x30, valid[1] - 1}, valid[2:]...), PolicyStrictDER, SigRuleStrictDER, false},
+		{"R length overflow", append([]byte{0x30, valid[1], 0x02, 0x45}, valid[4:]...), PolicyStrictDER, SigRuleStrictDER, false},
+		{"S length mismatch", append(append([]byte{}, valid[:len(r)+5]...), append([]byte{0x21}, s...)...), PolicyStrictDER, SigRuleStrictDER, false},
+		{"R not integer", derSig(0x03, r, 0x02, s), PolicyStrictDER, SigRuleStrictDER, false},
+		{"R zero length", derSig(0x02, nil, 0x02, s), PolicyStrictDER, SigRuleStrictDER, false},
+		{"R negative", derSig(0x02, append([]byte{0x80}, r[1:]...), 0x02, s), PolicyStrictDER, SigRuleStrictDER, true},
+		{"R excessively padded", derSig(0x02, append([]byte{0x00}, r...), 0x02, s), PolicyStrictDER, SigRuleStrictDER, true},
+		{"S not integer", derSig(0x02, r, 0x03, s), PolicyStrictDER, SigRuleStrictDER, false},
+		{"S zero length", derSig(0x02, r, 0x02, nil), PolicyStrictDER, SigRuleStrictDER, false},
+		{"S negative", derSig(0x02, r, 0x02, append([]byte{0x80}, s[1:]...)), PolicyStrictDER, SigRuleStrictDER, true},
+		{"S excessively padded", derSig(0x02, r, 0x02, append([]byte{0x00}, s...)), PolicyStrictDER, SigRuleStrictDER, true},
+		{"padded negative R is fine", derSig(0x02, append([]byte{0x00, 0x80}, r[1:]...), 0x02, s), PolicyStrictDER, "", true},
+	}
+
+	for _, test := range tests {
+		sig, err := ParseSignatureWithPolicy(test.sig, S256(), test.policy)
+		if test.rule == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+		} else {
+			ruleErr, ok := err.(*SignatureRuleError)
+			if !ok {
+				t.Errorf("%s: got %v, want violation of %s", test.name, err, test.rule)
+			} else if ruleErr.Rule != test.rule {
+				t.Errorf("%s: got violation of %s, want %s", test.name, ruleErr.Rule, test.rule)
+			}
+		}
+		if sig != nil && test.policy&PolicyLowS != 0 && !sig.IsLowS() {
+			t.Errorf("%s: accepted a high S signature", test.name)
+		}
+
+		_, err = ParseSignature(test.sig, S256())
+		if (err == nil) != test.lax {
+			t.Errorf("%s: ParseSignature got error %v, want success %v", test.name, err, test.lax)
+		}
+	}
+}
+
+func TestSerializeIsStrictDER(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		seed := bytes.Repeat([]byte{byte(i + 1)}, 32)
+		priv, _ := PrivKeyFromBytes(S256(), seed)
+		sig, err := priv.Sign(seed)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		if !sig.IsLowS() {
+			t.Errorf("Sign produced high S")
+		}
+		if err := ValidateStrictDER(sig.Serialize()); err != nil {
+			t.Errorf("Serialize produced invalid DER: %v", err)
+		}
+		if _, err := ParseSignatureWithPolicy(sig.Serialize(), S256(), PolicyConsensus); err != nil {
+			t.Errorf("ParseSignatureWithPolicy: %v", err)
+		}
+	}
+}
+
+func TestParseSignatureNoPanic(t *testing.T) {
+	// Length bytes near the top of the byte range used to wrap around.
+	for _, l := range []byte{0x00, 0x01, 0xfe, 0xff} {
+		sig := append([]byte{0x30, l}, make([]byte, 8)...)
+		if _, err := ParseSignature(sig, S256()); err == nil {
+			t.Errorf("length 0x%02x: expected error", l)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package elliptic
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// BaseLeafVersion is the tapscript leaf version of BIP342.
+const BaseLeafVersion byte = 0xc0
+
+// TapLeafHash returns the BIP341 leaf hash of a script:
+// hash_TapLeaf(leafVersion || compact_size(len(script)) || script).
+func TapLeafHash(leafVersion byte, script []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(leafVersion)
+	writeVarInt(&buf, uint64(len(script)))
+	buf.Write(script)
+	return TaggedHash("TapLeaf", buf.Bytes())
+}
+
+// TapBranchHash returns the BIP341 hash of the branch with children a and b,
+// which are hashed in lexicographic order so that the result doesn't depend
+// on the order of the children.
+func TapBranchHash(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return TaggedHash("TapBranch", a, b)
+}
+
+// TapTweakHash returns the BIP341 tweak hash_TapTweak(x(P) || merkleRoot)
+// of an internal key.  merkleRoot is nil for outputs without a script tree,
+// as BIP86 recommends for key path only outputs.
+func TapTweakHash(internalKey *PublicKey, merkleRoot []byte) []byte {
+	return TaggedHash("TapTweak", internalKey.SerializeXOnly(), merkleRoot)
+}
+
+// ComputeTaprootOutputKey returns the BIP341 output key Q = lift_x(P) + t*G
+// of the internal key P and the merkle root of its script tree, which is nil
+// when there is none.  The x-only form of Q is the witness program of the
+// P2TR output, the parity of its Y goes into script path control blocks.
+func ComputeTaprootOutputKey(internalKey *PublicKey, merkleRoot []byte) (*PublicKey, error) {
+	p, err := ParseXOnlyPubKey(internalKey.SerializeXOnly())
+	if err != nil {
+		return nil, err
+	}
+	return p.TweakAdd(TapTweakHash(p, merkleRoot))
+}
+
+// TweakTaprootPrivKey returns the private key of the output key that
+// ComputeTaprootOutputKey derives from priv's public key, which signs for
+// key path spends of the output with a BIP340 signature.
+func TweakTaprootPrivKey(priv *PrivateKey, merkleRoot []byte) (*PrivateKey, error) {
+	pub := priv.ECPubKey()
+	d := priv
+	if isOdd(pub.Y) {
+		negated := new(big.Int).Sub(S256().N, priv.D)
+		var err error
+		if d, err = privKeyFromScalar(negated.Mod(negated, S256().N)); err != nil {
+			return nil, err
+		}
+	}
+	return d.TweakAdd(TapTweakHash(pub, merkleRoot))
+}
@@ -0,0 +1,164 @@
+package elliptic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// TestTaprootOutputKey checks the scriptPubKey vectors of BIP341's
+// wallet-test-vectors.json.
+func TestTaprootOutputKey(t *testing.T) {
+	tests := []struct {
+		internalKey string
+		leaves      []string // leafVersion || script, hex
+		merkleRoot  string
+		outputKey   string
+	}{
+		{
+			internalKey: "d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d",
+			outputKey:   "53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+		},
+		{
+			internalKey: "187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+			leaves:      []string{"c020d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac"},
+			merkleRoot:  "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+			outputKey:   "147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+		},
+		{
+			internalKey: "93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820",
+			leaves:      []string{"c020b617298552a72ade070667e86ca63b8f5789a9fe8731ef91202a91c9f3459007ac"},
+			merkleRoot:  "c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b",
+			outputKey:   "e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e",
+		},
+		{
+			internalKey: "ee4fe085983462a184015d1f782d6a5f8b9c2b60130aff050ce221ecf3786592",
+			leaves: []string{
+				"c020387671353e273264c495656e27e39ba899ea8fee3bb69fb2a680e22093447d48ac",
+				"fa06424950333431",
+			},
+			merkleRoot: "6c2dc106ab816b73f9d07e3cd1ef2c8c1256f519748e0813e4edd2405d277bef",
+			outputKey:  "712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5",
+		},
+	}
+
+	for i, test := range tests {
+		internalKey, err := ParseXOnlyPubKey(hexToBytes(test.internalKey))
+		if err != nil {
+			t.Fatalf("#%d: ParseXOnlyPubKey: %v", i, err)
+		}
+
+		var root []byte
+		for _, leaf := range test.leaves {
+			b := hexToBytes(leaf)
+			h := TapLeafHash(b[0], b[1:])
+			if root == nil {
+				root = h
+			} else {
+				root = TapBranchHash(root, h)
+			}
+		}
+		if got := hex.EncodeToString(root); got != test.merkleRoot {
+			t.Errorf("#%d: merkle root %s, want %s", i, got, test.merkleRoot)
+		}
+
+		outputKey, err := ComputeTaprootOutputKey(internalKey, root)
+		if err != nil {
+			t.Fatalf("#%d: ComputeTaprootOutputKey: %v", i, err)
+		}
+		if got := hex.EncodeToString(outputKey.SerializeXOnly()); got != test.outputKey {
+			t.Errorf("#%d: output key %s, want %s", i, got, test.outputKey)
+		}
+	}
+}
+
+// TestTweakTaprootPrivKey checks the key path spending vector of BIP341's
+// wallet-test-vectors.json and that signatures of the tweaked key verify
+// against the output key.
+func TestTweakTaprootPrivKey(t *testing.T) {
+	priv, _ := PrivKeyFromBytes(S256(), hexToBytes("6b973d88838f27366ed61c9ad6367663045cb456e28335c109e30717ae0c6baa"))
+	tweaked, err := TweakTaprootPrivKey(priv, nil)
+	if err != nil {
+		t.Fatalf("TweakTaprootPrivKey: %v", err)
+	}
+	want := "2405b971772ad26915c8dcdf10f238753a9b837e5f8e6a86fd7c0cce5b7296d9"
+	if got := hex.EncodeToString(tweaked.PrivatekeyToBytes()); got != want {
+		t.Errorf("tweaked private key %s, want %s", got, want)
+	}
+
+	root := TapLeafHash(BaseLeafVersion, []byte{0x51})
+	for i := 0; i < 8; i++ {
+		priv, pub := PrivKeyFromBytes(S256(), bytes.Repeat([]byte{byte(i + 1)}, 32))
+		outputKey, err := ComputeTaprootOutputKey(pub, root)
+		if err != nil {
+			t.Fatalf("ComputeTaprootOutputKey: %v", err)
+		}
+		tweaked, err := TweakTaprootPrivKey(priv, root)
+		if err != nil {
+			t.Fatalf("TweakTaprootPrivKey: %v", err)
+		}
+		if !bytes.Equal(tweaked.ECPubKey().SerializeXOnly(), outputKey.SerializeXOnly()) {
+			t.Fatalf("tweaked private key does not match the output key")
+		}
+		msg := bytes.Repeat([]byte{0xaa}, 32)
+		sig, err := tweaked.SignSchnorr(msg)
+		if err != nil || !sig.Verify(msg, outputKey) {
+			t.Errorf("key path signature does not verify: %v", err)
+		}
+	}
+}
+
+func TestTweak(t *testing.T) {
+	priv, pub := PrivKeyFromBytes(S256(), bytes.Repeat([]byte{0x42}, 32))
+	tweak := bytes.Repeat([]byte{0x07}, 32)
+
+	addPriv, err := priv.TweakAdd(tweak)
+	if err != nil {
+		t.Fatalf("PrivateKey.TweakAdd: %v", err)
+	}
+	addPub, err := pub.TweakAdd(tweak)
+	if err != nil {
+		t.Fatalf("PublicKey.TweakAdd: %v", err)
+	}
+	if !bytes.Equal(addPriv.ECPubKey().SerializeCompressed(), addPub.SerializeCompressed()) {
+		t.Errorf("TweakAdd of the private and the public key disagree")
+	}
+
+	mulPriv, err := priv.TweakMul(tweak)
+	if err != nil {
+		t.Fatalf("PrivateKey.TweakMul: %v", err)
+	}
+	mulPub, err := pub.TweakMul(tweak)
+	if err != nil {
+		t.Fatalf("PublicKey.TweakMul: %v", err)
+	}
+	if !bytes.Equal(mulPriv.ECPubKey().SerializeCompressed(), mulPub.SerializeCompressed()) {
+		t.Errorf("TweakMul of the private and the public key disagree")
+	}
+
+	if !bytes.Equal(priv.PrivatekeyToBytes(), bytes.Repeat([]byte{0x42}, 32)) {
+		t.Errorf("tweaking modified the private key")
+	}
+
+	order := S256().N.Bytes()
+	zero := make([]byte, 32)
+	if _, err := priv.TweakAdd(order); err != ErrTweakOutOfRange {
+		t.Errorf("TweakAdd(N): got %v, want %v", err, ErrTweakOutOfRange)
+	}
+	if _, err := pub.TweakMul(zero); err != ErrTweakOutOfRange {
+		t.Errorf("TweakMul(0): got %v, want %v", err, ErrTweakOutOfRange)
+	}
+	if _, err := priv.TweakAdd(tweak[:31]); err != ErrTweakOutOfRange {
+		t.Errorf("short tweak: got %v, want %v", err, ErrTweakOutOfRange)
+	}
+
+	// d + (N - d) is zero.
+	neg := paddedAppend(32, nil, new(big.Int).Sub(S256().N, priv.D).Bytes())
+	if _, err := priv.TweakAdd(neg); err != ErrTweakInvalidResult {
+		t.Errorf("TweakAdd(-d): got %v, want %v", err, ErrTweakInvalidResult)
+	}
+	if _, err := pub.TweakAdd(neg); err != ErrTweakInvalidResult {
+		t.Errorf("PublicKey.TweakAdd(-d): got %v, want %v", err, ErrTweakInvalidResult)
+	}
+}
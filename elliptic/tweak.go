@@ -0,0 +1,92 @@
+package elliptic
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrTweakOutOfRange is returned when a tweak is not a valid scalar,
+	// i.e. not less than the group order, or zero for a multiplicative
+	// tweak.
+	ErrTweakOutOfRange = errors.New("tweak is out of range")
+
+	// ErrTweakInvalidResult is returned when tweaking a key gives the zero
+	// private key or the point at infinity.  It happens with negligible
+	// probability for tweaks derived from hashes.
+	ErrTweakInvalidResult = errors.New("tweaked key is invalid")
+)
+
+// TweakAdd returns the private key (d + tweak) mod N.  tweak is a 32 byte big
+// endian integer that must be less than N.  The key itself is not modified.
+func (p *PrivateKey) TweakAdd(tweak []byte) (*PrivateKey, error) {
+	t, err := parseTweak(tweak, true)
+	if err != nil {
+		return nil, err
+	}
+	d := t.Add(t, p.D)
+	d.Mod(d, S256().N)
+	return privKeyFromScalar(d)
+}
+
+// TweakMul returns the private key (d * tweak) mod N.  tweak is a 32 byte big
+// endian integer in [1, N-1].  The key itself is not modified.
+func (p *PrivateKey) TweakMul(tweak []byte) (*PrivateKey, error) {
+	t, err := parseTweak(tweak, false)
+	if err != nil {
+		return nil, err
+	}
+	d := t.Mul(t, p.D)
+	d.Mod(d, S256().N)
+	return privKeyFromScalar(d)
+}
+
+// TweakAdd returns the public key P + tweak*G, which belongs to the private
+// key tweaked by PrivateKey.TweakAdd with the same tweak.
+func (p *PublicKey) TweakAdd(tweak []byte) (*PublicKey, error) {
+	t, err := parseTweak(tweak, true)
+	if err != nil {
+		return nil, err
+	}
+	curve := S256()
+	tx, ty := curve.ScalarBaseMult(paddedAppend(32, nil, t.Bytes()))
+	x, y := curve.Add(p.X, p.Y, tx, ty)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, ErrTweakInvalidResult
+	}
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// TweakMul returns the public key tweak*P, which belongs to the private key
+// tweaked by PrivateKey.TweakMul with the same tweak.
+func (p *PublicKey) TweakMul(tweak []byte) (*PublicKey, error) {
+	t, err := parseTweak(tweak, false)
+	if err != nil {
+		return nil, err
+	}
+	curve := S256()
+	x, y := curve.ScalarMult(p.X, p.Y, t.Bytes())
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// parseTweak parses a 32 byte tweak, which must be less than N and, unless
+// allowZero is set, non-zero.
+func parseTweak(tweak []byte, allowZero bool) (*big.Int, error) {
+	if len(tweak) != 32 {
+		return nil, ErrTweakOutOfRange
+	}
+	t := new(big.Int).SetBytes(tweak)
+	if t.Cmp(S256().N) >= 0 || (!allowZero && t.Sign() == 0) {
+		return nil, ErrTweakOutOfRange
+	}
+	return t, nil
+}
+
+// privKeyFromScalar returns the private key d, which must be in [1, N-1].
+func privKeyFromScalar(d *big.Int) (*PrivateKey, error) {
+	if d.Sign() == 0 {
+		return nil, ErrTweakInvalidResult
+	}
+	priv, _ := PrivKeyFromBytes(S256(), paddedAppend(32, nil, d.Bytes()))
+	return priv, nil
+}
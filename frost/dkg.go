@@ -0,0 +1,231 @@
+package frost
+
+import "crypto/rand"
+import "fmt"
+import "io"
+import "math/big"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// Round1Package is what a participant broadcasts to all others in the first
+// round of the DKG: the commitment to its polynomial and a proof that it
+// knows the constant term, which stops rogue key attacks.
+type Round1Package struct {
+	ID         uint16
+	Commitment []*ec.PublicKey
+	ProofR     *ec.PublicKey
+	ProofZ     *big.Int
+}
+
+// Round2Package carries the share participant From computed for participant
+// To in the second round of the DKG.  Unlike the round one package it is
+// secret and must be sent over a confidential channel, e.g. encrypted with
+// ec.Encrypt to To's long term key.
+type Round2Package struct {
+	From  uint16
+	To    uint16
+	Share *big.Int
+}
+
+// DKGParticipant runs one participant through the Pedersen distributed key
+// generation:
+//
+//	p, r1, _ := NewDKGParticipant(id, t, n, nil)   // broadcast r1
+//	r2s, _ := p.Round2(othersRound1)               // send r2s[i] to r2s[i].To
+//	share, pub, _ := p.Finalize(sharesSentToMe)
+//
+// Every participant ends up with the same PublicKeyPackage and its own
+// KeyShare, while the group secret is never assembled anywhere.
+type DKGParticipant struct {
+	id         uint16
+	minSigners int
+	maxSigners int
+	r          io.Reader
+
+	coeffs     []*big.Int
+	commitment []*ec.PublicKey
+	round1     map[uint16]*Round1Package // 其他参与者的第一轮消息
+}
+
+// NewDKGParticipant starts the DKG for the participant with the given
+// non-zero identifier and returns its round one package.  crypto/rand is
+// used if r is nil.
+func NewDKGParticipant(id uint16, minSigners, maxSigners int, r io.Reader) (*DKGParticipant, *Round1Package, error) {
+	if minSigners < 1 || minSigners > maxSigners || maxSigners > 0xffff {
+		return nil, nil, ErrInvalidThreshold
+	}
+	if id == 0 {
+		return nil, nil, ErrInvalidIdentifier
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+
+	coeffs, err := randomPolynomial(minSigners, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &DKGParticipant{
+		id:         id,
+		minSigners: minSigners,
+		maxSigners: maxSigners,
+		r:          r,
+		coeffs:     coeffs,
+		commitment: commitPolynomial(coeffs),
+	}
+
+	// Schnorr proof of knowledge of a_0.
+	k, err := randomScalar(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofR := scalarBaseMult(k)
+	c := dkgChallenge(id, p.commitment[0], proofR)
+	z := c.Mul(c, coeffs[0])
+	z.Add(z, k)
+	z.Mod(z, ec.S256().N)
+	ec.ZeroBigInt(k)
+
+	return p, &Round1Package{
+		ID:         id,
+		Commitment: p.commitment,
+		ProofR:     proofR,
+		ProofZ:     z,
+	}, nil
+}
+
+// Round2 checks the round one packages of all other participants and returns
+// the secret share for each of them.
+func (p *DKGParticipant) Round2(packages []*Round1Package) ([]*Round2Package, error) {
+	if p.coeffs == nil {
+		return nil, fmt.Errorf("frost: DKG participant has already finished")
+	}
+	if len(packages) != p.maxSigners-1 {
+		return nil, fmt.Errorf("frost: expected %d round one packages, got %d",
+			p.maxSigners-1, len(packages))
+	}
+
+	ids := []uint16{p.id}
+	for _, pkg := range packages {
+		ids = append(ids, pkg.ID)
+	}
+	if err := checkIdentifiers(ids); err != nil {
+		return nil, err
+	}
+
+	p.round1 = make(map[uint16]*Round1Package, len(packages))
+	out := make([]*Round2Package, 0, len(packages))
+	for _, pkg := range packages {
+		if len(pkg.Commitment) != p.minSigners {
+			return nil, fmt.Errorf("frost: participant %d committed to %d coefficients, expected %d",
+				pkg.ID, len(pkg.Commitment), p.minSigners)
+		}
+		if !pkg.verifyProof() {
+			return nil, fmt.Errorf("%w: participant %d", ErrInvalidProof, pkg.ID)
+		}
+		p.round1[pkg.ID] = pkg
+		out = append(out, &Round2Package{
+			From:  p.id,
+			To:    pkg.ID,
+			Share: evalPolynomial(p.coeffs, pkg.ID),
+		})
+	}
+	return out, nil
+}
+
+// Finalize checks the shares the other participants sent in round two and
+// derives this participant's key share and the public key package.
+func (p *DKGParticipant) Finalize(packages []*Round2Package) (*KeyShare, *PublicKeyPackage, error) {
+	if p.round1 == nil {
+		return nil, nil, fmt.Errorf("frost: DKG round two has not run")
+	}
+	if len(packages) != len(p.round1) {
+		return nil, nil, fmt.Errorf("frost: expected %d round two packages, got %d",
+			len(p.round1), len(packages))
+	}
+
+	n := ec.S256().N
+	secret := evalPolynomial(p.coeffs, p.id)
+	seen := make(map[uint16]bool, len(packages))
+	for _, pkg := range packages {
+		sender, ok := p.round1[pkg.From]
+		if !ok || pkg.To != p.id || seen[pkg.From] {
+			return nil, nil, fmt.Errorf("frost: unexpected round two package from %d to %d", pkg.From, pkg.To)
+		}
+		seen[pkg.From] = true
+		if pkg.Share.Cmp(n) >= 0 ||
+			!pointEqual(scalarBaseMult(pkg.Share), evalCommitment(sender.Commitment, p.id)) {
+			return nil, nil, fmt.Errorf("%w: participant %d", ErrInvalidShare, pkg.From)
+		}
+		secret.Add(secret, pkg.Share)
+	}
+	secret.Mod(secret, n)
+
+	commitments := [][]*ec.PublicKey{p.commitment}
+	ids := []uint16{p.id}
+	for id, pkg := range p.round1 {
+		commitments = append(commitments, pkg.Commitment)
+		ids = append(ids, id)
+	}
+
+	curve := ec.S256()
+	gx, gy := new(big.Int), new(big.Int)
+	for _, c := range commitments {
+		gx, gy = curve.Add(gx, gy, c[0].X, c[0].Y)
+	}
+	pub := &PublicKeyPackage{
+		MinSigners:         p.minSigners,
+		GroupKey:           &ec.PublicKey{Curve: curve, X: gx, Y: gy},
+		VerificationShares: make(map[uint16]*ec.PublicKey, len(ids)),
+	}
+	if isInfinity(pub.GroupKey) {
+		return nil, nil, fmt.Errorf("frost: group public key is the point at infinity")
+	}
+	for _, id := range ids {
+		x, y := new(big.Int), new(big.Int)
+		for _, c := range commitments {
+			v := evalCommitment(c, id)
+			x, y = curve.Add(x, y, v.X, v.Y)
+		}
+		pub.VerificationShares[id] = &ec.PublicKey{Curve: curve, X: x, Y: y}
+	}
+
+	share := &KeyShare{
+		ID:         p.id,
+		MinSigners: p.minSigners,
+		Secret:     secret,
+		PublicKey:  scalarBaseMult(secret),
+		GroupKey:   pub.GroupKey,
+	}
+	if !pointEqual(share.PublicKey, pub.VerificationShares[p.id]) {
+		return nil, nil, ErrInvalidShare
+	}
+
+	for _, c := range p.coeffs {
+		ec.ZeroBigInt(c)
+	}
+	p.coeffs = nil
+	return share, pub, nil
+}
+
+// verifyProof checks the proof of knowledge of the constant term.
+func (pkg *Round1Package) verifyProof() bool {
+	curve := ec.S256()
+	if pkg.ProofZ.Cmp(curve.N) >= 0 || len(pkg.Commitment) == 0 {
+		return false
+	}
+	// R == z*G - c*A_0
+	c := dkgChallenge(pkg.ID, pkg.Commitment[0], pkg.ProofR)
+	c.Sub(curve.N, c)
+	zx, zy := curve.ScalarBaseMult(pkg.ProofZ.FillBytes(make([]byte, 32)))
+	cx, cy := curve.ScalarMult(pkg.Commitment[0].X, pkg.Commitment[0].Y, c.Bytes())
+	x, y := curve.Add(zx, zy, cx, cy)
+	return x.Cmp(pkg.ProofR.X) == 0 && y.Cmp(pkg.ProofR.Y) == 0
+}
+
+func dkgChallenge(id uint16, a0, r *ec.PublicKey) *big.Int {
+	c := new(big.Int).SetBytes(ec.TaggedHash(tagDKGProof,
+		serializeID(id), a0.SerializeCompressed(), r.SerializeCompressed()))
+	return c.Mod(c, ec.S256().N)
+}
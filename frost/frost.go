@@ -0,0 +1,265 @@
+// FROST 门限 Schnorr 签名: n 个参与者里任意 t 个就能合作生成一个 BIP340
+// 签名, 少于 t 个什么也做不了.
+//
+// The scheme follows the two-round FROST protocol of RFC 9591, adapted so
+// that the final signature is a plain BIP340 signature: the group public key
+// and the group commitment are used in their x-only form, so signers negate
+// their secret share when the group key has an odd Y and their nonces when
+// the group commitment has an odd Y.
+//
+// Key material is either split by a trusted dealer (TrustedDealerKeygen) or
+// generated jointly by the participants with the Pedersen DKG of the FROST
+// paper (DKGParticipant), where nobody ever learns the group secret.  Every
+// message exchanged between participants is a struct with a Serialize method
+// and a matching Parse function, so the rounds can run over any transport.
+//
+// https://www.rfc-editor.org/rfc/rfc9591
+// https://eprint.iacr.org/2020/852
+
+package frost
+
+import "crypto/rand"
+import "encoding/binary"
+import "fmt"
+import "io"
+import "math/big"
+import "sort"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+var (
+	ErrInvalidThreshold  = fmt.Errorf("frost: threshold must be between 1 and the number of participants")
+	ErrInvalidIdentifier = fmt.Errorf("frost: participant identifiers must be non-zero and unique")
+	ErrInvalidShare      = fmt.Errorf("frost: secret share does not match its commitment")
+	ErrInvalidProof      = fmt.Errorf("frost: proof of knowledge is invalid")
+	ErrNotEnoughSigners  = fmt.Errorf("frost: fewer signers than the threshold")
+	ErrNonceUsed         = fmt.Errorf("frost: signing nonces have already been used")
+	ErrNonceMismatch     = fmt.Errorf("frost: signing nonces do not match the commitment in the signing package")
+	ErrInvalidSigShare   = fmt.Errorf("frost: signature share is invalid")
+	ErrMissingSigShare   = fmt.Errorf("frost: signature share is missing")
+	ErrUnknownSigner     = fmt.Errorf("frost: signer is not part of the key")
+)
+
+// Domain separation tags of the hashes used by the protocol.
+const (
+	tagNonce      = "FROST/secp256k1/nonce"
+	tagRho        = "FROST/secp256k1/rho"
+	tagMsg        = "FROST/secp256k1/msg"
+	tagCommitList = "FROST/secp256k1/com"
+	tagDKGProof   = "FROST/secp256k1/dkg"
+)
+
+// SecretShare is a participant's share of the group secret as handed out by
+// a trusted dealer, together with the commitment to the dealer's polynomial
+// that lets the participant check it.  It must be sent over a confidential
+// channel.
+type SecretShare struct {
+	ID         uint16
+	Value      *big.Int
+	Commitment []*ec.PublicKey // 多项式系数的承诺 a_0*G ... a_{t-1}*G
+}
+
+// KeyShare is everything a participant needs to sign: its secret share and
+// the group public key.
+type KeyShare struct {
+	ID         uint16
+	MinSigners int
+	Secret     *big.Int
+	PublicKey  *ec.PublicKey // Secret*G, the participant's verification share
+	GroupKey   *ec.PublicKey
+}
+
+// PublicKeyPackage is the public information about a key: the group public
+// key and the verification share of every participant, which is what the
+// coordinator needs to check signature shares.
+type PublicKeyPackage struct {
+	MinSigners         int
+	GroupKey           *ec.PublicKey
+	VerificationShares map[uint16]*ec.PublicKey
+}
+
+// TrustedDealerKeygen splits secret into maxSigners shares of which any
+// minSigners can sign, using identifiers 1 to maxSigners.  A random secret
+// is used if secret is nil, and crypto/rand if r is nil.
+func TrustedDealerKeygen(secret *ec.PrivateKey, minSigners, maxSigners int,
+	r io.Reader) ([]*SecretShare, *PublicKeyPackage, error) {
+	if minSigners < 1 || minSigners > maxSigners || maxSigners > 0xffff {
+		return nil, nil, ErrInvalidThreshold
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+
+	coeffs, err := randomPolynomial(minSigners, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret != nil {
+		coeffs[0].Set(secret.D)
+	}
+	commitment := commitPolynomial(coeffs)
+
+	shares := make([]*SecretShare, maxSigners)
+	pub := &PublicKeyPackage{
+		MinSigners:         minSigners,
+		GroupKey:           commitment[0],
+		VerificationShares: make(map[uint16]*ec.PublicKey, maxSigners),
+	}
+	for i := range shares {
+		id := uint16(i + 1)
+		value := evalPolynomial(coeffs, id)
+		shares[i] = &SecretShare{ID: id, Value: value, Commitment: commitment}
+		pub.VerificationShares[id] = scalarBaseMult(value)
+	}
+	for _, c := range coeffs {
+		ec.ZeroBigInt(c)
+	}
+	return shares, pub, nil
+}
+
+// Verify checks the share against the dealer's commitment and returns the
+// resulting key share.
+func (s *SecretShare) Verify() (*KeyShare, error) {
+	if s.ID == 0 || len(s.Commitment) == 0 {
+		return nil, ErrInvalidIdentifier
+	}
+	if s.Value.Sign() <= 0 || s.Value.Cmp(ec.S256().N) >= 0 {
+		return nil, ErrInvalidShare
+	}
+	pk := scalarBaseMult(s.Value)
+	if !pointEqual(pk, evalCommitment(s.Commitment, s.ID)) {
+		return nil, ErrInvalidShare
+	}
+	return &KeyShare{
+		ID:         s.ID,
+		MinSigners: len(s.Commitment),
+		Secret:     new(big.Int).Set(s.Value),
+		PublicKey:  pk,
+		GroupKey:   s.Commitment[0],
+	}, nil
+}
+
+// randomScalar returns a uniformly random scalar in [1, N-1].
+func randomScalar(r io.Reader) (*big.Int, error) {
+	n := ec.S256().N
+	var b [32]byte
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(b[:])
+		if k.Sign() > 0 && k.Cmp(n) < 0 {
+			return k, nil
+		}
+	}
+}
+
+// randomPolynomial returns the coefficients of a random polynomial of degree
+// t-1, constant term first.
+func randomPolynomial(t int, r io.Reader) ([]*big.Int, error) {
+	coeffs := make([]*big.Int, t)
+	for i := range coeffs {
+		c, err := randomScalar(r)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = c
+	}
+	return coeffs, nil
+}
+
+// evalPolynomial evaluates the polynomial at x with Horner's method.
+func evalPolynomial(coeffs []*big.Int, x uint16) *big.Int {
+	n := ec.S256().N
+	bx := big.NewInt(int64(x))
+	y := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y.Mul(y, bx)
+		y.Add(y, coeffs[i])
+		y.Mod(y, n)
+	}
+	return y
+}
+
+// commitPolynomial returns the commitment a_i*G to every coefficient.
+func commitPolynomial(coeffs []*big.Int) []*ec.PublicKey {
+	commitment := make([]*ec.PublicKey, len(coeffs))
+	for i, c := range coeffs {
+		commitment[i] = scalarBaseMult(c)
+	}
+	return commitment
+}
+
+// evalCommitment evaluates the committed polynomial at x "in the exponent",
+// giving f(x)*G without knowing f.
+func evalCommitment(commitment []*ec.PublicKey, x uint16) *ec.PublicKey {
+	curve := ec.S256()
+	bx := big.NewInt(int64(x)).Bytes()
+	yx, yy := new(big.Int), new(big.Int)
+	for i := len(commitment) - 1; i >= 0; i-- {
+		yx, yy = curve.ScalarMult(yx, yy, bx)
+		yx, yy = curve.Add(yx, yy, commitment[i].X, commitment[i].Y)
+	}
+	return &ec.PublicKey{Curve: curve, X: yx, Y: yy}
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient of id at 0 over the
+// given signer set.
+func lagrangeCoefficient(id uint16, ids []uint16) *big.Int {
+	n := ec.S256().N
+	num, den := big.NewInt(1), big.NewInt(1)
+	for _, j := range ids {
+		if j == id {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j)))
+		num.Mod(num, n)
+		d := big.NewInt(int64(j) - int64(id))
+		den.Mul(den, d)
+		den.Mod(den, n)
+	}
+	den.ModInverse(den, n)
+	return num.Mul(num, den).Mod(num, n)
+}
+
+// checkIdentifiers checks that ids are non-zero and unique.
+func checkIdentifiers(ids []uint16) error {
+	sorted := append([]uint16{}, ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, id := range sorted {
+		if id == 0 || (i > 0 && sorted[i-1] == id) {
+			return ErrInvalidIdentifier
+		}
+	}
+	return nil
+}
+
+func scalarBaseMult(k *big.Int) *ec.PublicKey {
+	curve := ec.S256()
+	x, y := curve.ScalarBaseMult(k.FillBytes(make([]byte, 32)))
+	return &ec.PublicKey{Curve: curve, X: x, Y: y}
+}
+
+func pointEqual(a, b *ec.PublicKey) bool {
+	return a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+}
+
+func isInfinity(p *ec.PublicKey) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+// negatePoint returns -p.
+func negatePoint(p *ec.PublicKey) *ec.PublicKey {
+	curve := ec.S256()
+	if isInfinity(p) {
+		return p
+	}
+	return &ec.PublicKey{Curve: curve, X: p.X, Y: new(big.Int).Sub(curve.P, p.Y)}
+}
+
+func serializeID(id uint16) []byte {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], id)
+	return b[:]
+}
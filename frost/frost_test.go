@@ -0,0 +1,290 @@
+package frost
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+// runDKG runs the distributed key generation between maxSigners participants,
+// passing every message through its serialized form.
+func runDKG(t *testing.T, minSigners, maxSigners int) ([]*KeyShare, *PublicKeyPackage) {
+	participants := make([]*DKGParticipant, maxSigners)
+	round1 := make([][]byte, maxSigners)
+	for i := range participants {
+		p, pkg, err := NewDKGParticipant(uint16(i+1), minSigners, maxSigners, nil)
+		if err != nil {
+			t.Fatalf("NewDKGParticipant: %v", err)
+		}
+		participants[i] = p
+		round1[i] = pkg.Serialize()
+	}
+
+	round2 := make(map[uint16][][]byte)
+	for i, p := range participants {
+		var others []*Round1Package
+		for j, b := range round1 {
+			if j == i {
+				continue
+			}
+			pkg, err := ParseRound1Package(b)
+			if err != nil {
+				t.Fatalf("ParseRound1Package: %v", err)
+			}
+			others = append(others, pkg)
+		}
+		out, err := p.Round2(others)
+		if err != nil {
+			t.Fatalf("Round2: %v", err)
+		}
+		for _, pkg := range out {
+			round2[pkg.To] = append(round2[pkg.To], pkg.Serialize())
+		}
+	}
+
+	shares := make([]*KeyShare, maxSigners)
+	var pub *PublicKeyPackage
+	for i, p := range participants {
+		var mine []*Round2Package
+		for _, b := range round2[uint16(i+1)] {
+			pkg, err := ParseRound2Package(b)
+			if err != nil {
+				t.Fatalf("ParseRound2Package: %v", err)
+			}
+			mine = append(mine, pkg)
+		}
+		share, p2, err := p.Finalize(mine)
+		if err != nil {
+			t.Fatalf("Finalize: %v", err)
+		}
+		if pub != nil && !bytes.Equal(pub.Serialize(), p2.Serialize()) {
+			t.Fatalf("participants disagree on the public key package")
+		}
+		shares[i], pub = share, p2
+	}
+	return shares, pub
+}
+
+// runSigning signs msg with the given subset of shares, passing every message
+// through its serialized form.
+func runSigning(t *testing.T, shares []*KeyShare, pub *PublicKeyPackage, msg []byte) (*ec.SchnorrSignature, error) {
+	nonces := make([]*SigningNonces, len(shares))
+	var commitments []*SigningCommitment
+	for i, share := range shares {
+		n, c, err := Commit(share, nil)
+		if err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+		nonces[i] = n
+		parsed, err := ParseSigningCommitment(c.Serialize())
+		if err != nil {
+			t.Fatalf("ParseSigningCommitment: %v", err)
+		}
+		commitments = append(commitments, parsed)
+	}
+	pkg, err := NewSigningPackage(commitments, msg)
+	if err != nil {
+		t.Fatalf("NewSigningPackage: %v", err)
+	}
+	pkgBytes := pkg.Serialize()
+
+	var sigShares []*SignatureShare
+	for i, share := range shares {
+		received, err := ParseSigningPackage(pkgBytes)
+		if err != nil {
+			t.Fatalf("ParseSigningPackage: %v", err)
+		}
+		s, err := Sign(received, nonces[i], share)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseSignatureShare(s.Serialize())
+		if err != nil {
+			t.Fatalf("ParseSignatureShare: %v", err)
+		}
+		sigShares = append(sigShares, parsed)
+	}
+	return Aggregate(pkg, sigShares, pub)
+}
+
+func TestTrustedDealer(t *testing.T) {
+	seed := sha256.Sum256([]byte("frost dealer"))
+	secret, secretPub := ec.PrivKeyFromBytes(ec.S256(), seed[:])
+	dealt, pub, err := TrustedDealerKeygen(secret, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("TrustedDealerKeygen: %v", err)
+	}
+	if !pointEqual(pub.GroupKey, secretPub) {
+		t.Fatalf("group key is not the public key of the secret")
+	}
+
+	var shares []*KeyShare
+	for _, s := range dealt {
+		parsed, err := ParseSecretShare(s.Serialize())
+		if err != nil {
+			t.Fatalf("ParseSecretShare: %v", err)
+		}
+		share, err := parsed.Verify()
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		shares = append(shares, share)
+	}
+
+	bad := *dealt[0]
+	bad.Value = new(big.Int).Add(dealt[0].Value, big.NewInt(1))
+	if _, err := bad.Verify(); err != ErrInvalidShare {
+		t.Errorf("tampered share: got %v, want %v", err, ErrInvalidShare)
+	}
+
+	msg := sha256.Sum256([]byte("dealer message"))
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var signers []*KeyShare
+		for _, i := range subset {
+			signers = append(signers, shares[i])
+		}
+		sig, err := runSigning(t, signers, pub, msg[:])
+		if err != nil {
+			t.Fatalf("signing with %v: %v", subset, err)
+		}
+		if !sig.Verify(msg[:], secretPub) {
+			t.Errorf("signature of %v does not verify", subset)
+		}
+	}
+
+	if _, err := runSigning(t, shares[:2], pub, msg[:]); err != ErrNotEnoughSigners {
+		t.Errorf("signing below threshold: got %v, want %v", err, ErrNotEnoughSigners)
+	}
+}
+
+func TestDKG(t *testing.T) {
+	shares, pub := runDKG(t, 2, 3)
+	msg := []byte("any length message")
+	for _, subset := range [][]int{{0, 1}, {1, 2}, {0, 2}} {
+		sig, err := runSigning(t, []*KeyShare{shares[subset[0]], shares[subset[1]]}, pub, msg)
+		if err != nil {
+			t.Fatalf("signing with %v: %v", subset, err)
+		}
+		parsed, err := ec.ParseSchnorrSignature(sig.Serialize())
+		if err != nil {
+			t.Fatalf("ParseSchnorrSignature: %v", err)
+		}
+		if !parsed.Verify(msg, pub.GroupKey) {
+			t.Errorf("signature of %v does not verify", subset)
+		}
+	}
+
+	parsed, err := ParsePublicKeyPackage(pub.Serialize())
+	if err != nil || !bytes.Equal(parsed.Serialize(), pub.Serialize()) {
+		t.Errorf("public key package did not round trip: %v", err)
+	}
+	for _, share := range shares {
+		parsed, err := ParseKeyShare(share.Serialize())
+		if err != nil || !pointEqual(parsed.PublicKey, pub.VerificationShares[share.ID]) {
+			t.Errorf("key share did not round trip: %v", err)
+		}
+	}
+}
+
+func TestDKGRejectsBadProof(t *testing.T) {
+	p1, _, _ := NewDKGParticipant(1, 2, 3, nil)
+	_, r2, _ := NewDKGParticipant(2, 2, 3, nil)
+	_, r3, _ := NewDKGParticipant(3, 2, 3, nil)
+
+	forged := *r3
+	forged.Commitment = append([]*ec.PublicKey{r2.Commitment[0]}, r3.Commitment[1:]...)
+	if _, err := p1.Round2([]*Round1Package{r2, &forged}); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("commitment without a valid proof of knowledge: got %v, want %v", err, ErrInvalidProof)
+	}
+	if _, err := p1.Round2([]*Round1Package{r2, r2}); err != ErrInvalidIdentifier {
+		t.Errorf("duplicate participant: got %v, want %v", err, ErrInvalidIdentifier)
+	}
+}
+
+func TestDKGRejectsBadShare(t *testing.T) {
+	p1, r1, _ := NewDKGParticipant(1, 2, 3, nil)
+	p2, r2, _ := NewDKGParticipant(2, 2, 3, nil)
+	_, r3, _ := NewDKGParticipant(3, 2, 3, nil)
+	if _, err := p1.Round2([]*Round1Package{r2, r3}); err != nil {
+		t.Fatalf("Round2: %v", err)
+	}
+	out, err := p2.Round2([]*Round1Package{r1, r3})
+	if err != nil {
+		t.Fatalf("Round2: %v", err)
+	}
+	toP1 := out[0]
+	toP1.Share.Add(toP1.Share, big.NewInt(1))
+	fromP3 := &Round2Package{From: 3, To: 1, Share: big.NewInt(1)}
+	if _, _, err := p1.Finalize([]*Round2Package{toP1, fromP3}); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("share that does not match its commitment: got %v, want %v", err, ErrInvalidShare)
+	}
+}
+
+func TestSignatureShareBlame(t *testing.T) {
+	dealt, pub, _ := TrustedDealerKeygen(nil, 2, 3, nil)
+	s1, _ := dealt[0].Verify()
+	s3, _ := dealt[2].Verify()
+	msg := []byte("blame")
+
+	n1, c1, _ := Commit(s1, nil)
+	n3, c3, _ := Commit(s3, nil)
+	pkg, err := NewSigningPackage([]*SigningCommitment{c3, c1}, msg)
+	if err != nil {
+		t.Fatalf("NewSigningPackage: %v", err)
+	}
+	z1, err := Sign(pkg, n1, s1)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := Sign(pkg, n1, s1); err != ErrNonceUsed {
+		t.Errorf("reused nonces: got %v, want %v", err, ErrNonceUsed)
+	}
+	if _, err := Sign(pkg, n3, s1); err != ErrNonceMismatch {
+		t.Errorf("foreign nonces: got %v, want %v", err, ErrNonceMismatch)
+	}
+	if err := pub.VerifySignatureShare(pkg, z1); err != nil {
+		t.Errorf("VerifySignatureShare: %v", err)
+	}
+	bad := &SignatureShare{ID: 1, Z: new(big.Int).Add(z1.Z, big.NewInt(1))}
+	if err := pub.VerifySignatureShare(pkg, bad); !errors.Is(err, ErrInvalidSigShare) {
+		t.Errorf("corrupted share: got %v, want %v", err, ErrInvalidSigShare)
+	}
+	stranger := &SignatureShare{ID: 2, Z: z1.Z}
+	if err := pub.VerifySignatureShare(pkg, stranger); !errors.Is(err, ErrUnknownSigner) {
+		t.Errorf("share of a signer outside the package: got %v, want %v", err, ErrUnknownSigner)
+	}
+	if _, err := Aggregate(pkg, []*SignatureShare{z1}, pub); !errors.Is(err, ErrMissingSigShare) {
+		t.Errorf("missing share: got %v, want %v", err, ErrMissingSigShare)
+	}
+	zero := &SignatureShare{ID: 3, Z: new(big.Int)}
+	if _, err := Aggregate(pkg, []*SignatureShare{bad, zero}, pub); !errors.Is(err, ErrInvalidSigShare) {
+		t.Errorf("Aggregate of corrupted shares: got %v, want %v", err, ErrInvalidSigShare)
+	}
+
+	_, c3, _ = Commit(s3, nil)
+	n3, _, _ = Commit(s3, nil)
+	pkg, _ = NewSigningPackage([]*SigningCommitment{c1, c3}, msg)
+	if _, err := Sign(pkg, n3, s3); err != ErrNonceMismatch {
+		t.Errorf("nonces not in the package: got %v, want %v", err, ErrNonceMismatch)
+	}
+}
+
+func TestParseRejectsMalformed(t *testing.T) {
+	dealt, _, _ := TrustedDealerKeygen(nil, 2, 2, nil)
+	share, _ := dealt[0].Verify()
+	_, c, _ := Commit(share, nil)
+	b := c.Serialize()
+	if _, err := ParseSigningCommitment(b[:len(b)-1]); err == nil {
+		t.Errorf("accepted a truncated commitment")
+	}
+	if _, err := ParseSigningCommitment(append(b, 0)); err == nil {
+		t.Errorf("accepted a commitment with trailing data")
+	}
+	if _, err := ParseSignatureShare(bytes.Repeat([]byte{0xff}, 34)); err == nil {
+		t.Errorf("accepted an out of range scalar")
+	}
+}
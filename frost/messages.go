@@ -0,0 +1,277 @@
+package frost
+
+import "encoding/binary"
+import "fmt"
+import "math/big"
+import "sort"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// Every message is serialized as fixed size big endian fields: identifiers
+// and counts take 2 bytes, scalars 32 bytes and points 33 bytes in
+// compressed form.
+
+var errMessageLength = fmt.Errorf("frost: malformed message: invalid length")
+
+// Serialize encodes the package as ID || count || commitments || R || z.
+func (pkg *Round1Package) Serialize() []byte {
+	b := append(serializeID(pkg.ID), serializeID(uint16(len(pkg.Commitment)))...)
+	b = appendPoints(b, pkg.Commitment)
+	b = append(b, pkg.ProofR.SerializeCompressed()...)
+	return append(b, pkg.ProofZ.FillBytes(make([]byte, 32))...)
+}
+
+// ParseRound1Package decodes a package encoded by Round1Package.Serialize.
+func ParseRound1Package(b []byte) (*Round1Package, error) {
+	d := decoder{b: b}
+	pkg := &Round1Package{ID: d.uint16()}
+	pkg.Commitment = d.points(int(d.uint16()))
+	pkg.ProofR = d.point()
+	pkg.ProofZ = d.scalar()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Serialize encodes the package as From || To || share.
+func (pkg *Round2Package) Serialize() []byte {
+	b := append(serializeID(pkg.From), serializeID(pkg.To)...)
+	return append(b, pkg.Share.FillBytes(make([]byte, 32))...)
+}
+
+// ParseRound2Package decodes a package encoded by Round2Package.Serialize.
+func ParseRound2Package(b []byte) (*Round2Package, error) {
+	d := decoder{b: b}
+	pkg := &Round2Package{From: d.uint16(), To: d.uint16(), Share: d.scalar()}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Serialize encodes the share as ID || value || count || commitment.
+func (s *SecretShare) Serialize() []byte {
+	b := append(serializeID(s.ID), s.Value.FillBytes(make([]byte, 32))...)
+	b = append(b, serializeID(uint16(len(s.Commitment)))...)
+	return appendPoints(b, s.Commitment)
+}
+
+// ParseSecretShare decodes a share encoded by SecretShare.Serialize.  The
+// result still has to be checked with Verify.
+func ParseSecretShare(b []byte) (*SecretShare, error) {
+	d := decoder{b: b}
+	s := &SecretShare{ID: d.uint16(), Value: d.scalar()}
+	s.Commitment = d.points(int(d.uint16()))
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Serialize encodes the key share as ID || min signers || secret || group
+// key.  The result is secret.
+func (s *KeyShare) Serialize() []byte {
+	b := append(serializeID(s.ID), serializeID(uint16(s.MinSigners))...)
+	b = append(b, s.Secret.FillBytes(make([]byte, 32))...)
+	return append(b, s.GroupKey.SerializeCompressed()...)
+}
+
+// ParseKeyShare decodes a key share encoded by KeyShare.Serialize.
+func ParseKeyShare(b []byte) (*KeyShare, error) {
+	d := decoder{b: b}
+	s := &KeyShare{ID: d.uint16(), MinSigners: int(d.uint16()), Secret: d.scalar(), GroupKey: d.point()}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	if s.ID == 0 || s.Secret.Sign() == 0 {
+		return nil, fmt.Errorf("frost: malformed key share")
+	}
+	s.PublicKey = scalarBaseMult(s.Secret)
+	return s, nil
+}
+
+// Serialize encodes the package as min signers || group key || count ||
+// (ID || verification share)... sorted by ID.
+func (p *PublicKeyPackage) Serialize() []byte {
+	ids := make([]int, 0, len(p.VerificationShares))
+	for id := range p.VerificationShares {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	b := append(serializeID(uint16(p.MinSigners)), p.GroupKey.SerializeCompressed()...)
+	b = append(b, serializeID(uint16(len(ids)))...)
+	for _, id := range ids {
+		b = append(b, serializeID(uint16(id))...)
+		b = append(b, p.VerificationShares[uint16(id)].SerializeCompressed()...)
+	}
+	return b
+}
+
+// ParsePublicKeyPackage decodes a package encoded by
+// PublicKeyPackage.Serialize.
+func ParsePublicKeyPackage(b []byte) (*PublicKeyPackage, error) {
+	d := decoder{b: b}
+	p := &PublicKeyPackage{MinSigners: int(d.uint16()), GroupKey: d.point()}
+	count := int(d.uint16())
+	p.VerificationShares = make(map[uint16]*ec.PublicKey, count)
+	for i := 0; i < count && d.err == nil; i++ {
+		id := d.uint16()
+		if _, dup := p.VerificationShares[id]; dup || id == 0 {
+			return nil, ErrInvalidIdentifier
+		}
+		p.VerificationShares[id] = d.point()
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Serialize encodes the commitment as ID || hiding || binding.
+func (c *SigningCommitment) Serialize() []byte {
+	b := append(serializeID(c.ID), c.Hiding.SerializeCompressed()...)
+	return append(b, c.Binding.SerializeCompressed()...)
+}
+
+// ParseSigningCommitment decodes a commitment encoded by
+// SigningCommitment.Serialize.
+func ParseSigningCommitment(b []byte) (*SigningCommitment, error) {
+	d := decoder{b: b}
+	c := &SigningCommitment{ID: d.uint16(), Hiding: d.point(), Binding: d.point()}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Serialize encodes the package as message length (4 bytes) || message ||
+// count || commitments.
+func (pkg *SigningPackage) Serialize() []byte {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(pkg.Message)))
+	b := append(l[:], pkg.Message...)
+	b = append(b, serializeID(uint16(len(pkg.Commitments)))...)
+	for _, c := range pkg.Commitments {
+		b = append(b, c.Serialize()...)
+	}
+	return b
+}
+
+// ParseSigningPackage decodes a package encoded by SigningPackage.Serialize.
+func ParseSigningPackage(b []byte) (*SigningPackage, error) {
+	d := decoder{b: b}
+	pkg := &SigningPackage{Message: d.bytes(int(d.uint32()))}
+	count := int(d.uint16())
+	for i := 0; i < count && d.err == nil; i++ {
+		pkg.Commitments = append(pkg.Commitments,
+			&SigningCommitment{ID: d.uint16(), Hiding: d.point(), Binding: d.point()})
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	if err := pkg.check(); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Serialize encodes the share as ID || z.
+func (s *SignatureShare) Serialize() []byte {
+	return append(serializeID(s.ID), s.Z.FillBytes(make([]byte, 32))...)
+}
+
+// ParseSignatureShare decodes a share encoded by SignatureShare.Serialize.
+func ParseSignatureShare(b []byte) (*SignatureShare, error) {
+	d := decoder{b: b}
+	s := &SignatureShare{ID: d.uint16(), Z: d.scalar()}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func appendPoints(b []byte, points []*ec.PublicKey) []byte {
+	for _, p := range points {
+		b = append(b, p.SerializeCompressed()...)
+	}
+	return b
+}
+
+// decoder reads fixed size fields from b and remembers the first error, so
+// that a message can be decoded without checking every field.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) < n {
+		d.err = errMessageLength
+		return nil
+	}
+	v := append([]byte{}, d.b[:n]...)
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) uint16() uint16 {
+	b := d.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (d *decoder) scalar() *big.Int {
+	b := d.bytes(32)
+	if b == nil {
+		return new(big.Int)
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(ec.S256().N) >= 0 && d.err == nil {
+		d.err = fmt.Errorf("frost: malformed message: scalar out of range")
+	}
+	return k
+}
+
+func (d *decoder) point() *ec.PublicKey {
+	b := d.bytes(33)
+	if b == nil {
+		return nil
+	}
+	p, err := ec.ParsePubKey(b, ec.S256())
+	if err != nil {
+		d.err = fmt.Errorf("frost: malformed message: %v", err)
+		return nil
+	}
+	return p
+}
+
+func (d *decoder) points(n int) []*ec.PublicKey {
+	points := make([]*ec.PublicKey, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		points = append(points, d.point())
+	}
+	return points
+}
+
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.b) != 0 {
+		d.err = errMessageLength
+	}
+	return d.err
+}
@@ -0,0 +1,312 @@
+package frost
+
+import "crypto/rand"
+import "fmt"
+import "io"
+import "math/big"
+import "sort"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// SigningCommitment is the public commitment to a signer's nonces that it
+// sends to the coordinator in the first signing round.
+type SigningCommitment struct {
+	ID      uint16
+	Hiding  *ec.PublicKey
+	Binding *ec.PublicKey
+}
+
+// SigningNonces are the secret nonces behind a SigningCommitment.  They never
+// leave the signer and can be used for one signature only, Sign wipes them.
+type SigningNonces struct {
+	hiding     *big.Int
+	binding    *big.Int
+	commitment *SigningCommitment
+}
+
+// SigningPackage is what the coordinator sends to the chosen signers in the
+// second round: the message and the commitments of every signer, sorted by
+// identifier.
+type SigningPackage struct {
+	Commitments []*SigningCommitment
+	Message     []byte
+}
+
+// SignatureShare is a signer's contribution to the final signature, sent back
+// to the coordinator in the second round.
+type SignatureShare struct {
+	ID uint16
+	Z  *big.Int
+}
+
+// Commit generates fresh nonces for the holder of share along with their
+// commitment.  The nonces mix the secret share with the randomness from r,
+// or crypto/rand if r is nil, so a weak random number generator alone does
+// not leak the share.
+func Commit(share *KeyShare, r io.Reader) (*SigningNonces, *SigningCommitment, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	hiding, err := generateNonce(share.Secret, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	binding, err := generateNonce(share.Secret, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	commitment := &SigningCommitment{
+		ID:      share.ID,
+		Hiding:  scalarBaseMult(hiding),
+		Binding: scalarBaseMult(binding),
+	}
+	return &SigningNonces{hiding: hiding, binding: binding, commitment: commitment}, commitment, nil
+}
+
+// Commitment returns the public commitment to the nonces.
+func (n *SigningNonces) Commitment() *SigningCommitment {
+	return n.commitment
+}
+
+// NewSigningPackage builds the signing package for msg from the commitments
+// of the participating signers.
+func NewSigningPackage(commitments []*SigningCommitment, msg []byte) (*SigningPackage, error) {
+	sorted := make([]*SigningCommitment, len(commitments))
+	copy(sorted, commitments)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	pkg := &SigningPackage{Commitments: sorted, Message: append([]byte{}, msg...)}
+	if err := pkg.check(); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Sign produces the signature share of the holder of share.  nonces must be
+// the ones whose commitment is in pkg, and are wiped before Sign returns.
+func Sign(pkg *SigningPackage, nonces *SigningNonces, share *KeyShare) (*SignatureShare, error) {
+	if nonces.hiding == nil {
+		return nil, ErrNonceUsed
+	}
+	d, e := nonces.hiding, nonces.binding
+	nonces.hiding, nonces.binding = nil, nil
+	defer func() {
+		ec.ZeroBigInt(d)
+		ec.ZeroBigInt(e)
+	}()
+
+	if len(pkg.Commitments) < share.MinSigners {
+		return nil, ErrNotEnoughSigners
+	}
+	v, err := computeSigningValues(pkg, share.GroupKey)
+	if err != nil {
+		return nil, err
+	}
+	own := pkg.commitment(share.ID)
+	if own == nil || !pointEqual(own.Hiding, nonces.commitment.Hiding) ||
+		!pointEqual(own.Binding, nonces.commitment.Binding) {
+		return nil, ErrNonceMismatch
+	}
+
+	n := ec.S256().N
+	k := new(big.Int).Mul(e, v.rho[share.ID])
+	k.Add(k, d)
+	if v.negateR {
+		k.Neg(k)
+	}
+	s := new(big.Int).Set(share.Secret)
+	if v.negateKey {
+		s.Neg(s)
+	}
+
+	// z = k + lambda * s * c
+	z := s.Mul(s, lagrangeCoefficient(share.ID, v.ids))
+	z.Mul(z, v.c)
+	z.Add(z, k)
+	z.Mod(z, n)
+	ec.ZeroBigInt(k)
+
+	return &SignatureShare{ID: share.ID, Z: z}, nil
+}
+
+// VerifySignatureShare checks the signature share of one signer, which lets
+// the coordinator tell which signer misbehaved when aggregation fails.
+func (p *PublicKeyPackage) VerifySignatureShare(pkg *SigningPackage, sigShare *SignatureShare) error {
+	v, err := computeSigningValues(pkg, p.GroupKey)
+	if err != nil {
+		return err
+	}
+	return p.verifySignatureShare(pkg, v, sigShare)
+}
+
+func (p *PublicKeyPackage) verifySignatureShare(pkg *SigningPackage, v *signingValues,
+	sigShare *SignatureShare) error {
+	curve := ec.S256()
+	commitment := pkg.commitment(sigShare.ID)
+	if commitment == nil {
+		return fmt.Errorf("%w: signer %d", ErrUnknownSigner, sigShare.ID)
+	}
+	pk, ok := p.VerificationShares[sigShare.ID]
+	if !ok {
+		return fmt.Errorf("%w: signer %d", ErrUnknownSigner, sigShare.ID)
+	}
+	if sigShare.Z.Sign() < 0 || sigShare.Z.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("%w: signer %d", ErrInvalidSigShare, sigShare.ID)
+	}
+
+	// R_i = D_i + rho_i * E_i
+	ex, ey := curve.ScalarMult(commitment.Binding.X, commitment.Binding.Y, v.rho[sigShare.ID].Bytes())
+	rx, ry := curve.Add(commitment.Hiding.X, commitment.Hiding.Y, ex, ey)
+	ri := &ec.PublicKey{Curve: curve, X: rx, Y: ry}
+	if v.negateR {
+		ri = negatePoint(ri)
+	}
+	if v.negateKey {
+		pk = negatePoint(pk)
+	}
+
+	// z_i * G == R_i + c * lambda_i * Y_i
+	cl := new(big.Int).Mul(v.c, lagrangeCoefficient(sigShare.ID, v.ids))
+	cl.Mod(cl, curve.N)
+	yx, yy := curve.ScalarMult(pk.X, pk.Y, cl.Bytes())
+	rhsx, rhsy := curve.Add(ri.X, ri.Y, yx, yy)
+	lhsx, lhsy := curve.ScalarBaseMult(sigShare.Z.FillBytes(make([]byte, 32)))
+	if lhsx.Cmp(rhsx) != 0 || lhsy.Cmp(rhsy) != 0 {
+		return fmt.Errorf("%w: signer %d", ErrInvalidSigShare, sigShare.ID)
+	}
+	return nil
+}
+
+// Aggregate verifies the signature shares of all signers in pkg and combines
+// them into a BIP340 signature of pkg.Message under the x-only group key.
+func Aggregate(pkg *SigningPackage, sigShares []*SignatureShare, pub *PublicKeyPackage) (*ec.SchnorrSignature, error) {
+	if len(pkg.Commitments) < pub.MinSigners {
+		return nil, ErrNotEnoughSigners
+	}
+	v, err := computeSigningValues(pkg, pub.GroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint16]*SignatureShare, len(sigShares))
+	for _, s := range sigShares {
+		byID[s.ID] = s
+	}
+	n := ec.S256().N
+	z := new(big.Int)
+	for _, c := range pkg.Commitments {
+		s, ok := byID[c.ID]
+		if !ok {
+			return nil, fmt.Errorf("%w: signer %d", ErrMissingSigShare, c.ID)
+		}
+		if err := pub.verifySignatureShare(pkg, v, s); err != nil {
+			return nil, err
+		}
+		z.Add(z, s.Z)
+	}
+	z.Mod(z, n)
+
+	sig := &ec.SchnorrSignature{R: new(big.Int).Set(v.rx), S: z}
+	if !sig.Verify(pkg.Message, pub.GroupKey) {
+		return nil, fmt.Errorf("frost: aggregate signature does not verify")
+	}
+	return sig, nil
+}
+
+// signingValues are the values every signer and the coordinator derive from
+// a signing package.
+type signingValues struct {
+	ids       []uint16
+	rho       map[uint16]*big.Int // 每个签名者的 binding factor
+	rx        *big.Int            // 群承诺 R 的 X 坐标
+	negateR   bool                // R 的 Y 是奇数
+	negateKey bool                // 群公钥的 Y 是奇数
+	c         *big.Int            // BIP340 challenge
+}
+
+func computeSigningValues(pkg *SigningPackage, groupKey *ec.PublicKey) (*signingValues, error) {
+	if err := pkg.check(); err != nil {
+		return nil, err
+	}
+	curve := ec.S256()
+	n := curve.N
+
+	var encoded [][]byte
+	for _, c := range pkg.Commitments {
+		encoded = append(encoded, serializeID(c.ID),
+			c.Hiding.SerializeCompressed(), c.Binding.SerializeCompressed())
+	}
+	groupXOnly := groupKey.SerializeXOnly()
+	msgHash := ec.TaggedHash(tagMsg, pkg.Message)
+	listHash := ec.TaggedHash(tagCommitList, encoded...)
+
+	v := &signingValues{rho: make(map[uint16]*big.Int, len(pkg.Commitments))}
+	rx, ry := new(big.Int), new(big.Int)
+	for _, c := range pkg.Commitments {
+		rho := new(big.Int).SetBytes(ec.TaggedHash(tagRho,
+			groupXOnly, msgHash, listHash, serializeID(c.ID)))
+		rho.Mod(rho, n)
+		v.rho[c.ID] = rho
+		v.ids = append(v.ids, c.ID)
+
+		ex, ey := curve.ScalarMult(c.Binding.X, c.Binding.Y, rho.Bytes())
+		rx, ry = curve.Add(rx, ry, ex, ey)
+		rx, ry = curve.Add(rx, ry, c.Hiding.X, c.Hiding.Y)
+	}
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return nil, fmt.Errorf("frost: group commitment is the point at infinity")
+	}
+	v.rx = rx
+	v.negateR = ry.Bit(0) == 1
+	v.negateKey = groupKey.Y.Bit(0) == 1
+
+	// BIP340 challenge over the x-only R and group key.
+	c := new(big.Int).SetBytes(ec.TaggedHash("BIP0340/challenge",
+		rx.FillBytes(make([]byte, 32)), groupXOnly, pkg.Message))
+	v.c = c.Mod(c, n)
+	return v, nil
+}
+
+// check verifies that the commitments are sorted by identifier without
+// duplicates and are valid points.
+func (pkg *SigningPackage) check() error {
+	if len(pkg.Commitments) == 0 {
+		return ErrNotEnoughSigners
+	}
+	curve := ec.S256()
+	for i, c := range pkg.Commitments {
+		if c.ID == 0 || (i > 0 && pkg.Commitments[i-1].ID >= c.ID) {
+			return ErrInvalidIdentifier
+		}
+		if !curve.IsOnCurve(c.Hiding.X, c.Hiding.Y) || !curve.IsOnCurve(c.Binding.X, c.Binding.Y) {
+			return fmt.Errorf("frost: commitment of signer %d is not on the curve", c.ID)
+		}
+	}
+	return nil
+}
+
+func (pkg *SigningPackage) commitment(id uint16) *SigningCommitment {
+	for _, c := range pkg.Commitments {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// generateNonce derives a nonce from 32 random bytes and the secret share,
+// as nonce_generate in RFC 9591.
+func generateNonce(secret *big.Int, r io.Reader) (*big.Int, error) {
+	n := ec.S256().N
+	randBytes := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(r, randBytes); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(ec.TaggedHash(tagNonce, randBytes, secret.FillBytes(make([]byte, 32))))
+		k.Mod(k, n)
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
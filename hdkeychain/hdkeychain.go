@@ -117,11 +117,14 @@ func (k *ExtendedKey) Child(idx uint32) (*ExtendedKey, error){
 	binary.BigEndian.PutUint32(data[keyLen:], idx)
 	hmac512 := hmac.New(sha512.New, k.chainCode)
 	hmac512.Write(data)
+	zeroBytes(data)
 	lr := hmac512.Sum(nil)
 
 	left := lr[:len(lr)/2]
 	childChainCode := lr[len(lr)/2:]
 
+	// The child chain code stays in lr, so only IL is wiped.
+	defer zeroBytes(left)
 	leftNum := new(big.Int).SetBytes(left)
 	if leftNum.Cmp(ec.S256().N) >= 0 || leftNum.Sign() == 0 {
 		return nil, ErrorInvalidChild
@@ -131,28 +134,32 @@ func (k *ExtendedKey) Child(idx uint32) (*ExtendedKey, error){
 	var childKey []byte
 
 	if k.isPrivate{
-		// case #1 or #2 
-		keyNum := new(big.Int).SetBytes(k.key)
-		leftNum.Add(leftNum, keyNum)
-		// 保证 私钥值不会超过椭圆曲线N值
-		leftNum.Mod(leftNum, ec.S256().N)
-		childKey = leftNum.Bytes()
-		isPrivate = true
-	}else{
-		// case #3
-		curve := ec.S256()
-		ilx, ily := curve.ScalarBaseMult(left)
-		if ilx.Sign() == 0 || ily.Sign() == 0 {
+		// case #1 or #2, 子私钥 = (IL + 父私钥) mod N
+		privKey, _ := ec.PrivKeyFromBytes(ec.S256(), k.key)
+		childPriv, err := privKey.TweakAdd(left)
+		privKey.Zero()
+		if err != nil {
 			return nil, ErrorInvalidChild
 		}
+		// The child key is kept without leading zeros, as derivation has
+		// always done: about one child in 256 has a key shorter than 32
+		// bytes, and it goes left-aligned into the HMAC input of its
+		// hardened children.  BIP32 pads it to 32 bytes, but doing so
+		// would change those grandchildren for existing wallets.
+		childKey = childPriv.D.Bytes()
+		childPriv.Zero()
+		isPrivate = true
+	}else{
+		// case #3, 子公钥 = IL*G + 父公钥
 		pubKey, err := ec.ParsePubKey(k.key, ec.S256())
 		if err != nil {
 			return nil, err
 		}
-		// 把中间公钥加到 父公钥上，推导出子公钥
-		childX, childY := ec.S256().Add(ilx, ily, pubKey.X, pubKey.Y)
-		pk := ec.PublicKey{Curve: ec.S256(), X: childX, Y: childY}
-		childKey = pk.SerializeCompressed()
+		childPub, err := pubKey.TweakAdd(left)
+		if err != nil {
+			return nil, ErrorInvalidChild
+		}
+		childKey = childPub.SerializeCompressed()
 	}
 	// The fingerprint of the parent for the derived child
 	parentFP := ec.Hash160(k.pubKeyBytes())[:4]
@@ -209,8 +216,34 @@ func (k *ExtendedKey) Neuter() (*ExtendedKey, error) {
 
 	// Convert it to an extended public key.  The key for the new extended
 	// key will simply be the pubkey of the current extended private key.
+	// The chain code is copied so that zeroing either key leaves the other
+	// intact.
 	//
 	// This is the function N((k,c)) -> (K, c) from [BIP32].
-	return NewExtendedKey(HDPublicKeyID[:], k.pubKeyBytes(), k.chainCode, k.parentFP,
+	pubKey := append([]byte(nil), k.pubKeyBytes()...)
+	chainCode := append([]byte(nil), k.chainCode...)
+	return NewExtendedKey(HDPublicKeyID[:], pubKey, chainCode, k.parentFP,
 		k.depth, k.childNum, false), nil
 }
+
+// Zero 清除扩展密钥中的私钥和链码。
+//
+// Zero wipes the key and the chain code, which together allow deriving every
+// descendant key.  The extended key must not be used afterwards.  Keys
+// derived from it or returned by Neuter own their own copies and are not
+// affected.
+func (k *ExtendedKey) Zero() {
+	zeroBytes(k.key)
+	zeroBytes(k.chainCode)
+	zeroBytes(k.pubKey)
+	k.key = nil
+	k.chainCode = nil
+	k.pubKey = nil
+}
+
+// zeroBytes overwrites b.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
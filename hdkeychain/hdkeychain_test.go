@@ -0,0 +1,124 @@
+package hdkeychain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestExtendedKeyZero(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x5a}, 32)
+	master, err := NewMaster(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := master.Child(HardenedKeyStart + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubChild, err := pub.Child(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPub := append([]byte(nil), pub.key...)
+	wantChildKey := append([]byte(nil), child.key...)
+	wantPubChild := append([]byte(nil), pubChild.key...)
+
+	key, chainCode := master.key, master.chainCode
+	master.Zero()
+	if master.key != nil || master.chainCode != nil {
+		t.Error("Zero kept references to the key material")
+	}
+	if !bytes.Equal(key, make([]byte, len(key))) {
+		t.Errorf("key was not wiped: %x", key)
+	}
+	if !bytes.Equal(chainCode, make([]byte, len(chainCode))) {
+		t.Errorf("chain code was not wiped: %x", chainCode)
+	}
+
+	// Keys derived before the parent was wiped are unaffected.
+	if !bytes.Equal(pub.key, wantPub) || bytes.Equal(pub.chainCode, make([]byte, 32)) {
+		t.Error("wiping the master key changed its neutered key")
+	}
+	if !bytes.Equal(child.key, wantChildKey) {
+		t.Error("wiping the master key changed its child")
+	}
+	if again, err := pub.Child(2); err != nil || !bytes.Equal(again.key, wantPubChild) {
+		t.Errorf("neutered key derives a different child after wiping the master: %v", err)
+	}
+
+	child.Zero()
+	pub.Zero()
+	if child.key != nil || pub.key != nil {
+		t.Error("Zero kept references to the key material")
+	}
+}
+
+// TestChildShortKey pins the derivation below children whose private key is
+// shorter than 32 bytes.  Such keys are stored without leading zeros and
+// hashed left-aligned when deriving hardened children, as they always
+// have been.
+func TestChildShortKey(t *testing.T) {
+	tests := []struct {
+		idx      uint32
+		key      string
+		hardened string // key of child HardenedKeyStart
+		normal   string // key of child 0
+	}{
+		{
+			133,
+			"60f6a534cf46880862e3f36dba55604ee98f76ccab558967b512df65bd5508",
+			"7a112ea1f78e1be194621d1e1b874ccd34b0205c1db218dff0fe232db9c9da9d",
+			"3b553e48d6b2f41872df736cc4b126867054d13e11c948ae18d5dc2cc7e596e8",
+		},
+		{
+			226,
+			"2a21c95c506bbf7b832337bf84da316d71e76a202dcd4b10b3262186c20709",
+			"56ff01e5e1d9d38a6f4658cbf7e23e3cfc50b38bd6bebbd7e63638b809a1345e",
+			"44da4f01226d752b93b173cd2b994df7fdb1430038403bb636e6803619b54b0f",
+		},
+		{
+			HardenedKeyStart + 32,
+			"45c85f2471e795bd8eaf7c5185f342c38f4f725d65fdfc7db9d73e6ccd910b",
+			"bb70cf5d6b476954ae7a493d78432e43c7964a03062db62fb35ef930fa1810b1",
+			"",
+		},
+	}
+
+	master, err := NewMaster(bytes.Repeat([]byte{0x5a}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		child, err := master.Child(test.idx)
+		if err != nil {
+			t.Fatalf("child %d: %v", test.idx, err)
+		}
+		if got := hex.EncodeToString(child.key); got != test.key {
+			t.Errorf("child %d: key %s, want %s", test.idx, got, test.key)
+		}
+
+		hardened, err := child.Child(HardenedKeyStart)
+		if err != nil {
+			t.Fatalf("child %d: %v", test.idx, err)
+		}
+		if got := hex.EncodeToString(hardened.key); got != test.hardened {
+			t.Errorf("child %d/0': key %s, want %s", test.idx, got, test.hardened)
+		}
+
+		if test.normal == "" {
+			continue
+		}
+		normal, err := child.Child(0)
+		if err != nil {
+			t.Fatalf("child %d: %v", test.idx, err)
+		}
+		if got := hex.EncodeToString(normal.key); got != test.normal {
+			t.Errorf("child %d/0: key %s, want %s", test.idx, got, test.normal)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package jose
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+func testKey(seed string) (*ec.PrivateKey, *ec.PublicKey) {
+	b := sha256.Sum256([]byte(seed))
+	return ec.PrivKeyFromBytes(ec.S256(), b[:])
+}
+
+func TestJWKGenerator(t *testing.T) {
+	// The JWK of the private key 1 is the generator.
+	one := make([]byte, 32)
+	one[31] = 1
+	priv, _ := ec.PrivKeyFromBytes(ec.S256(), one)
+	k := NewPrivateJWK(priv)
+
+	want := `{"kty":"EC","crv":"secp256k1",` +
+		`"x":"eb5mfvncu6xVoGKVzocLBwKb_NstzijZWfKBWxb4F5g",` +
+		`"y":"SDradyajxGVdpPv8DhEIqP0XtEimhVQZnEfQj_sQ1Lg",` +
+		`"d":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE"}`
+	got, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("JWK\n got %s\nwant %s", got, want)
+	}
+
+	// RFC 7638 section 3: SHA-256 of the required members in
+	// lexicographic order without whitespace.
+	canonical := `{"crv":"secp256k1","kty":"EC",` +
+		`"x":"eb5mfvncu6xVoGKVzocLBwKb_NstzijZWfKBWxb4F5g",` +
+		`"y":"SDradyajxGVdpPv8DhEIqP0XtEimhVQZnEfQj_sQ1Lg"}`
+	sum := sha256.Sum256([]byte(canonical))
+	tp, err := k.ThumbprintString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tp != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Errorf("thumbprint %s, want that of %s", tp, canonical)
+	}
+	pubTP, _ := k.Public().ThumbprintString()
+	if pubTP != tp {
+		t.Error("public and private thumbprints differ")
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	priv, pub := testKey("node key")
+	k := NewPrivateJWK(priv)
+	k.Kid, _ = k.ThumbprintString()
+
+	data, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed JWK
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	gotPriv, err := parsed.PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPriv.D.Cmp(priv.D) != 0 {
+		t.Error("private key changed in the round trip")
+	}
+	gotPub, err := parsed.Public().PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPub.X.Cmp(pub.X) != 0 || gotPub.Y.Cmp(pub.Y) != 0 {
+		t.Error("public key changed in the round trip")
+	}
+	if _, err := parsed.Public().PrivateKey(); err != ErrNotPrivate {
+		t.Errorf("public JWK PrivateKey: got %v, want %v", err, ErrNotPrivate)
+	}
+	if strings.Contains(string(mustMarshal(t, k.Public())), `"d"`) {
+		t.Error("public JWK has a d member")
+	}
+}
+
+func TestJWKErrors(t *testing.T) {
+	priv, _ := testKey("node key")
+	other, _ := testKey("other key")
+	good := NewPrivateJWK(priv)
+
+	mutate := func(f func(k *JWK)) *JWK {
+		k := *good
+		f(&k)
+		return &k
+	}
+	tests := []struct {
+		name string
+		key  *JWK
+	}{
+		{"kty", mutate(func(k *JWK) { k.Kty = "OKP" })},
+		{"crv", mutate(func(k *JWK) { k.Crv = "P-256" })},
+		{"short x", mutate(func(k *JWK) { k.X = k.X[:40] })},
+		{"bad base64", mutate(func(k *JWK) { k.Y = "!" + k.Y[1:] })},
+		{"trailing bits", mutate(func(k *JWK) { k.X = setTrailingBit(k.X) })},
+		{"off curve", mutate(func(k *JWK) { k.Y = k.X })},
+		{"mismatched d", mutate(func(k *JWK) { k.D = NewPrivateJWK(other).D })},
+		{"zero d", mutate(func(k *JWK) { k.D = b64(make([]byte, 32)) })},
+	}
+	for _, test := range tests {
+		if _, err := test.key.PrivateKey(); err == nil {
+			t.Errorf("%s: accepted", test.name)
+		}
+	}
+	if _, err := mutate(func(k *JWK) { k.D = NewPrivateJWK(other).D }).PrivateKey(); err != ErrKeyMismatch {
+		t.Errorf("mismatched d: got %v, want %v", err, ErrKeyMismatch)
+	}
+
+	// A second spelling of x must not give the key a second thumbprint.
+	if _, err := mutate(func(k *JWK) { k.X = setTrailingBit(k.X) }).Thumbprint(); err == nil {
+		t.Error("thumbprint of an x member with non-zero trailing bits")
+	}
+}
+
+// setTrailingBit sets the lowest of the unused bits of the last character
+// of an unpadded base64url encoding of 32 bytes, which lenient decoders
+// ignore.
+func setTrailingBit(s string) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	last := strings.IndexByte(alphabet, s[len(s)-1])
+	return s[:len(s)-1] + string(alphabet[last|1])
+}
+
+func TestJWS(t *testing.T) {
+	priv, pub := testKey("gateway")
+	payload := []byte(`{"sub":"node-7","exp":1700000000}`)
+	kid, _ := NewPublicJWK(pub).ThumbprintString()
+
+	token, err := Sign(priv, &Header{Typ: "JWT", Kid: kid}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Sign(priv, &Header{Typ: "JWT", Kid: kid}, payload)
+	if again != token {
+		t.Error("signing is not deterministic")
+	}
+
+	h, got, err := Verify(pub, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alg != AlgES256K || h.Kid != kid || h.Typ != "JWT" || string(got) != string(payload) {
+		t.Errorf("Verify = %+v, %s", h, got)
+	}
+	if _, _, err := VerifyJWK(NewPublicJWK(pub), token); err != nil {
+		t.Errorf("VerifyJWK: %v", err)
+	}
+
+	// The signature is raw R || S over the signing input and checks out
+	// with crypto/ecdsa.
+	parts := strings.Split(token, ".")
+	rs, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if len(rs) != SignatureSize {
+		t.Fatalf("signature is %d bytes", len(rs))
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(rs[:32]), new(big.Int).SetBytes(rs[32:])
+	if !ecdsa.Verify(pub.ToECDSA(), hash[:], r, s) {
+		t.Error("crypto/ecdsa rejects the signature")
+	}
+}
+
+func TestJWSErrors(t *testing.T) {
+	priv, pub := testKey("gateway")
+	_, other := testKey("other")
+	token, err := Sign(priv, nil, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	header := func(h string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(h))
+	}
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"other key", token, ErrBadSignature},
+		{"tampered payload", parts[0] + "." + b64([]byte("payloae")) + "." + parts[2], ErrBadSignature},
+		{"alg none", header(`{"alg":"none"}`) + "." + parts[1] + ".", ErrAlgorithm},
+		{"alg ES256", header(`{"alg":"ES256"}`) + "." + parts[1] + "." + parts[2], ErrAlgorithm},
+		{"crit", header(`{"alg":"ES256K","crit":["exp"]}`) + "." + parts[1] + "." + parts[2], ErrCritical},
+		{"two parts", parts[0] + "." + parts[1], ErrMalformed},
+		{"short signature", parts[0] + "." + parts[1] + "." + parts[2][:40], ErrMalformed},
+		{"bad header", "e30." + parts[1] + "." + parts[2], ErrAlgorithm},
+	}
+	for _, test := range tests {
+		key := pub
+		if test.name == "other key" {
+			key = other
+		}
+		if _, _, err := Verify(key, test.token); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
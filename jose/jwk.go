@@ -0,0 +1,202 @@
+// JOSE 签名: 把 secp256k1 密钥导出成 JWK, 用 ES256K 签发和验证 JWS 令牌,
+// 供网关用节点密钥签发 token.
+//
+// Keys are JSON Web Keys (RFC 7517) of kty "EC" with the crv "secp256k1"
+// registered by RFC 8812, whose x, y and d members are the base64url encoded
+// 32 byte big-endian coordinates and scalar.  Key ids are usually the
+// RFC 7638 thumbprint of the public key.
+//
+// Tokens are JWS compact serializations (RFC 7515) with the ES256K
+// algorithm of RFC 8812: ECDSA over the SHA-256 of the signing input, with
+// the signature encoded as the 64 byte concatenation R || S instead of DER.
+// Signing uses the deterministic RFC 6979 nonces of PrivateKey.Sign.
+//
+// https://www.rfc-editor.org/rfc/rfc7517
+// https://www.rfc-editor.org/rfc/rfc7515
+// https://www.rfc-editor.org/rfc/rfc7638
+// https://www.rfc-editor.org/rfc/rfc8812
+
+package jose
+
+import "crypto/sha256"
+import "crypto/subtle"
+import "encoding/base64"
+import "encoding/json"
+import "errors"
+import "fmt"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+const (
+	// KeyTypeEC is the kty of elliptic curve keys.
+	KeyTypeEC = "EC"
+
+	// CurveSecp256k1 is the crv of secp256k1 keys.
+	CurveSecp256k1 = "secp256k1"
+
+	// coordSize is the length of the decoded x, y and d members.
+	coordSize = 32
+)
+
+var (
+	ErrKeyType     = errors.New("jose: key is not an EC secp256k1 key")
+	ErrNotPrivate  = errors.New("jose: key has no private part")
+	ErrKeyMismatch = errors.New("jose: private key does not match the public key")
+)
+
+// b64url decodes unpadded base64url and, unlike base64.RawURLEncoding,
+// rejects encodings whose unused trailing bits are not zero, so that every
+// value has exactly one encoding.
+var b64url = base64.RawURLEncoding.Strict()
+
+// JWK is a secp256k1 JSON Web Key.  It marshals to and from its JSON
+// representation with encoding/json.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"`
+
+	// Optional members of RFC 7517 section 4.
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+}
+
+// NewPublicJWK returns the JWK of a public key.
+func NewPublicJWK(pub *ec.PublicKey) *JWK {
+	b := pub.SerializeUncompressed()
+	return &JWK{
+		Kty: KeyTypeEC,
+		Crv: CurveSecp256k1,
+		X:   b64(b[1 : 1+coordSize]),
+		Y:   b64(b[1+coordSize:]),
+	}
+}
+
+// NewPrivateJWK returns the JWK of a private key, which includes the
+// private scalar d.
+func NewPrivateJWK(priv *ec.PrivateKey) *JWK {
+	k := NewPublicJWK(priv.ECPubKey())
+	d := priv.PrivatekeyToBytes()
+	k.D = b64(d)
+	for i := range d {
+		d[i] = 0
+	}
+	return k
+}
+
+// Public returns a copy of the key without the private scalar.
+func (k *JWK) Public() *JWK {
+	pub := *k
+	pub.D = ""
+	return &pub
+}
+
+// IsPrivate reports whether the key has a private scalar.
+func (k *JWK) IsPrivate() bool {
+	return k.D != ""
+}
+
+// PublicKey returns the public key of the JWK, checking that it is a
+// secp256k1 key whose coordinates are a point on the curve.
+func (k *JWK) PublicKey() (*ec.PublicKey, error) {
+	if k.Kty != KeyTypeEC || k.Crv != CurveSecp256k1 {
+		return nil, ErrKeyType
+	}
+	x, err := decodeCoord("x", k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeCoord("y", k.Y)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ec.ParsePubKey(append(append([]byte{0x04}, x...), y...), ec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid public key: %v", err)
+	}
+	return pub, nil
+}
+
+// PrivateKey returns the private key of the JWK, checking that d is in
+// range and matches the public coordinates.
+func (k *JWK) PrivateKey() (*ec.PrivateKey, error) {
+	if !k.IsPrivate() {
+		return nil, ErrNotPrivate
+	}
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeCoord("d", k.D)
+	if err != nil {
+		return nil, err
+	}
+	priv, derived, err := ec.PrivKeyFromBytesStrict(ec.S256(), d)
+	for i := range d {
+		d[i] = 0
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid private key: %v", err)
+	}
+	if subtle.ConstantTimeCompare(derived.SerializeCompressed(), pub.SerializeCompressed()) != 1 {
+		return nil, ErrKeyMismatch
+	}
+	return priv, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key: the hash
+// of the JSON object of its required public members crv, kty, x and y in
+// lexicographic order without whitespace.  The thumbprint of a private key
+// is that of its public key.
+func (k *JWK) Thumbprint() ([]byte, error) {
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	// The members are re-encoded from the key rather than copied, and
+	// are base64url strings and fixed names, none of which needs
+	// escaping, so json.Marshal of the struct gives the canonical form.
+	c := NewPublicJWK(pub)
+	canonical, err := json.Marshal(struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}{c.Crv, c.Kty, c.X, c.Y})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	return sum[:], nil
+}
+
+// ThumbprintString returns the base64url encoded thumbprint of the key, the
+// usual form of a kid.
+func (k *JWK) ThumbprintString() (string, error) {
+	tp, err := k.Thumbprint()
+	if err != nil {
+		return "", err
+	}
+	return b64(tp), nil
+}
+
+// decodeCoord decodes a base64url member that must be exactly 32 bytes.
+func decodeCoord(name, s string) ([]byte, error) {
+	b, err := b64url.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("jose: malformed %s member: %v", name, err)
+	}
+	if len(b) != coordSize {
+		return nil, fmt.Errorf("jose: %s member is %d bytes, expected %d", name, len(b), coordSize)
+	}
+	return b, nil
+}
+
+// b64 returns the unpadded base64url encoding of b.
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,112 @@
+package jose
+
+import "crypto/sha256"
+import "encoding/json"
+import "errors"
+import "math/big"
+import "strings"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+const (
+	// AlgES256K is the JWS algorithm of ECDSA on secp256k1 with SHA-256.
+	AlgES256K = "ES256K"
+
+	// SignatureSize is the length of an ES256K signature, R || S.
+	SignatureSize = 2 * coordSize
+)
+
+var (
+	ErrMalformed    = errors.New("jose: malformed compact JWS")
+	ErrAlgorithm    = errors.New("jose: algorithm is not " + AlgES256K)
+	ErrCritical     = errors.New("jose: unsupported critical header parameters")
+	ErrBadSignature = errors.New("jose: invalid signature")
+)
+
+// Header is the protected header of a JWS.  Alg is always ES256K; the other
+// members are optional.
+type Header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid,omitempty"`
+	Typ  string   `json:"typ,omitempty"`
+	Cty  string   `json:"cty,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// Sign returns the compact serialization of a JWS of payload signed with
+// priv.  The alg of the header is set to ES256K; header may be nil.
+func Sign(priv *ec.PrivateKey, header *Header, payload []byte) (string, error) {
+	h := Header{}
+	if header != nil {
+		h = *header
+	}
+	h.Alg = AlgES256K
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	input := b64(hb) + "." + b64(payload)
+	hash := sha256.Sum256([]byte(input))
+	sig, err := priv.Sign(hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	var rs [SignatureSize]byte
+	sig.R.FillBytes(rs[:coordSize])
+	sig.S.FillBytes(rs[coordSize:])
+	return input + "." + b64(rs[:]), nil
+}
+
+// Verify checks the compact JWS token against pub and returns its header
+// and payload.  Tokens of any other algorithm, including "none", and tokens
+// with critical header parameters are rejected.
+func Verify(pub *ec.PublicKey, token string) (*Header, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, ErrMalformed
+	}
+	hb, err := b64url.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	var h Header
+	if err := json.Unmarshal(hb, &h); err != nil {
+		return nil, nil, ErrMalformed
+	}
+	if h.Alg != AlgES256K {
+		return nil, nil, ErrAlgorithm
+	}
+	if len(h.Crit) > 0 {
+		return nil, nil, ErrCritical
+	}
+	payload, err := b64url.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	rs, err := b64url.DecodeString(parts[2])
+	if err != nil || len(rs) != SignatureSize {
+		return nil, nil, ErrMalformed
+	}
+
+	sig := &ec.Signature{
+		R: new(big.Int).SetBytes(rs[:coordSize]),
+		S: new(big.Int).SetBytes(rs[coordSize:]),
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !sig.Verify(hash[:], pub) {
+		return nil, nil, ErrBadSignature
+	}
+	return &h, payload, nil
+}
+
+// VerifyJWK is Verify with the public key of a JWK.
+func VerifyJWK(key *JWK, token string) (*Header, []byte, error) {
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return Verify(pub, token)
+}
@@ -0,0 +1,312 @@
+// 加密 JSON 密钥文件: 私钥以 Web3 Secret Storage v3 格式落盘, 与 geth 等以太坊
+// 客户端的 keystore 文件互通.
+//
+// A key file holds the 32 byte private key encrypted with AES-128-CTR under
+// the first half of a 32 byte key derived from the passphrase with scrypt
+// or PBKDF2-HMAC-SHA256.  The second half of the derived key authenticates
+// the ciphertext: mac = Keccak-256(derived[16:32] || ciphertext).  The
+// address member is the Ethereum address of the key, in lower case hex
+// without the 0x prefix.
+//
+// https://ethereum.org/en/developers/docs/data-structures-and-encoding/web3-secret-storage/
+
+package keystore
+
+import "crypto/aes"
+import "crypto/cipher"
+import "crypto/rand"
+import "crypto/sha256"
+import "crypto/subtle"
+import "encoding/hex"
+import "encoding/json"
+import "fmt"
+
+import "golang.org/x/crypto/pbkdf2"
+import "golang.org/x/crypto/scrypt"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+const (
+	// Version is the version of the key file format.
+	Version = 3
+
+	// StandardScryptN and StandardScryptP are the scrypt parameters of
+	// geth's default key files, which take about a second and 256MB to
+	// decrypt.
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+
+	// LightScryptN and LightScryptP are the scrypt parameters of geth's
+	// --lightkdf key files, for memory constrained devices.
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	// StandardPBKDF2Iterations is the PBKDF2 iteration count of the
+	// reference key files.
+	StandardPBKDF2Iterations = 1 << 18
+
+	// maxScryptN and maxScryptWork bound n and n*r*p of the scrypt
+	// parameters accepted from a key file, which caps its memory use at
+	// 128*r*n = 1GB, and maxPBKDF2Iterations bounds the PBKDF2 iteration
+	// count, so that a crafted file cannot exhaust the memory of the
+	// process or make it hang.  They allow four times the work of the
+	// standard parameters.
+	maxScryptN          = 1 << 20
+	maxScryptWork       = 4 * StandardScryptN * scryptR * StandardScryptP
+	maxPBKDF2Iterations = 4 * StandardPBKDF2Iterations
+
+	scryptR     = 8
+	scryptDKLen = 32
+	cipherName  = "aes-128-ctr"
+	kdfScrypt   = "scrypt"
+	kdfPBKDF2   = "pbkdf2"
+	prfSHA256   = "hmac-sha256"
+)
+
+var (
+	ErrDecrypt         = fmt.Errorf("keystore: could not decrypt key with given passphrase")
+	ErrVersion         = fmt.Errorf("keystore: unsupported key file version")
+	ErrAddressMismatch = fmt.Errorf("keystore: key file address does not match its key")
+)
+
+// keyFileJSON is the JSON layout of a version 3 key file.
+type keyFileJSON struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// EncryptKey returns the version 3 key file of priv encrypted with
+// passphrase, using scrypt with the cost parameters n and p, such as
+// StandardScryptN and StandardScryptP.
+func EncryptKey(priv *ec.PrivateKey, passphrase string, n, p int) ([]byte, error) {
+	salt, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, n, scryptR, p, scryptDKLen)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %v", err)
+	}
+	params := map[string]interface{}{
+		"n":     n,
+		"r":     scryptR,
+		"p":     p,
+		"dklen": scryptDKLen,
+		"salt":  hex.EncodeToString(salt),
+	}
+	return encryptKey(priv, derived, kdfScrypt, params)
+}
+
+// EncryptKeyPBKDF2 is like EncryptKey, but derives the key with
+// PBKDF2-HMAC-SHA256 and the given iteration count.
+func EncryptKeyPBKDF2(priv *ec.PrivateKey, passphrase string, iterations int) ([]byte, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("keystore: invalid PBKDF2 iteration count %d", iterations)
+	}
+	salt, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	derived := pbkdf2.Key([]byte(passphrase), salt, iterations, scryptDKLen, sha256.New)
+	params := map[string]interface{}{
+		"c":     iterations,
+		"prf":   prfSHA256,
+		"dklen": scryptDKLen,
+		"salt":  hex.EncodeToString(salt),
+	}
+	return encryptKey(priv, derived, kdfPBKDF2, params)
+}
+
+// encryptKey encrypts priv under the derived key and returns the key file.
+func encryptKey(priv *ec.PrivateKey, derived []byte, kdf string, params map[string]interface{}) ([]byte, error) {
+	defer zero(derived)
+	iv, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	plain := priv.PrivatekeyToBytes()
+	defer zero(plain)
+	ciphertext, err := aesCTR(derived[:16], iv, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(keyFileJSON{
+		Address: hex.EncodeToString(priv.ECPubKey().EthereumAddress()),
+		Crypto: cryptoJSON{
+			Cipher:       cipherName,
+			CipherText:   hex.EncodeToString(ciphertext),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          kdf,
+			KDFParams:    params,
+			MAC:          hex.EncodeToString(ec.Keccak256(derived[16:32], ciphertext)),
+		},
+		ID:      id,
+		Version: Version,
+	})
+}
+
+// DecryptKey decrypts a version 3 key file with passphrase.  It returns
+// ErrDecrypt when the MAC does not match, which means a wrong passphrase or
+// a corrupt file.
+func DecryptKey(keyjson []byte, passphrase string) (*ec.PrivateKey, error) {
+	var k keyFileJSON
+	if err := json.Unmarshal(keyjson, &k); err != nil {
+		return nil, fmt.Errorf("keystore: malformed key file: %v", err)
+	}
+	if k.Version != Version {
+		return nil, ErrVersion
+	}
+	if k.Crypto.Cipher != cipherName {
+		return nil, fmt.Errorf("keystore: unsupported cipher %q", k.Crypto.Cipher)
+	}
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: malformed mac: %v", err)
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("keystore: malformed iv")
+	}
+	ciphertext, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: malformed ciphertext: %v", err)
+	}
+
+	derived, err := deriveKey(&k.Crypto, []byte(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	defer zero(derived)
+	if subtle.ConstantTimeCompare(ec.Keccak256(derived[16:32], ciphertext), mac) != 1 {
+		return nil, ErrDecrypt
+	}
+
+	plain, err := aesCTR(derived[:16], iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(plain)
+	priv, _, err := ec.PrivKeyFromBytesStrict(ec.S256(), plain)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid private key: %v", err)
+	}
+	if k.Address != "" {
+		addr, err := ParseAddress(k.Address)
+		if err != nil || addr != PubKeyToAddress(priv.ECPubKey()) {
+			return nil, ErrAddressMismatch
+		}
+	}
+	return priv, nil
+}
+
+// deriveKey runs the key derivation function of a key file.
+func deriveKey(c *cryptoJSON, passphrase []byte) ([]byte, error) {
+	salt, err := hex.DecodeString(paramString(c.KDFParams, "salt"))
+	if err != nil {
+		return nil, fmt.Errorf("keystore: malformed salt: %v", err)
+	}
+	dkLen := paramInt(c.KDFParams, "dklen")
+	if dkLen != scryptDKLen {
+		return nil, fmt.Errorf("keystore: derived key length %d is not 32", dkLen)
+	}
+
+	switch c.KDF {
+	case kdfScrypt:
+		n := paramInt(c.KDFParams, "n")
+		r := paramInt(c.KDFParams, "r")
+		p := paramInt(c.KDFParams, "p")
+		if n < 2 || n > maxScryptN || r < 1 || p < 1 ||
+			r > maxScryptWork/n || p > maxScryptWork/(n*r) {
+			return nil, fmt.Errorf("keystore: scrypt parameters n=%d r=%d p=%d out of range", n, r, p)
+		}
+		derived, err := scrypt.Key(passphrase, salt, n, r, p, dkLen)
+		if err != nil {
+			return nil, fmt.Errorf("keystore: %v", err)
+		}
+		return derived, nil
+	case kdfPBKDF2:
+		if prf := paramString(c.KDFParams, "prf"); prf != prfSHA256 {
+			return nil, fmt.Errorf("keystore: unsupported PBKDF2 prf %q", prf)
+		}
+		iterations := paramInt(c.KDFParams, "c")
+		if iterations < 1 || iterations > maxPBKDF2Iterations {
+			return nil, fmt.Errorf("keystore: invalid PBKDF2 iteration count %d", iterations)
+		}
+		return pbkdf2.Key(passphrase, salt, iterations, dkLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("keystore: unsupported kdf %q", c.KDF)
+}
+
+// paramInt returns a numeric KDF parameter, or 0 if it is missing or not
+// an integer.
+func paramInt(params map[string]interface{}, name string) int {
+	f, ok := params[name].(float64)
+	if !ok || f != float64(int(f)) {
+		return 0
+	}
+	return int(f)
+}
+
+// paramString returns a string KDF parameter, or "" if it is missing.
+func paramString(params map[string]interface{}, name string) string {
+	s, _ := params[name].(string)
+	return s
+}
+
+// aesCTR encrypts or decrypts in with AES-128-CTR.
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() (string, error) {
+	u, err := randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
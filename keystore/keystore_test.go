@@ -0,0 +1,146 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+// The key files in testdata are the test vectors of the Web3 Secret Storage
+// definition and a key file written by geth, the one of the "aaa" account
+// in go-ethereum's accounts/keystore/testdata.
+func TestDecryptVectors(t *testing.T) {
+	tests := []struct {
+		file       string
+		passphrase string
+		key        string
+		address    string
+	}{
+		{"v3_pbkdf2.json", "testpassword",
+			"7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+			"0x008AeEda4D805471dF9b2A5B0f38A0C3bCBA786b"},
+		{"v3_scrypt.json", "testpassword",
+			"7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+			"0x008AeEda4D805471dF9b2A5B0f38A0C3bCBA786b"},
+		{"keystore/UTC--2016-03-22T12-57-55.920751759Z--7ef5a6135f1fd6a02593eedc869c6d41d934aef8", "foobar",
+			"976f9f7772781ff6d1c93941129d417c49a209c674056a3cf5e27e225ee55fa8",
+			"0x7EF5A6135f1FD6a02593eEdC869c6D41D934aef8"},
+	}
+	for _, test := range tests {
+		keyjson, err := os.ReadFile(filepath.Join("testdata", test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		priv, err := DecryptKey(keyjson, test.passphrase)
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if got := hex.EncodeToString(priv.PrivatekeyToBytes()); got != test.key {
+			t.Errorf("%s: key %s, want %s", test.file, got, test.key)
+		}
+		if got := PubKeyToAddress(priv.ECPubKey()).String(); got != test.address {
+			t.Errorf("%s: address %s, want %s", test.file, got, test.address)
+		}
+		if _, err := DecryptKey(keyjson, test.passphrase+"x"); err != ErrDecrypt {
+			t.Errorf("%s with wrong passphrase: got %v, want %v", test.file, err, ErrDecrypt)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	priv, err := ec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		encrypt func() ([]byte, error)
+		kdf     string
+	}{
+		{"scrypt", func() ([]byte, error) { return EncryptKey(priv, "pass", LightScryptN, LightScryptP) }, "scrypt"},
+		{"pbkdf2", func() ([]byte, error) { return EncryptKeyPBKDF2(priv, "pass", 4096) }, "pbkdf2"},
+	}
+	for _, test := range tests {
+		keyjson, err := test.encrypt()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		var k keyFileJSON
+		if err := json.Unmarshal(keyjson, &k); err != nil {
+			t.Fatal(err)
+		}
+		if k.Version != 3 || k.Crypto.KDF != test.kdf || k.Crypto.Cipher != "aes-128-ctr" || len(k.ID) != 36 {
+			t.Errorf("%s: unexpected key file %s", test.name, keyjson)
+		}
+		if k.Address != hex.EncodeToString(priv.ECPubKey().EthereumAddress()) {
+			t.Errorf("%s: address %s", test.name, k.Address)
+		}
+
+		got, err := DecryptKey(keyjson, "pass")
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got.D.Cmp(priv.D) != 0 {
+			t.Errorf("%s: round trip changed the key", test.name)
+		}
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	priv, _ := ec.NewPrivateKey()
+	keyjson, err := EncryptKey(priv, "pass", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := ec.NewPrivateKey()
+	otherAddr := hex.EncodeToString(other.ECPubKey().EthereumAddress())
+
+	mutate := func(f func(k *keyFileJSON)) []byte {
+		var k keyFileJSON
+		json.Unmarshal(keyjson, &k)
+		f(&k)
+		b, _ := json.Marshal(k)
+		return b
+	}
+	tests := []struct {
+		name    string
+		keyjson []byte
+		err     error
+	}{
+		{"version 1", mutate(func(k *keyFileJSON) { k.Version = 1 }), ErrVersion},
+		{"tampered ciphertext", mutate(func(k *keyFileJSON) {
+			k.Crypto.CipherText = strings.Repeat("00", 32)
+		}), ErrDecrypt},
+		{"wrong address", mutate(func(k *keyFileJSON) { k.Address = otherAddr }), ErrAddressMismatch},
+		{"cipher", mutate(func(k *keyFileJSON) { k.Crypto.Cipher = "aes-128-cbc" }), nil},
+		{"kdf", mutate(func(k *keyFileJSON) { k.Crypto.KDF = "argon2" }), nil},
+		{"short dklen", mutate(func(k *keyFileJSON) { k.Crypto.KDFParams["dklen"] = 16 }), nil},
+		{"long dklen", mutate(func(k *keyFileJSON) { k.Crypto.KDFParams["dklen"] = 1 << 40 }), nil},
+		{"bad n", mutate(func(k *keyFileJSON) { k.Crypto.KDFParams["n"] = 1000 }), nil},
+		{"huge n", mutate(func(k *keyFileJSON) { k.Crypto.KDFParams["n"] = 1 << 40 }), nil},
+		{"huge r", mutate(func(k *keyFileJSON) { k.Crypto.KDFParams["r"] = 1 << 20 }), nil},
+		{"huge p", mutate(func(k *keyFileJSON) { k.Crypto.KDFParams["p"] = 1 << 29 }), nil},
+		{"huge c", mutate(func(k *keyFileJSON) {
+			k.Crypto.KDF = "pbkdf2"
+			k.Crypto.KDFParams = map[string]interface{}{
+				"c": 1 << 40, "dklen": 32, "prf": "hmac-sha256",
+				"salt": k.Crypto.KDFParams["salt"],
+			}
+		}), nil},
+		{"not JSON", []byte("{"), nil},
+	}
+	for _, test := range tests {
+		_, err := DecryptKey(test.keyjson, "pass")
+		if err == nil || (test.err != nil && err != test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package keystore
+
+import "encoding/hex"
+import "encoding/json"
+import "fmt"
+import "os"
+import "path/filepath"
+import "sort"
+import "strings"
+import "sync"
+import "time"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+var (
+	ErrNoKey  = fmt.Errorf("keystore: no key for the address")
+	ErrExists = fmt.Errorf("keystore: a key for the address already exists")
+)
+
+// Address is the Ethereum address of a key, which names its key file.
+type Address [20]byte
+
+// PubKeyToAddress returns the address of a public key.
+func PubKeyToAddress(pub *ec.PublicKey) Address {
+	var a Address
+	copy(a[:], pub.EthereumAddress())
+	return a
+}
+
+// ParseAddress parses a hex address with or without the 0x prefix, see
+// elliptic.DecodeEthereumAddress.
+func ParseAddress(s string) (Address, error) {
+	var a Address
+	b, err := ec.DecodeEthereumAddress(s)
+	if err != nil {
+		return a, fmt.Errorf("keystore: %v", err)
+	}
+	copy(a[:], b)
+	return a, nil
+}
+
+// String returns the EIP-55 checksummed address.
+func (a Address) String() string {
+	return ec.EncodeEthereumAddress(a[:])
+}
+
+// Account is a key in a KeyStore.
+type Account struct {
+	Address Address
+	Path    string
+}
+
+// KeyStore keeps key files in a directory, named the way geth names them:
+// UTC--<creation time>--<lower case hex address>.  Keys written by the store
+// are encrypted with scrypt using its cost parameters.  Files that are not
+// key files, such as editor backups, are ignored.
+//
+// A KeyStore is safe for concurrent use, but not against other processes
+// storing keys in the same directory.
+type KeyStore struct {
+	dir     string
+	scryptN int
+	scryptP int
+
+	mu sync.Mutex // serializes the check for an existing key and the write
+}
+
+// NewKeyStore returns a key store in dir whose new key files use the scrypt
+// parameters n and p.  The directory is created when the first key is
+// stored.
+func NewKeyStore(dir string, n, p int) *KeyStore {
+	return &KeyStore{dir: dir, scryptN: n, scryptP: p}
+}
+
+// Dir returns the directory of the key store.
+func (ks *KeyStore) Dir() string {
+	return ks.dir
+}
+
+// Accounts returns the keys of the store sorted by address.
+func (ks *KeyStore) Accounts() ([]Account, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %v", err)
+	}
+
+	var accounts []Account
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		path := filepath.Join(ks.dir, name)
+		addr, ok := readAddress(path)
+		if !ok {
+			continue
+		}
+		accounts = append(accounts, Account{Address: addr, Path: path})
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return string(accounts[i].Address[:]) < string(accounts[j].Address[:])
+	})
+	return accounts, nil
+}
+
+// Has reports whether the store has a key for the address.
+func (ks *KeyStore) Has(addr Address) bool {
+	_, err := ks.find(addr)
+	return err == nil
+}
+
+// Store encrypts priv with passphrase and writes it to a new key file.
+func (ks *KeyStore) Store(priv *ec.PrivateKey, passphrase string) (Account, error) {
+	addr := PubKeyToAddress(priv.ECPubKey())
+	if ks.Has(addr) {
+		return Account{}, ErrExists
+	}
+	keyjson, err := EncryptKey(priv, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return Account{}, err
+	}
+
+	// Check again, as another Store may have written the key while it was
+	// being encrypted.
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.Has(addr) {
+		return Account{}, ErrExists
+	}
+	return ks.write(addr, keyjson)
+}
+
+// Import decrypts a key file with passphrase and stores the key encrypted
+// with newPassphrase and the scrypt parameters of the store.
+func (ks *KeyStore) Import(keyjson []byte, passphrase, newPassphrase string) (Account, error) {
+	priv, err := DecryptKey(keyjson, passphrase)
+	if err != nil {
+		return Account{}, err
+	}
+	defer priv.Zero()
+	return ks.Store(priv, newPassphrase)
+}
+
+// Export returns the key file of the address re-encrypted with
+// newPassphrase.
+func (ks *KeyStore) Export(addr Address, passphrase, newPassphrase string) ([]byte, error) {
+	priv, err := ks.Key(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer priv.Zero()
+	return EncryptKey(priv, newPassphrase, ks.scryptN, ks.scryptP)
+}
+
+// Key decrypts and returns the private key of the address.  The caller
+// should Zero it once done.
+func (ks *KeyStore) Key(addr Address, passphrase string) (*ec.PrivateKey, error) {
+	acct, err := ks.find(addr)
+	if err != nil {
+		return nil, err
+	}
+	keyjson, err := os.ReadFile(acct.Path)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %v", err)
+	}
+	return DecryptKey(keyjson, passphrase)
+}
+
+// Delete removes the key file of the address once passphrase is shown to
+// decrypt it.
+func (ks *KeyStore) Delete(addr Address, passphrase string) error {
+	priv, err := ks.Key(addr, passphrase)
+	if err != nil {
+		return err
+	}
+	priv.Zero()
+	acct, err := ks.find(addr)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(acct.Path); err != nil {
+		return fmt.Errorf("keystore: %v", err)
+	}
+	return nil
+}
+
+// find returns the account of the address.
+func (ks *KeyStore) find(addr Address) (Account, error) {
+	accounts, err := ks.Accounts()
+	if err != nil {
+		return Account{}, err
+	}
+	for _, a := range accounts {
+		if a.Address == addr {
+			return a, nil
+		}
+	}
+	return Account{}, ErrNoKey
+}
+
+// write atomically writes a new key file, readable only by the owner.
+func (ks *KeyStore) write(addr Address, keyjson []byte) (Account, error) {
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return Account{}, fmt.Errorf("keystore: %v", err)
+	}
+	name := keyFileName(addr, time.Now())
+	tmp, err := os.CreateTemp(ks.dir, "."+name+".tmp")
+	if err != nil {
+		return Account{}, fmt.Errorf("keystore: %v", err)
+	}
+	if _, err := tmp.Write(keyjson); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return Account{}, fmt.Errorf("keystore: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return Account{}, fmt.Errorf("keystore: %v", err)
+	}
+	path := filepath.Join(ks.dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return Account{}, fmt.Errorf("keystore: %v", err)
+	}
+	return Account{Address: addr, Path: path}, nil
+}
+
+// keyFileName returns the geth style name of a key file created at t.
+func keyFileName(addr Address, t time.Time) string {
+	ts := t.UTC().Format("2006-01-02T15-04-05.000000000Z")
+	return "UTC--" + ts + "--" + hex.EncodeToString(addr[:])
+}
+
+// readAddress returns the address member of a key file.
+func readAddress(path string) (Address, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Address{}, false
+	}
+	var k struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(data, &k); err != nil || k.Address == "" {
+		return Address{}, false
+	}
+	addr, err := ParseAddress(k.Address)
+	return addr, err == nil
+}
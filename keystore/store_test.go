@@ -0,0 +1,145 @@
+package keystore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+// copyTestKeyStore copies testdata/keystore into a temporary directory.
+func copyTestKeyStore(t *testing.T) string {
+	dir := t.TempDir()
+	entries, err := os.ReadDir(filepath.Join("testdata", "keystore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "keystore", e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestKeyStoreGethDir(t *testing.T) {
+	ks := NewKeyStore(copyTestKeyStore(t), LightScryptN, LightScryptP)
+	accounts, err := ks.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The README and the hidden file are not key files.
+	if len(accounts) != 1 {
+		t.Fatalf("got %d accounts, want 1", len(accounts))
+	}
+	addr, _ := ParseAddress("0x7EF5A6135f1FD6a02593eEdC869c6D41D934aef8")
+	if accounts[0].Address != addr {
+		t.Errorf("account %s, want %s", accounts[0].Address, addr)
+	}
+
+	priv, err := ks.Key(addr, "foobar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if PubKeyToAddress(priv.ECPubKey()) != addr {
+		t.Error("decrypted the key of another address")
+	}
+	if _, err := ks.Key(addr, "wrong"); err != ErrDecrypt {
+		t.Errorf("wrong passphrase: got %v, want %v", err, ErrDecrypt)
+	}
+}
+
+func TestKeyStoreLifecycle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	ks := NewKeyStore(dir, LightScryptN, LightScryptP)
+	if accounts, err := ks.Accounts(); err != nil || len(accounts) != 0 {
+		t.Fatalf("missing directory: got %v, %v", accounts, err)
+	}
+
+	priv, _ := ec.NewPrivateKey()
+	acct, err := ks.Store(priv, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Address != PubKeyToAddress(priv.ECPubKey()) {
+		t.Errorf("stored under %s", acct.Address)
+	}
+	name := filepath.Base(acct.Path)
+	if !strings.HasPrefix(name, "UTC--") || !strings.HasSuffix(name, "--"+strings.ToLower(acct.Address.String()[2:])) {
+		t.Errorf("key file name %s", name)
+	}
+	if fi, err := os.Stat(acct.Path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("key file mode %v, %v", fi.Mode(), err)
+	}
+	if _, err := ks.Store(priv, "again"); err != ErrExists {
+		t.Errorf("storing twice: got %v, want %v", err, ErrExists)
+	}
+
+	// Export under a new passphrase and import into another store.
+	exported, err := ks.Export(acct.Address, "first", "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	if _, err := other.Import(exported, "first", "third"); err != ErrDecrypt {
+		t.Errorf("import with old passphrase: got %v, want %v", err, ErrDecrypt)
+	}
+	imported, err := other.Import(exported, "second", "third")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := other.Key(imported.Address, "third")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.D.Cmp(priv.D) != 0 {
+		t.Error("import changed the key")
+	}
+
+	if err := ks.Delete(acct.Address, "wrong"); err != ErrDecrypt {
+		t.Errorf("delete with wrong passphrase: got %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.Delete(acct.Address, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if ks.Has(acct.Address) {
+		t.Error("key still present after Delete")
+	}
+	if _, err := ks.Key(acct.Address, "first"); err != ErrNoKey {
+		t.Errorf("deleted key: got %v, want %v", err, ErrNoKey)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("%d files left in the key store", len(entries))
+	}
+}
+
+// TestKeyStoreConcurrentStore checks that of concurrent stores of one key,
+// exactly one succeeds.
+func TestKeyStoreConcurrentStore(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	priv, _ := ec.NewPrivateKey()
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := ks.Store(priv, "concurrent")
+			errs <- err
+		}()
+	}
+	stored := 0
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err == nil {
+			stored++
+		} else if err != ErrExists {
+			t.Errorf("Store: %v", err)
+		}
+	}
+	if accounts, _ := ks.Accounts(); stored != 1 || len(accounts) != 1 {
+		t.Errorf("stores succeeded %d times, the store has %d keys", stored, len(accounts))
+	}
+}
@@ -0,0 +1,514 @@
+// MuSig2 多签名: 多个公钥聚合成一个 BIP340 公钥, 两轮交互生成一个普通的
+// Schnorr 签名.
+//
+// The functions in this file follow the algorithms of BIP327 one to one:
+// KeyAgg and ApplyTweak are AggregateKeys and KeyAggContext.Tweak, NonceGen
+// and NonceAgg are GenerateNonce and AggregateNonces, and Sign,
+// PartialSigVerify and PartialSigAgg are Sign, VerifyPartialSig and
+// AggregatePartialSigs.  Session wraps them for a single signer and makes
+// sure a secret nonce is never used twice.
+//
+// https://github.com/bitcoin/bips/blob/master/bip-0327.mediawiki
+
+package musig2
+
+import "bytes"
+import "crypto/rand"
+import "encoding/binary"
+import "fmt"
+import "io"
+import "math/big"
+import "sort"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+const PubNonceSize = 66   // 两个压缩点 R1 || R2
+const SecNonceSize = 97   // k1 || k2 || 33 字节公钥
+const PartialSigSize = 32 // 部分签名 s
+
+var (
+	ErrNoKeys            = fmt.Errorf("musig2: no public keys to aggregate")
+	ErrInfiniteKey       = fmt.Errorf("musig2: aggregate public key is the point at infinity")
+	ErrInvalidTweak      = fmt.Errorf("musig2: tweak is not less than the group order")
+	ErrInvalidSecNonce   = fmt.Errorf("musig2: secret nonce is invalid or has already been used")
+	ErrSecNonceMismatch  = fmt.Errorf("musig2: secret nonce was not generated for this private key")
+	ErrSignerNotFound    = fmt.Errorf("musig2: public key is not one of the aggregated keys")
+	ErrInvalidPartialSig = fmt.Errorf("musig2: partial signature is invalid")
+)
+
+// PubNonce is the public nonce a signer sends in the first round.
+type PubNonce [PubNonceSize]byte
+
+// SecNonce is the secret counterpart of a PubNonce.  It must be used for
+// exactly one signature, Sign wipes it.
+type SecNonce [SecNonceSize]byte
+
+// PartialSignature is a signer's share of the final signature, sent in the
+// second round.
+type PartialSignature [PartialSigSize]byte
+
+// KeyAggContext holds the aggregate public key of a set of signers along with
+// the accumulated tweaks applied to it.
+type KeyAggContext struct {
+	keys      []*ec.PublicKey
+	keyBytes  [][]byte // 压缩公钥, 和 keys 一一对应
+	keyHash   []byte   // L = hash(pk1 || ... || pku)
+	secondKey []byte   // 第一个和 pk1 不同的公钥, 系数固定为 1
+
+	qx, qy *big.Int
+	gacc   *big.Int
+	tacc   *big.Int
+}
+
+// SortKeys returns the keys sorted by their compressed encoding, which is the
+// KeySort algorithm.  Signers that sort the keys get the same aggregate key
+// regardless of the order in which they learned them.
+func SortKeys(keys []*ec.PublicKey) []*ec.PublicKey {
+	sorted := make([]*ec.PublicKey, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].SerializeCompressed(), sorted[j].SerializeCompressed()) < 0
+	})
+	return sorted
+}
+
+// AggregateKeys combines the public keys of the signers, in the given order,
+// into a single public key.
+func AggregateKeys(keys []*ec.PublicKey) (*KeyAggContext, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	curve := ec.S256()
+
+	ctx := &KeyAggContext{
+		keys:     keys,
+		keyBytes: make([][]byte, len(keys)),
+		gacc:     big.NewInt(1),
+		tacc:     big.NewInt(0),
+	}
+	for i, key := range keys {
+		ctx.keyBytes[i] = key.SerializeCompressed()
+	}
+	ctx.keyHash = ec.TaggedHash("KeyAgg list", ctx.keyBytes...)
+	for _, key := range ctx.keyBytes[1:] {
+		if !bytes.Equal(key, ctx.keyBytes[0]) {
+			ctx.secondKey = key
+			break
+		}
+	}
+
+	qx, qy := new(big.Int), new(big.Int)
+	for i, key := range keys {
+		a := ctx.coefficient(ctx.keyBytes[i])
+		ax, ay := curve.ScalarMult(key.X, key.Y, a.Bytes())
+		qx, qy = curve.Add(qx, qy, ax, ay)
+	}
+	if isInfinity(qx, qy) {
+		return nil, ErrInfiniteKey
+	}
+	ctx.qx, ctx.qy = qx, qy
+	return ctx, nil
+}
+
+// coefficient returns the KeyAgg coefficient of the compressed key pk.
+func (c *KeyAggContext) coefficient(pk []byte) *big.Int {
+	if c.secondKey != nil && bytes.Equal(pk, c.secondKey) {
+		return big.NewInt(1)
+	}
+	a := new(big.Int).SetBytes(ec.TaggedHash("KeyAgg coefficient", c.keyHash, pk))
+	return a.Mod(a, ec.S256().N)
+}
+
+// Tweak returns a new context whose aggregate key is Q + t*G for a plain
+// tweak, or the even Y lift of Q plus t*G for an x-only tweak as used by
+// BIP341 taproot outputs.
+func (c *KeyAggContext) Tweak(tweak [32]byte, isXOnly bool) (*KeyAggContext, error) {
+	curve := ec.S256()
+	n := curve.N
+
+	t := new(big.Int).SetBytes(tweak[:])
+	if t.Cmp(n) >= 0 {
+		return nil, ErrInvalidTweak
+	}
+
+	// g = -1 for x-only tweaks of keys with an odd Y, 1 otherwise.
+	negate := isXOnly && c.qy.Bit(0) == 1
+	qx, qy := c.qx, c.qy
+	gacc := new(big.Int).Set(c.gacc)
+	tacc := new(big.Int).Set(c.tacc)
+	if negate {
+		qy = new(big.Int).Sub(curve.P, qy)
+		gacc.Sub(n, gacc)
+		tacc.Sub(n, tacc)
+	}
+	tx, ty := curve.ScalarBaseMult(t.Bytes())
+	qx, qy = curve.Add(qx, qy, tx, ty)
+	if isInfinity(qx, qy) {
+		return nil, ErrInfiniteKey
+	}
+	tacc.Add(tacc, t)
+	tacc.Mod(tacc, n)
+
+	return &KeyAggContext{
+		keys:      c.keys,
+		keyBytes:  c.keyBytes,
+		keyHash:   c.keyHash,
+		secondKey: c.secondKey,
+		qx:        qx,
+		qy:        qy,
+		gacc:      gacc,
+		tacc:      tacc,
+	}, nil
+}
+
+// PublicKey returns the aggregate public key.
+func (c *KeyAggContext) PublicKey() *ec.PublicKey {
+	return &ec.PublicKey{Curve: ec.S256(), X: c.qx, Y: c.qy}
+}
+
+// XOnlyPublicKey returns the 32 byte BIP340 encoding of the aggregate public
+// key, against which the final signature verifies.
+func (c *KeyAggContext) XOnlyPublicKey() []byte {
+	return c.PublicKey().SerializeXOnly()
+}
+
+// Keys returns the public keys of the signers in aggregation order.
+func (c *KeyAggContext) Keys() []*ec.PublicKey {
+	return c.keys
+}
+
+// NonceOptions are the optional inputs of GenerateNonce.  Every one of them
+// that is known should be passed, since they make the nonce more robust
+// against a bad random number generator.
+type NonceOptions struct {
+	SecretKey   *ec.PrivateKey // the signer's private key
+	AggXOnlyKey []byte         // the x-only aggregate public key
+	Msg         []byte         // the message, nil if it's not known yet
+	ExtraIn     []byte         // any other data, e.g. a session id
+	Rand        io.Reader      // source of randomness, crypto/rand if nil
+}
+
+// GenerateNonce creates a fresh nonce pair for the signer with the given
+// public key.
+func GenerateNonce(pubKey *ec.PublicKey, opts *NonceOptions) (*SecNonce, *PubNonce, error) {
+	if opts == nil {
+		opts = &NonceOptions{}
+	}
+	r := opts.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	curve := ec.S256()
+
+	randBytes := make([]byte, 32)
+	if _, err := io.ReadFull(r, randBytes); err != nil {
+		return nil, nil, err
+	}
+	if opts.SecretKey != nil {
+		aux := ec.TaggedHash("MuSig/aux", randBytes)
+		sk := opts.SecretKey.PrivatekeyToBytes()
+		defer func() {
+			for i := range sk {
+				sk[i] = 0
+			}
+		}()
+		for i := range randBytes {
+			randBytes[i] = sk[i] ^ aux[i]
+		}
+	}
+
+	pk := pubKey.SerializeCompressed()
+	msgPrefixed := []byte{0}
+	if opts.Msg != nil {
+		msgPrefixed = make([]byte, 9, 9+len(opts.Msg))
+		msgPrefixed[0] = 1
+		binary.BigEndian.PutUint64(msgPrefixed[1:], uint64(len(opts.Msg)))
+		msgPrefixed = append(msgPrefixed, opts.Msg...)
+	}
+	var extraLen [4]byte
+	binary.BigEndian.PutUint32(extraLen[:], uint32(len(opts.ExtraIn)))
+
+	secNonce := new(SecNonce)
+	pubNonce := new(PubNonce)
+	for i := 0; i < 2; i++ {
+		k := new(big.Int).SetBytes(ec.TaggedHash("MuSig/nonce",
+			randBytes,
+			[]byte{byte(len(pk))}, pk,
+			[]byte{byte(len(opts.AggXOnlyKey))}, opts.AggXOnlyKey,
+			msgPrefixed,
+			extraLen[:], opts.ExtraIn,
+			[]byte{byte(i)}))
+		k.Mod(k, curve.N)
+		if k.Sign() == 0 {
+			return nil, nil, fmt.Errorf("musig2: generated nonce is zero")
+		}
+		k.FillBytes(secNonce[i*32 : (i+1)*32])
+
+		rx, ry := curve.ScalarBaseMult(k.Bytes())
+		r := ec.PublicKey{Curve: curve, X: rx, Y: ry}
+		copy(pubNonce[i*33:(i+1)*33], r.SerializeCompressed())
+	}
+	copy(secNonce[64:], pk)
+
+	return secNonce, pubNonce, nil
+}
+
+// AggregateNonces sums the public nonces of all signers into the aggregate
+// nonce every signer needs to produce its partial signature.  An invalid
+// nonce is reported with the index of the signer that sent it.
+func AggregateNonces(pubNonces []PubNonce) (*PubNonce, error) {
+	curve := ec.S256()
+	aggNonce := new(PubNonce)
+	for j := 0; j < 2; j++ {
+		rx, ry := new(big.Int), new(big.Int)
+		for i := range pubNonces {
+			p, err := ec.ParsePubKey(pubNonces[i][j*33:(j+1)*33], curve)
+			if err != nil {
+				return nil, fmt.Errorf("musig2: invalid public nonce from signer %d: %v", i, err)
+			}
+			rx, ry = curve.Add(rx, ry, p.X, p.Y)
+		}
+		copy(aggNonce[j*33:(j+1)*33], serializeExt(rx, ry))
+	}
+	return aggNonce, nil
+}
+
+// sessionValues are the values of GetSessionValues derived from the aggregate
+// nonce, the key aggregation context and the message.
+type sessionValues struct {
+	qx, qy *big.Int
+	gacc   *big.Int
+	tacc   *big.Int
+	b      *big.Int
+	rx, ry *big.Int
+	e      *big.Int
+}
+
+func (c *KeyAggContext) sessionValues(aggNonce *PubNonce, msg []byte) (*sessionValues, error) {
+	curve := ec.S256()
+	qXOnly := c.XOnlyPublicKey()
+
+	b := new(big.Int).SetBytes(ec.TaggedHash("MuSig/noncecoef", aggNonce[:], qXOnly, msg))
+	b.Mod(b, curve.N)
+
+	r1x, r1y, err := parseExt(aggNonce[:33])
+	if err != nil {
+		return nil, err
+	}
+	r2x, r2y, err := parseExt(aggNonce[33:])
+	if err != nil {
+		return nil, err
+	}
+	bx, by := curve.ScalarMult(r2x, r2y, b.Bytes())
+	rx, ry := curve.Add(r1x, r1y, bx, by)
+	if isInfinity(rx, ry) {
+		rx, ry = curve.Gx, curve.Gy
+	}
+
+	e := new(big.Int).SetBytes(ec.TaggedHash("BIP0340/challenge",
+		rx.FillBytes(make([]byte, 32)), qXOnly, msg))
+	e.Mod(e, curve.N)
+
+	return &sessionValues{
+		qx: c.qx, qy: c.qy,
+		gacc: c.gacc, tacc: c.tacc,
+		b:  b,
+		rx: rx, ry: ry,
+		e: e,
+	}, nil
+}
+
+// Sign produces the partial signature of the signer holding priv.  secNonce
+// is wiped before Sign returns, whether it succeeds or not, so that it can
+// never be used for a second signature.
+func Sign(secNonce *SecNonce, priv *ec.PrivateKey, keyCtx *KeyAggContext,
+	aggNonce *PubNonce, msg []byte) (*PartialSignature, error) {
+	curve := ec.S256()
+	n := curve.N
+
+	k1 := new(big.Int).SetBytes(secNonce[:32])
+	k2 := new(big.Int).SetBytes(secNonce[32:64])
+	noncePK := append([]byte{}, secNonce[64:]...)
+	for i := range secNonce {
+		secNonce[i] = 0
+	}
+	if k1.Sign() == 0 || k1.Cmp(n) >= 0 || k2.Sign() == 0 || k2.Cmp(n) >= 0 {
+		return nil, ErrInvalidSecNonce
+	}
+
+	v, err := keyCtx.sessionValues(aggNonce, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	pubNonce := new(PubNonce)
+	for i, k := range []*big.Int{k1, k2} {
+		rx, ry := curve.ScalarBaseMult(k.Bytes())
+		r := ec.PublicKey{Curve: curve, X: rx, Y: ry}
+		copy(pubNonce[i*33:(i+1)*33], r.SerializeCompressed())
+	}
+	if v.ry.Bit(0) == 1 {
+		k1.Sub(n, k1)
+		k2.Sub(n, k2)
+	}
+
+	d := new(big.Int).Set(priv.D)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("musig2: private key is out of range")
+	}
+	pk := priv.ECPubKey().SerializeCompressed()
+	if !bytes.Equal(pk, noncePK) {
+		return nil, ErrSecNonceMismatch
+	}
+	if !keyCtx.hasKey(pk) {
+		return nil, ErrSignerNotFound
+	}
+	a := keyCtx.coefficient(pk)
+
+	// d = g * gacc * d' mod n
+	if v.qy.Bit(0) == 1 {
+		d.Sub(n, d)
+	}
+	d.Mul(d, v.gacc)
+	d.Mod(d, n)
+
+	// s = k1 + b*k2 + e*a*d mod n
+	s := new(big.Int).Mul(v.e, a)
+	s.Mul(s, d)
+	s.Add(s, k1)
+	s.Add(s, new(big.Int).Mul(v.b, k2))
+	s.Mod(s, n)
+
+	psig := new(PartialSignature)
+	s.FillBytes(psig[:])
+
+	if !verifyPartialSig(psig, pubNonce, priv.ECPubKey(), keyCtx, v) {
+		return nil, ErrInvalidPartialSig
+	}
+	return psig, nil
+}
+
+// VerifyPartialSig checks the partial signature psig of the signer with the
+// given public nonce and public key.
+func VerifyPartialSig(psig *PartialSignature, pubNonce *PubNonce, pubKey *ec.PublicKey,
+	keyCtx *KeyAggContext, aggNonce *PubNonce, msg []byte) bool {
+	v, err := keyCtx.sessionValues(aggNonce, msg)
+	if err != nil {
+		return false
+	}
+	return verifyPartialSig(psig, pubNonce, pubKey, keyCtx, v)
+}
+
+func verifyPartialSig(psig *PartialSignature, pubNonce *PubNonce, pubKey *ec.PublicKey,
+	keyCtx *KeyAggContext, v *sessionValues) bool {
+	curve := ec.S256()
+	n := curve.N
+
+	s := new(big.Int).SetBytes(psig[:])
+	if s.Cmp(n) >= 0 {
+		return false
+	}
+	pk := pubKey.SerializeCompressed()
+	if !keyCtx.hasKey(pk) {
+		return false
+	}
+
+	r1, err := ec.ParsePubKey(pubNonce[:33], curve)
+	if err != nil {
+		return false
+	}
+	r2, err := ec.ParsePubKey(pubNonce[33:], curve)
+	if err != nil {
+		return false
+	}
+
+	// Re = R1 + b*R2, negated if the final nonce has an odd Y.
+	bx, by := curve.ScalarMult(r2.X, r2.Y, v.b.Bytes())
+	rex, rey := curve.Add(r1.X, r1.Y, bx, by)
+	if v.ry.Bit(0) == 1 && !isInfinity(rex, rey) {
+		rey = new(big.Int).Sub(curve.P, rey)
+	}
+
+	// g' = g * gacc mod n
+	g := new(big.Int).Set(v.gacc)
+	if v.qy.Bit(0) == 1 {
+		g.Sub(n, g)
+	}
+	// s*G == Re + e*a*g'*P
+	eag := new(big.Int).Mul(v.e, keyCtx.coefficient(pk))
+	eag.Mul(eag, g)
+	eag.Mod(eag, n)
+	px, py := curve.ScalarMult(pubKey.X, pubKey.Y, eag.Bytes())
+	rhsx, rhsy := curve.Add(rex, rey, px, py)
+	lhsx, lhsy := curve.ScalarBaseMult(s.Bytes())
+
+	return lhsx.Cmp(rhsx) == 0 && lhsy.Cmp(rhsy) == 0
+}
+
+// AggregatePartialSigs combines the partial signatures of all signers into
+// a BIP340 signature that verifies against keyCtx.XOnlyPublicKey().
+func AggregatePartialSigs(psigs []PartialSignature, keyCtx *KeyAggContext,
+	aggNonce *PubNonce, msg []byte) (*ec.SchnorrSignature, error) {
+	curve := ec.S256()
+	n := curve.N
+
+	v, err := keyCtx.sessionValues(aggNonce, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(big.Int)
+	for i := range psigs {
+		si := new(big.Int).SetBytes(psigs[i][:])
+		if si.Cmp(n) >= 0 {
+			return nil, fmt.Errorf("musig2: partial signature of signer %d is out of range", i)
+		}
+		s.Add(s, si)
+	}
+
+	// s = sum(s_i) + e*g*tacc mod n
+	et := new(big.Int).Mul(v.e, v.tacc)
+	if v.qy.Bit(0) == 1 {
+		et.Neg(et)
+	}
+	s.Add(s, et)
+	s.Mod(s, n)
+
+	return &ec.SchnorrSignature{R: new(big.Int).Set(v.rx), S: s}, nil
+}
+
+func (c *KeyAggContext) hasKey(pk []byte) bool {
+	for _, key := range c.keyBytes {
+		if bytes.Equal(key, pk) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInfinity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// serializeExt is cbytes_ext: the compressed point, or 33 zero bytes for the
+// point at infinity.
+func serializeExt(x, y *big.Int) []byte {
+	if isInfinity(x, y) {
+		return make([]byte, 33)
+	}
+	p := ec.PublicKey{Curve: ec.S256(), X: x, Y: y}
+	return p.SerializeCompressed()
+}
+
+// parseExt is cpoint_ext, the inverse of serializeExt.
+func parseExt(b []byte) (*big.Int, *big.Int, error) {
+	if bytes.Equal(b, make([]byte, 33)) {
+		return new(big.Int), new(big.Int), nil
+	}
+	p, err := ec.ParsePubKey(b, ec.S256())
+	if err != nil {
+		return nil, nil, fmt.Errorf("musig2: invalid aggregate nonce: %v", err)
+	}
+	return p.X, p.Y, nil
+}
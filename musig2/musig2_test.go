@@ -0,0 +1,276 @@
+package musig2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+// testSigners returns n deterministic private keys and their public keys.
+func testSigners(n int) ([]*ec.PrivateKey, []*ec.PublicKey) {
+	privs := make([]*ec.PrivateKey, n)
+	pubs := make([]*ec.PublicKey, n)
+	for i := range privs {
+		seed := sha256.Sum256([]byte{byte(i), 'm', 's'})
+		privs[i], pubs[i] = ec.PrivKeyFromBytes(ec.S256(), seed[:])
+	}
+	return privs, pubs
+}
+
+// TestKeyAggVectors checks the key aggregation vectors of BIP327.
+func TestKeyAggVectors(t *testing.T) {
+	var keys []*ec.PublicKey
+	for _, s := range []string{
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66",
+	} {
+		pk, err := ec.ParsePubKey(hexToBytes(s), ec.S256())
+		if err != nil {
+			t.Fatalf("ParsePubKey: %v", err)
+		}
+		keys = append(keys, pk)
+	}
+
+	tests := []struct {
+		indices  []int
+		expected string
+	}{
+		{[]int{0, 1, 2}, "90539EEDE565F5D054F32CC0C220126889ED1E5D193BAF15AEF344FE59D4610C"},
+		{[]int{2, 1, 0}, "6204DE8B083426DC6EAF9502D27024D53FC826BF7D2012148A0575435DF54B2B"},
+		{[]int{0, 0, 0}, "B436E3BAD62B8CD409969A224731C193D051162D8C5AE8B109306127DA3AA935"},
+		{[]int{0, 0, 1, 1}, "69BC22BFA5D106306E48A20679DE1D7389386124D07571D0D872686028C26A3E"},
+	}
+	for i, test := range tests {
+		var set []*ec.PublicKey
+		for _, idx := range test.indices {
+			set = append(set, keys[idx])
+		}
+		ctx, err := AggregateKeys(set)
+		if err != nil {
+			t.Fatalf("#%d: AggregateKeys: %v", i, err)
+		}
+		if got := hex.EncodeToString(ctx.XOnlyPublicKey()); !strings.EqualFold(got, test.expected) {
+			t.Errorf("#%d: aggregate key %s, want %s", i, got, test.expected)
+		}
+	}
+
+	if _, err := AggregateKeys(nil); err != ErrNoKeys {
+		t.Errorf("AggregateKeys(nil): got %v, want %v", err, ErrNoKeys)
+	}
+
+	ctx, _ := AggregateKeys(keys)
+	var order [32]byte
+	copy(order[:], hexToBytes("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141"))
+	if _, err := ctx.Tweak(order, true); err != ErrInvalidTweak {
+		t.Errorf("tweak equal to the order: got %v, want %v", err, ErrInvalidTweak)
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	_, pubs := testSigners(5)
+	sorted := SortKeys(pubs)
+	for i := 1; i < len(sorted); i++ {
+		if bytes.Compare(sorted[i-1].SerializeCompressed(), sorted[i].SerializeCompressed()) > 0 {
+			t.Fatalf("keys are not sorted")
+		}
+	}
+}
+
+// runSessions runs a complete signing round between all signers and returns
+// the final signature.
+func runSessions(t *testing.T, privs []*ec.PrivateKey, ctx *KeyAggContext, msg []byte) *ec.SchnorrSignature {
+	sessions := make([]*Session, len(privs))
+	for i, priv := range privs {
+		s, err := NewSession(priv, ctx, msg)
+		if err != nil {
+			t.Fatalf("NewSession: %v", err)
+		}
+		sessions[i] = s
+	}
+
+	for _, s := range sessions {
+		for _, other := range sessions {
+			if other == s {
+				continue
+			}
+			if _, err := s.RegisterPubNonce(other.Index(), other.PublicNonce()); err != nil {
+				t.Fatalf("RegisterPubNonce: %v", err)
+			}
+		}
+	}
+
+	psigs := make([]*PartialSignature, len(sessions))
+	for i, s := range sessions {
+		psig, err := s.Sign()
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		psigs[i] = psig
+	}
+
+	for _, s := range sessions {
+		for j, other := range sessions {
+			if other == s {
+				continue
+			}
+			done, err := s.CombineSig(other.Index(), psigs[j])
+			if err != nil {
+				t.Fatalf("CombineSig: %v", err)
+			}
+			if done != (s.FinalSig() != nil) {
+				t.Fatalf("CombineSig reported %v but final signature is %v", done, s.FinalSig())
+			}
+		}
+		if s.FinalSig() == nil {
+			t.Fatalf("session has no final signature")
+		}
+		if !s.FinalSig().IsEqual(sessions[0].FinalSig()) {
+			t.Fatalf("sessions produced different signatures")
+		}
+	}
+	return sessions[0].FinalSig()
+}
+
+func TestSessionSign(t *testing.T) {
+	privs, pubs := testSigners(3)
+	ctx, err := AggregateKeys(SortKeys(pubs))
+	if err != nil {
+		t.Fatalf("AggregateKeys: %v", err)
+	}
+	msg := sha256.Sum256([]byte("musig2"))
+
+	sig := runSessions(t, privs, ctx, msg[:])
+	aggKey, err := ec.ParseXOnlyPubKey(ctx.XOnlyPublicKey())
+	if err != nil {
+		t.Fatalf("ParseXOnlyPubKey: %v", err)
+	}
+	if !sig.Verify(msg[:], aggKey) {
+		t.Errorf("aggregate signature does not verify")
+	}
+}
+
+func TestSessionSignTweaked(t *testing.T) {
+	privs, pubs := testSigners(4)
+	ctx, _ := AggregateKeys(pubs)
+	ctx, err := ctx.Tweak(sha256.Sum256([]byte("plain")), false)
+	if err != nil {
+		t.Fatalf("Tweak: %v", err)
+	}
+	ctx, err = ctx.Tweak(sha256.Sum256([]byte("x-only")), true)
+	if err != nil {
+		t.Fatalf("Tweak: %v", err)
+	}
+	msg := sha256.Sum256([]byte("tweaked"))
+
+	sig := runSessions(t, privs, ctx, msg[:])
+	if !sig.Verify(msg[:], ctx.PublicKey()) {
+		t.Errorf("aggregate signature does not verify under the tweaked key")
+	}
+}
+
+func TestSessionNonceReuse(t *testing.T) {
+	privs, pubs := testSigners(2)
+	ctx, _ := AggregateKeys(pubs)
+	msg := sha256.Sum256([]byte("once"))
+
+	s0, _ := NewSession(privs[0], ctx, msg[:])
+	s1, _ := NewSession(privs[1], ctx, msg[:])
+	if _, err := s0.Sign(); err != ErrMissingNonces {
+		t.Errorf("Sign before nonces: got %v, want %v", err, ErrMissingNonces)
+	}
+	// A malformed nonce does not stop the signer from sending a valid one.
+	bad := s1.PublicNonce()
+	bad[33] = 0x05
+	if _, err := s0.RegisterPubNonce(s1.Index(), bad); err == nil {
+		t.Errorf("RegisterPubNonce accepted a malformed nonce")
+	}
+	done, err := s0.RegisterPubNonce(s1.Index(), s1.PublicNonce())
+	if err != nil || !done {
+		t.Fatalf("RegisterPubNonce: %v %v", done, err)
+	}
+	if _, err := s0.RegisterPubNonce(s1.Index(), s0.PublicNonce()); err != ErrNonceConflict {
+		t.Errorf("conflicting nonce: got %v, want %v", err, ErrNonceConflict)
+	}
+	if _, err := s0.RegisterPubNonce(2, s1.PublicNonce()); err != ErrSignerIndex {
+		t.Errorf("bad index: got %v, want %v", err, ErrSignerIndex)
+	}
+	if _, err := s0.Sign(); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := s0.Sign(); err != ErrNonceReused {
+		t.Errorf("second Sign: got %v, want %v", err, ErrNonceReused)
+	}
+
+	// The stateless API refuses a secret nonce that was already used.
+	secNonce, pubNonce, err := GenerateNonce(pubs[0], &NonceOptions{SecretKey: privs[0]})
+	if err != nil {
+		t.Fatalf("GenerateNonce: %v", err)
+	}
+	aggNonce, err := AggregateNonces([]PubNonce{*pubNonce, s1.PublicNonce()})
+	if err != nil {
+		t.Fatalf("AggregateNonces: %v", err)
+	}
+	if _, err := Sign(secNonce, privs[0], ctx, aggNonce, msg[:]); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := Sign(secNonce, privs[0], ctx, aggNonce, msg[:]); err != ErrInvalidSecNonce {
+		t.Errorf("reused secret nonce: got %v, want %v", err, ErrInvalidSecNonce)
+	}
+}
+
+func TestInvalidPartialSig(t *testing.T) {
+	privs, pubs := testSigners(2)
+	ctx, _ := AggregateKeys(pubs)
+	msg := sha256.Sum256([]byte("invalid"))
+
+	s0, _ := NewSession(privs[0], ctx, msg[:])
+	s1, _ := NewSession(privs[1], ctx, msg[:])
+	s0.RegisterPubNonce(s1.Index(), s1.PublicNonce())
+	s1.RegisterPubNonce(s0.Index(), s0.PublicNonce())
+	if _, err := s0.Sign(); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	psig, err := s1.Sign()
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	bad := *psig
+	bad[31] ^= 1
+	if _, err := s0.CombineSig(s1.Index(), &bad); !errors.Is(err, ErrInvalidPartialSig) {
+		t.Errorf("CombineSig of a corrupted partial signature: got %v, want %v", err, ErrInvalidPartialSig)
+	}
+	if s0.FinalSig() != nil {
+		t.Errorf("session completed with a corrupted partial signature")
+	}
+	if done, err := s0.CombineSig(s1.Index(), psig); err != nil || !done {
+		t.Errorf("CombineSig: %v %v", done, err)
+	}
+}
+
+func TestAggregateNoncesInvalid(t *testing.T) {
+	_, pubs := testSigners(1)
+	_, pubNonce, err := GenerateNonce(pubs[0], nil)
+	if err != nil {
+		t.Fatalf("GenerateNonce: %v", err)
+	}
+	bad := *pubNonce
+	bad[0] = 0x05
+	if _, err := AggregateNonces([]PubNonce{*pubNonce, bad}); err == nil {
+		t.Errorf("AggregateNonces accepted an invalid nonce")
+	}
+}
@@ -0,0 +1,188 @@
+package musig2
+
+import "fmt"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+var (
+	ErrNonceReused     = fmt.Errorf("musig2: session has already produced its partial signature")
+	ErrMissingNonces   = fmt.Errorf("musig2: public nonces of all signers are required first")
+	ErrSignerIndex     = fmt.Errorf("musig2: signer index is out of range")
+	ErrNonceConflict   = fmt.Errorf("musig2: a different public nonce was already registered for this signer")
+	ErrSessionComplete = fmt.Errorf("musig2: all partial signatures have been combined already")
+)
+
+// Session drives one signer through the two MuSig2 rounds for one message.
+//
+// Round one: send PublicNonce() to the other signers and pass theirs to
+// RegisterPubNonce.  Round two: send the result of Sign() to the other
+// signers and pass theirs to CombineSig, which verifies each of them.  Once
+// every partial signature is in, FinalSig returns the BIP340 signature.
+//
+// The secret nonce is generated by NewSession and wiped by Sign, so a session
+// can sign once only.  Signing another message requires a new session.
+type Session struct {
+	keyCtx *KeyAggContext
+	priv   *ec.PrivateKey
+	msg    []byte
+	index  int // 自己在 keyCtx.keys 里的位置
+
+	secNonce  *SecNonce
+	pubNonces []*PubNonce
+	aggNonce  *PubNonce
+
+	partialSigs []*PartialSignature
+	finalSig    *ec.SchnorrSignature
+}
+
+// NewSession starts a signing session for msg.  keyCtx must contain the public
+// key of priv and have every tweak the final signature should commit to
+// already applied.
+func NewSession(priv *ec.PrivateKey, keyCtx *KeyAggContext, msg []byte) (*Session, error) {
+	pk := priv.ECPubKey().SerializeCompressed()
+	index := -1
+	for i, key := range keyCtx.keyBytes {
+		if string(key) == string(pk) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrSignerNotFound
+	}
+
+	secNonce, pubNonce, err := GenerateNonce(priv.ECPubKey(), &NonceOptions{
+		SecretKey:   priv,
+		AggXOnlyKey: keyCtx.XOnlyPublicKey(),
+		Msg:         append([]byte{}, msg...),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		keyCtx:      keyCtx,
+		priv:        priv,
+		msg:         append([]byte{}, msg...),
+		index:       index,
+		secNonce:    secNonce,
+		pubNonces:   make([]*PubNonce, len(keyCtx.keys)),
+		partialSigs: make([]*PartialSignature, len(keyCtx.keys)),
+	}
+	s.pubNonces[index] = pubNonce
+	return s, nil
+}
+
+// Index returns the position of this signer in the aggregated keys, which is
+// how the other signers refer to it.
+func (s *Session) Index() int {
+	return s.index
+}
+
+// PublicNonce returns this signer's public nonce for round one.
+func (s *Session) PublicNonce() PubNonce {
+	return *s.pubNonces[s.index]
+}
+
+// RegisterPubNonce records the public nonce of the signer at index and
+// reports whether the nonces of all signers are now known.
+func (s *Session) RegisterPubNonce(index int, nonce PubNonce) (bool, error) {
+	if index < 0 || index >= len(s.pubNonces) {
+		return false, ErrSignerIndex
+	}
+	if have := s.pubNonces[index]; have != nil {
+		if *have != nonce {
+			return false, ErrNonceConflict
+		}
+	} else {
+		// An invalid nonce is refused before it is recorded, so that the
+		// signer can still send a valid one.
+		for j := 0; j < 2; j++ {
+			if _, err := ec.ParsePubKey(nonce[j*33:(j+1)*33], ec.S256()); err != nil {
+				return false, fmt.Errorf("musig2: invalid public nonce from signer %d: %v", index, err)
+			}
+		}
+		s.pubNonces[index] = &nonce
+	}
+
+	for _, n := range s.pubNonces {
+		if n == nil {
+			return false, nil
+		}
+	}
+	if s.aggNonce == nil {
+		nonces := make([]PubNonce, len(s.pubNonces))
+		for i, n := range s.pubNonces {
+			nonces[i] = *n
+		}
+		aggNonce, err := AggregateNonces(nonces)
+		if err != nil {
+			return false, err
+		}
+		s.aggNonce = aggNonce
+	}
+	return true, nil
+}
+
+// Sign produces this signer's partial signature for round two.  It can only
+// be called once, a second call returns ErrNonceReused.
+func (s *Session) Sign() (*PartialSignature, error) {
+	if s.secNonce == nil {
+		return nil, ErrNonceReused
+	}
+	if s.aggNonce == nil {
+		return nil, ErrMissingNonces
+	}
+
+	secNonce := s.secNonce
+	s.secNonce = nil
+	psig, err := Sign(secNonce, s.priv, s.keyCtx, s.aggNonce, s.msg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.CombineSig(s.index, psig); err != nil {
+		return nil, err
+	}
+	return psig, nil
+}
+
+// CombineSig verifies the partial signature of the signer at index and
+// records it.  It reports whether the partial signatures of all signers are
+// now known, in which case FinalSig returns the aggregate signature.
+func (s *Session) CombineSig(index int, psig *PartialSignature) (bool, error) {
+	if s.finalSig != nil {
+		return true, ErrSessionComplete
+	}
+	if index < 0 || index >= len(s.partialSigs) {
+		return false, ErrSignerIndex
+	}
+	if s.aggNonce == nil {
+		return false, ErrMissingNonces
+	}
+	if !VerifyPartialSig(psig, s.pubNonces[index], s.keyCtx.keys[index],
+		s.keyCtx, s.aggNonce, s.msg) {
+		return false, fmt.Errorf("%w: signer %d", ErrInvalidPartialSig, index)
+	}
+	s.partialSigs[index] = psig
+
+	psigs := make([]PartialSignature, len(s.partialSigs))
+	for i, p := range s.partialSigs {
+		if p == nil {
+			return false, nil
+		}
+		psigs[i] = *p
+	}
+	sig, err := AggregatePartialSigs(psigs, s.keyCtx, s.aggNonce, s.msg)
+	if err != nil {
+		return false, err
+	}
+	s.finalSig = sig
+	return true, nil
+}
+
+// FinalSig returns the aggregate BIP340 signature, or nil if some partial
+// signatures are still missing.
+func (s *Session) FinalSig() *ec.SchnorrSignature {
+	return s.finalSig
+}
@@ -0,0 +1,476 @@
+package musig2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+// The files in testdata are the nonce_gen, nonce_agg, sign_verify, tweak and
+// sig_agg test vectors of BIP327.
+
+func loadVectors(t *testing.T, name string, v interface{}) {
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+// vectorError is the error object of the failing test cases.  Signer is nil
+// when the failure is not attributed to a signer.
+type vectorError struct {
+	Type   string `json:"type"`
+	Signer *int   `json:"signer"`
+}
+
+func parsePubKeys(keys []string) []*ec.PublicKey {
+	pubs := make([]*ec.PublicKey, len(keys))
+	for i, s := range keys {
+		// Invalid keys stay nil, the error cases refer to them.
+		pubs[i], _ = ec.ParsePubKey(hexToBytes(s), ec.S256())
+	}
+	return pubs
+}
+
+// parsePubNonce returns the public nonce encoded by s, or nil if it does not
+// have the length of one.
+func parsePubNonce(s string) *PubNonce {
+	b := hexToBytes(s)
+	if len(b) != PubNonceSize {
+		return nil
+	}
+	n := new(PubNonce)
+	copy(n[:], b)
+	return n
+}
+
+// selectKeys returns the keys at indices and the position of the first
+// invalid one, or -1.
+func selectKeys(pubs []*ec.PublicKey, indices []int) ([]*ec.PublicKey, int) {
+	keys := make([]*ec.PublicKey, len(indices))
+	for i, idx := range indices {
+		if pubs[idx] == nil {
+			return nil, i
+		}
+		keys[i] = pubs[idx]
+	}
+	return keys, -1
+}
+
+// tweakedContext aggregates keys and applies the tweaks at indices.
+func tweakedContext(keys []*ec.PublicKey, tweaks []string, indices []int, isXOnly []bool) (*KeyAggContext, error) {
+	ctx, err := AggregateKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, idx := range indices {
+		var tweak [32]byte
+		copy(tweak[:], hexToBytes(tweaks[idx]))
+		if ctx, err = ctx.Tweak(tweak, isXOnly[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+func TestNonceGenVectors(t *testing.T) {
+	var vectors struct {
+		TestCases []struct {
+			Rand     string  `json:"rand_"`
+			SK       *string `json:"sk"`
+			PK       string  `json:"pk"`
+			AggPK    *string `json:"aggpk"`
+			Msg      *string `json:"msg"`
+			ExtraIn  *string `json:"extra_in"`
+			Expected string  `json:"expected"`
+		} `json:"test_cases"`
+	}
+	loadVectors(t, "nonce_gen_vectors.json", &vectors)
+
+	for i, test := range vectors.TestCases {
+		pk, err := ec.ParsePubKey(hexToBytes(test.PK), ec.S256())
+		if err != nil {
+			t.Fatalf("#%d: ParsePubKey: %v", i, err)
+		}
+		opts := &NonceOptions{Rand: bytes.NewReader(hexToBytes(test.Rand))}
+		if test.SK != nil {
+			opts.SecretKey, _ = ec.PrivKeyFromBytes(ec.S256(), hexToBytes(*test.SK))
+		}
+		if test.AggPK != nil {
+			opts.AggXOnlyKey = hexToBytes(*test.AggPK)
+		}
+		if test.Msg != nil {
+			// An empty message is not the same as no message.
+			opts.Msg = append([]byte{}, hexToBytes(*test.Msg)...)
+		}
+		if test.ExtraIn != nil {
+			opts.ExtraIn = hexToBytes(*test.ExtraIn)
+		}
+
+		secNonce, pubNonce, err := GenerateNonce(pk, opts)
+		if err != nil {
+			t.Fatalf("#%d: GenerateNonce: %v", i, err)
+		}
+		if got := hex.EncodeToString(secNonce[:]); !strings.EqualFold(got, test.Expected) {
+			t.Errorf("#%d: secret nonce %s, want %s", i, got, test.Expected)
+		}
+		for j := 0; j < 2; j++ {
+			priv, _ := ec.PrivKeyFromBytes(ec.S256(), secNonce[j*32:(j+1)*32])
+			if !bytes.Equal(pubNonce[j*33:(j+1)*33], priv.ECPubKey().SerializeCompressed()) {
+				t.Errorf("#%d: public nonce does not match the secret nonce", i)
+			}
+		}
+	}
+}
+
+func TestNonceAggVectors(t *testing.T) {
+	var vectors struct {
+		PubNonces []string `json:"pnonces"`
+		Valid     []struct {
+			Indices  []int  `json:"pnonce_indices"`
+			Expected string `json:"expected"`
+		} `json:"valid_test_cases"`
+		Errors []struct {
+			Indices []int       `json:"pnonce_indices"`
+			Error   vectorError `json:"error"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "nonce_agg_vectors.json", &vectors)
+
+	pubNonces := func(indices []int) []PubNonce {
+		nonces := make([]PubNonce, len(indices))
+		for i, idx := range indices {
+			nonces[i] = *parsePubNonce(vectors.PubNonces[idx])
+		}
+		return nonces
+	}
+
+	for i, test := range vectors.Valid {
+		aggNonce, err := AggregateNonces(pubNonces(test.Indices))
+		if err != nil {
+			t.Errorf("valid #%d: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(aggNonce[:]); !strings.EqualFold(got, test.Expected) {
+			t.Errorf("valid #%d: aggregate nonce %s, want %s", i, got, test.Expected)
+		}
+	}
+
+	for i, test := range vectors.Errors {
+		_, err := AggregateNonces(pubNonces(test.Indices))
+		if err == nil {
+			t.Errorf("error #%d: AggregateNonces succeeded", i)
+			continue
+		}
+		if want := fmt.Sprintf("signer %d", *test.Error.Signer); !strings.Contains(err.Error(), want) {
+			t.Errorf("error #%d: %v, want an error for %s", i, err, want)
+		}
+	}
+}
+
+func TestSignVerifyVectors(t *testing.T) {
+	var vectors struct {
+		SK        string   `json:"sk"`
+		PubKeys   []string `json:"pubkeys"`
+		SecNonces []string `json:"secnonces"`
+		PubNonces []string `json:"pnonces"`
+		AggNonces []string `json:"aggnonces"`
+		Msgs      []string `json:"msgs"`
+		Valid     []struct {
+			KeyIndices    []int  `json:"key_indices"`
+			NonceIndices  []int  `json:"nonce_indices"`
+			AggNonceIndex int    `json:"aggnonce_index"`
+			MsgIndex      int    `json:"msg_index"`
+			SignerIndex   int    `json:"signer_index"`
+			Expected      string `json:"expected"`
+		} `json:"valid_test_cases"`
+		SignErrors []struct {
+			KeyIndices    []int       `json:"key_indices"`
+			AggNonceIndex int         `json:"aggnonce_index"`
+			MsgIndex      int         `json:"msg_index"`
+			SecNonceIndex int         `json:"secnonce_index"`
+			Error         vectorError `json:"error"`
+			Comment       string      `json:"comment"`
+		} `json:"sign_error_test_cases"`
+		VerifyFails []struct {
+			Sig          string `json:"sig"`
+			KeyIndices   []int  `json:"key_indices"`
+			NonceIndices []int  `json:"nonce_indices"`
+			MsgIndex     int    `json:"msg_index"`
+			SignerIndex  int    `json:"signer_index"`
+			Comment      string `json:"comment"`
+		} `json:"verify_fail_test_cases"`
+		VerifyErrors []struct {
+			KeyIndices   []int       `json:"key_indices"`
+			NonceIndices []int       `json:"nonce_indices"`
+			SignerIndex  int         `json:"signer_index"`
+			Error        vectorError `json:"error"`
+			Comment      string      `json:"comment"`
+		} `json:"verify_error_test_cases"`
+	}
+	loadVectors(t, "sign_verify_vectors.json", &vectors)
+
+	priv, _ := ec.PrivKeyFromBytes(ec.S256(), hexToBytes(vectors.SK))
+	pubs := parsePubKeys(vectors.PubKeys)
+	secNonce := func(idx int) *SecNonce {
+		// Sign wipes the secret nonce, every case gets a fresh copy.
+		n := new(SecNonce)
+		copy(n[:], hexToBytes(vectors.SecNonces[idx]))
+		return n
+	}
+
+	for i, test := range vectors.Valid {
+		keys, bad := selectKeys(pubs, test.KeyIndices)
+		if bad >= 0 {
+			t.Fatalf("valid #%d: key %d is invalid", i, bad)
+		}
+		ctx, err := AggregateKeys(keys)
+		if err != nil {
+			t.Fatalf("valid #%d: AggregateKeys: %v", i, err)
+		}
+		aggNonce := parsePubNonce(vectors.AggNonces[test.AggNonceIndex])
+		msg := hexToBytes(vectors.Msgs[test.MsgIndex])
+
+		nonces := make([]PubNonce, len(test.NonceIndices))
+		for j, idx := range test.NonceIndices {
+			nonces[j] = *parsePubNonce(vectors.PubNonces[idx])
+		}
+		if got, err := AggregateNonces(nonces); err != nil || *got != *aggNonce {
+			t.Errorf("valid #%d: AggregateNonces does not give the aggregate nonce: %v", i, err)
+		}
+
+		psig, err := Sign(secNonce(0), priv, ctx, aggNonce, msg)
+		if err != nil {
+			t.Errorf("valid #%d: Sign: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(psig[:]); !strings.EqualFold(got, test.Expected) {
+			t.Errorf("valid #%d: partial signature %s, want %s", i, got, test.Expected)
+		}
+		if !VerifyPartialSig(psig, &nonces[test.SignerIndex], keys[test.SignerIndex], ctx, aggNonce, msg) {
+			t.Errorf("valid #%d: partial signature does not verify", i)
+		}
+	}
+
+	for i, test := range vectors.SignErrors {
+		keys, bad := selectKeys(pubs, test.KeyIndices)
+		if bad >= 0 {
+			if test.Error.Signer == nil || *test.Error.Signer != bad {
+				t.Errorf("sign error #%d: key %d is invalid, want %s", i, bad, test.Comment)
+			}
+			continue
+		}
+		ctx, err := AggregateKeys(keys)
+		if err != nil {
+			t.Fatalf("sign error #%d: AggregateKeys: %v", i, err)
+		}
+		aggNonce := parsePubNonce(vectors.AggNonces[test.AggNonceIndex])
+		msg := hexToBytes(vectors.Msgs[test.MsgIndex])
+		if _, err := Sign(secNonce(test.SecNonceIndex), priv, ctx, aggNonce, msg); err == nil {
+			t.Errorf("sign error #%d: Sign succeeded: %s", i, test.Comment)
+		}
+	}
+
+	for i, test := range vectors.VerifyFails {
+		keys, _ := selectKeys(pubs, test.KeyIndices)
+		ctx, err := AggregateKeys(keys)
+		if err != nil {
+			t.Fatalf("verify fail #%d: AggregateKeys: %v", i, err)
+		}
+		nonces := make([]PubNonce, len(test.NonceIndices))
+		for j, idx := range test.NonceIndices {
+			nonces[j] = *parsePubNonce(vectors.PubNonces[idx])
+		}
+		aggNonce, err := AggregateNonces(nonces)
+		if err != nil {
+			t.Fatalf("verify fail #%d: AggregateNonces: %v", i, err)
+		}
+		var psig PartialSignature
+		copy(psig[:], hexToBytes(test.Sig))
+		if VerifyPartialSig(&psig, &nonces[test.SignerIndex], keys[test.SignerIndex], ctx, aggNonce,
+			hexToBytes(vectors.Msgs[test.MsgIndex])) {
+			t.Errorf("verify fail #%d: partial signature verifies: %s", i, test.Comment)
+		}
+	}
+
+	// The invalid contributions are caught when the keys and nonces are
+	// decoded, before any partial signature can be verified.
+	for i, test := range vectors.VerifyErrors {
+		bad := -1
+		if _, k := selectKeys(pubs, test.KeyIndices); k >= 0 {
+			bad = k
+		}
+		for j, idx := range test.NonceIndices {
+			if bad < 0 && parsePubNonce(vectors.PubNonces[idx]) == nil {
+				bad = j
+			}
+		}
+		if bad != *test.Error.Signer {
+			t.Errorf("verify error #%d: invalid contribution from signer %d, want %d: %s",
+				i, bad, *test.Error.Signer, test.Comment)
+		}
+	}
+}
+
+func TestTweakVectors(t *testing.T) {
+	var vectors struct {
+		SK        string   `json:"sk"`
+		PubKeys   []string `json:"pubkeys"`
+		SecNonce  string   `json:"secnonce"`
+		PubNonces []string `json:"pnonces"`
+		AggNonce  string   `json:"aggnonce"`
+		Tweaks    []string `json:"tweaks"`
+		Msg       string   `json:"msg"`
+		Valid     []struct {
+			KeyIndices   []int  `json:"key_indices"`
+			NonceIndices []int  `json:"nonce_indices"`
+			TweakIndices []int  `json:"tweak_indices"`
+			IsXOnly      []bool `json:"is_xonly"`
+			SignerIndex  int    `json:"signer_index"`
+			Expected     string `json:"expected"`
+			Comment      string `json:"comment"`
+		} `json:"valid_test_cases"`
+		Errors []struct {
+			KeyIndices   []int  `json:"key_indices"`
+			TweakIndices []int  `json:"tweak_indices"`
+			IsXOnly      []bool `json:"is_xonly"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "tweak_vectors.json", &vectors)
+
+	priv, _ := ec.PrivKeyFromBytes(ec.S256(), hexToBytes(vectors.SK))
+	pubs := parsePubKeys(vectors.PubKeys)
+	aggNonce := parsePubNonce(vectors.AggNonce)
+	msg := hexToBytes(vectors.Msg)
+
+	for i, test := range vectors.Valid {
+		keys, _ := selectKeys(pubs, test.KeyIndices)
+		ctx, err := tweakedContext(keys, vectors.Tweaks, test.TweakIndices, test.IsXOnly)
+		if err != nil {
+			t.Errorf("valid #%d: %v: %s", i, err, test.Comment)
+			continue
+		}
+
+		nonces := make([]PubNonce, len(test.NonceIndices))
+		for j, idx := range test.NonceIndices {
+			nonces[j] = *parsePubNonce(vectors.PubNonces[idx])
+		}
+		if got, err := AggregateNonces(nonces); err != nil || *got != *aggNonce {
+			t.Errorf("valid #%d: AggregateNonces does not give the aggregate nonce: %v", i, err)
+		}
+
+		secNonce := new(SecNonce)
+		copy(secNonce[:], hexToBytes(vectors.SecNonce))
+		psig, err := Sign(secNonce, priv, ctx, aggNonce, msg)
+		if err != nil {
+			t.Errorf("valid #%d: Sign: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(psig[:]); !strings.EqualFold(got, test.Expected) {
+			t.Errorf("valid #%d: partial signature %s, want %s: %s", i, got, test.Expected, test.Comment)
+		}
+		if !VerifyPartialSig(psig, &nonces[test.SignerIndex], keys[test.SignerIndex], ctx, aggNonce, msg) {
+			t.Errorf("valid #%d: partial signature does not verify", i)
+		}
+	}
+
+	for i, test := range vectors.Errors {
+		keys, _ := selectKeys(pubs, test.KeyIndices)
+		if _, err := tweakedContext(keys, vectors.Tweaks, test.TweakIndices, test.IsXOnly); err != ErrInvalidTweak {
+			t.Errorf("error #%d: got %v, want %v", i, err, ErrInvalidTweak)
+		}
+	}
+}
+
+func TestSigAggVectors(t *testing.T) {
+	var vectors struct {
+		PubKeys   []string `json:"pubkeys"`
+		PubNonces []string `json:"pnonces"`
+		Tweaks    []string `json:"tweaks"`
+		PSigs     []string `json:"psigs"`
+		Msg       string   `json:"msg"`
+		Valid     []struct {
+			AggNonce     string `json:"aggnonce"`
+			NonceIndices []int  `json:"nonce_indices"`
+			KeyIndices   []int  `json:"key_indices"`
+			TweakIndices []int  `json:"tweak_indices"`
+			IsXOnly      []bool `json:"is_xonly"`
+			PSigIndices  []int  `json:"psig_indices"`
+			Expected     string `json:"expected"`
+		} `json:"valid_test_cases"`
+		Errors []struct {
+			AggNonce     string      `json:"aggnonce"`
+			KeyIndices   []int       `json:"key_indices"`
+			TweakIndices []int       `json:"tweak_indices"`
+			IsXOnly      []bool      `json:"is_xonly"`
+			PSigIndices  []int       `json:"psig_indices"`
+			Error        vectorError `json:"error"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "sig_agg_vectors.json", &vectors)
+
+	pubs := parsePubKeys(vectors.PubKeys)
+	msg := hexToBytes(vectors.Msg)
+	psigs := func(indices []int) []PartialSignature {
+		sigs := make([]PartialSignature, len(indices))
+		for i, idx := range indices {
+			copy(sigs[i][:], hexToBytes(vectors.PSigs[idx]))
+		}
+		return sigs
+	}
+
+	for i, test := range vectors.Valid {
+		keys, _ := selectKeys(pubs, test.KeyIndices)
+		ctx, err := tweakedContext(keys, vectors.Tweaks, test.TweakIndices, test.IsXOnly)
+		if err != nil {
+			t.Fatalf("valid #%d: %v", i, err)
+		}
+		aggNonce := parsePubNonce(test.AggNonce)
+
+		nonces := make([]PubNonce, len(test.NonceIndices))
+		for j, idx := range test.NonceIndices {
+			nonces[j] = *parsePubNonce(vectors.PubNonces[idx])
+		}
+		if got, err := AggregateNonces(nonces); err != nil || *got != *aggNonce {
+			t.Errorf("valid #%d: AggregateNonces does not give the aggregate nonce: %v", i, err)
+		}
+
+		sig, err := AggregatePartialSigs(psigs(test.PSigIndices), ctx, aggNonce, msg)
+		if err != nil {
+			t.Errorf("valid #%d: AggregatePartialSigs: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(sig.Serialize()); !strings.EqualFold(got, test.Expected) {
+			t.Errorf("valid #%d: signature %s, want %s", i, got, test.Expected)
+		}
+		if !sig.Verify(msg, ctx.PublicKey()) {
+			t.Errorf("valid #%d: signature does not verify", i)
+		}
+	}
+
+	for i, test := range vectors.Errors {
+		keys, _ := selectKeys(pubs, test.KeyIndices)
+		ctx, err := tweakedContext(keys, vectors.Tweaks, test.TweakIndices, test.IsXOnly)
+		if err != nil {
+			t.Fatalf("error #%d: %v", i, err)
+		}
+		_, err = AggregatePartialSigs(psigs(test.PSigIndices), ctx, parsePubNonce(test.AggNonce), msg)
+		if err == nil {
+			t.Errorf("error #%d: AggregatePartialSigs succeeded", i)
+			continue
+		}
+		if want := fmt.Sprintf("signer %d", *test.Error.Signer); !strings.Contains(err.Error(), want) {
+			t.Errorf("error #%d: %v, want an error for %s", i, err, want)
+		}
+	}
+}
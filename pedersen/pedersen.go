@@ -0,0 +1,212 @@
+// Pedersen 承诺: C = r*G + v*H, 隐藏金额 v 的同时保持加法同态, 用来做机密交易.
+//
+// G is the usual secp256k1 base point and carries the blinding factor r, H is
+// a second generator nobody knows the discrete logarithm of and carries the
+// value v.  H is the x coordinate SHA256(uncompressed G) lifted to the curve,
+// the same "nothing up my sleeve" generator as libsecp256k1-zkp, so
+// commitments are interchangeable with Elements and Mimblewimble
+// implementations.
+//
+// Commitments add and subtract like the values they hide, so a transaction
+// balances when the input commitments minus the output commitments are a
+// commitment to zero, which VerifySum checks given the excess blinding
+// factors computed with SumBlindingFactors.
+
+package pedersen
+
+import "bytes"
+import "crypto/rand"
+import "crypto/sha256"
+import "fmt"
+import "io"
+import "math/big"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// CommitmentSize is the length of a serialized commitment.
+const CommitmentSize = 33
+
+var (
+	ErrInvalidBlindingFactor = fmt.Errorf("pedersen: blinding factor is not less than the group order")
+	ErrInvalidCommitment     = fmt.Errorf("pedersen: malformed commitment")
+)
+
+// H is the value generator.  It is deliberately not derived with the RFC
+// 9380 elliptic.HashToCurve: it reproduces secp256k1_generator_h of
+// libsecp256k1-zkp, which lifts SHA256 of the uncompressed G to the curve,
+// so that commitments interoperate with Elements and Mimblewimble.
+var H = GeneratorFromSeed((&ec.PublicKey{Curve: ec.S256(), X: ec.S256().Gx, Y: ec.S256().Gy}).SerializeUncompressed())
+
+// BlindingFactor is the 32 byte big endian secret r of a commitment.
+type BlindingFactor [32]byte
+
+// Commitment is a Pedersen commitment r*G + v*H.  Unlike a public key it may
+// be the point at infinity, e.g. the difference of two equal commitments.
+type Commitment struct {
+	X, Y *big.Int
+}
+
+// GeneratorFromSeed derives a point with unknown discrete logarithm from
+// seed by try-and-increment: x = SHA256(seed) is lifted to the point with an
+// even Y, and x is hashed again until it is the X coordinate of a point.
+// This is the derivation of the libsecp256k1-zkp value generator, see H.
+func GeneratorFromSeed(seed []byte) *ec.PublicKey {
+	x := sha256.Sum256(seed)
+	for {
+		if p, err := ec.ParseXOnlyPubKey(x[:]); err == nil {
+			return p
+		}
+		x = sha256.Sum256(x[:])
+	}
+}
+
+// NewBlindingFactor returns a random blinding factor read from r, or from
+// crypto/rand if r is nil.
+func NewBlindingFactor(r io.Reader) (*BlindingFactor, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	b := new(BlindingFactor)
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(b[:])
+		if k.Sign() > 0 && k.Cmp(ec.S256().N) < 0 {
+			return b, nil
+		}
+	}
+}
+
+// Scalar returns the blinding factor as an integer, checking that it is less
+// than the group order.
+func (b *BlindingFactor) Scalar() (*big.Int, error) {
+	k := new(big.Int).SetBytes(b[:])
+	if k.Cmp(ec.S256().N) >= 0 {
+		return nil, ErrInvalidBlindingFactor
+	}
+	return k, nil
+}
+
+// Commit returns the commitment blind*G + value*H.
+func Commit(value uint64, blind *BlindingFactor) (*Commitment, error) {
+	r, err := blind.Scalar()
+	if err != nil {
+		return nil, err
+	}
+	return CommitScalar(new(big.Int).SetUint64(value), r), nil
+}
+
+// CommitScalar returns the commitment r*G + v*H for arbitrary scalars, which
+// range proofs need for their intermediate commitments.
+func CommitScalar(v, r *big.Int) *Commitment {
+	curve := ec.S256()
+	rx, ry := curve.ScalarBaseMult(r.FillBytes(make([]byte, 32)))
+	vx, vy := curve.ScalarMult(H.X, H.Y, v.FillBytes(make([]byte, 32)))
+	x, y := curve.Add(rx, ry, vx, vy)
+	return &Commitment{X: x, Y: y}
+}
+
+// Open reports whether c is a commitment to value with the blinding factor
+// blind.
+func (c *Commitment) Open(value uint64, blind *BlindingFactor) bool {
+	other, err := Commit(value, blind)
+	return err == nil && c.Equal(other)
+}
+
+// Add returns the commitment c + o, which commits to the sum of the values
+// with the sum of the blinding factors.
+func (c *Commitment) Add(o *Commitment) *Commitment {
+	x, y := ec.S256().Add(c.X, c.Y, o.X, o.Y)
+	return &Commitment{X: new(big.Int).Set(x), Y: new(big.Int).Set(y)}
+}
+
+// Sub returns the commitment c - o.
+func (c *Commitment) Sub(o *Commitment) *Commitment {
+	return c.Add(o.Negate())
+}
+
+// Negate returns -c.
+func (c *Commitment) Negate() *Commitment {
+	if c.IsInfinity() {
+		return &Commitment{X: new(big.Int), Y: new(big.Int)}
+	}
+	return &Commitment{X: new(big.Int).Set(c.X), Y: new(big.Int).Sub(ec.S256().P, c.Y)}
+}
+
+// IsInfinity reports whether c is the point at infinity, which is the
+// commitment to zero with a zero blinding factor.
+func (c *Commitment) IsInfinity() bool {
+	return c.X.Sign() == 0 && c.Y.Sign() == 0
+}
+
+// Equal reports whether both commitments are the same point.
+func (c *Commitment) Equal(o *Commitment) bool {
+	return c.X.Cmp(o.X) == 0 && c.Y.Cmp(o.Y) == 0
+}
+
+// Serialize returns the compressed point encoding of the commitment, or 33
+// zero bytes for the point at infinity.
+func (c *Commitment) Serialize() []byte {
+	if c.IsInfinity() {
+		return make([]byte, CommitmentSize)
+	}
+	return (&ec.PublicKey{Curve: ec.S256(), X: c.X, Y: c.Y}).SerializeCompressed()
+}
+
+// ParseCommitment parses a commitment encoded by Serialize.
+func ParseCommitment(b []byte) (*Commitment, error) {
+	if len(b) != CommitmentSize {
+		return nil, ErrInvalidCommitment
+	}
+	if bytes.Equal(b, make([]byte, CommitmentSize)) {
+		return &Commitment{X: new(big.Int), Y: new(big.Int)}, nil
+	}
+	p, err := ec.ParsePubKey(b, ec.S256())
+	if err != nil {
+		return nil, ErrInvalidCommitment
+	}
+	return &Commitment{X: p.X, Y: p.Y}, nil
+}
+
+// SumBlindingFactors returns sum(positive) - sum(negative) mod N.  The
+// blinding factor of a change output that makes a transaction balance is
+// the sum of the input factors minus the other output factors.
+func SumBlindingFactors(positive, negative []*BlindingFactor) (*BlindingFactor, error) {
+	n := ec.S256().N
+	sum := new(big.Int)
+	for _, b := range positive {
+		k, err := b.Scalar()
+		if err != nil {
+			return nil, err
+		}
+		sum.Add(sum, k)
+	}
+	for _, b := range negative {
+		k, err := b.Scalar()
+		if err != nil {
+			return nil, err
+		}
+		sum.Sub(sum, k)
+	}
+	sum.Mod(sum, n)
+
+	out := new(BlindingFactor)
+	sum.FillBytes(out[:])
+	return out, nil
+}
+
+// VerifySum reports whether sum(positive) - sum(negative) == excess, where
+// excess is typically a commitment to zero whose blinding factor is proven
+// by a signature, or the point at infinity when the blinding factors cancel.
+func VerifySum(positive, negative []*Commitment, excess *Commitment) bool {
+	sum := &Commitment{X: new(big.Int), Y: new(big.Int)}
+	for _, c := range positive {
+		sum = sum.Add(c)
+	}
+	for _, c := range negative {
+		sum = sum.Sub(c)
+	}
+	return sum.Equal(excess)
+}
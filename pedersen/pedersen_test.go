@@ -0,0 +1,93 @@
+package pedersen
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestGeneratorH(t *testing.T) {
+	// secp256k1_generator_h, the value generator of libsecp256k1-zkp.
+	wantX := "50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0"
+	wantY := "31d3c6863973926e049e637cb1b5f40a36dac28af1766968c30c2313f3a38904"
+	if got := hex.EncodeToString(H.X.FillBytes(make([]byte, 32))); got != wantX {
+		t.Errorf("H.X = %s, want %s", got, wantX)
+	}
+	if got := hex.EncodeToString(H.Y.FillBytes(make([]byte, 32))); got != wantY {
+		t.Errorf("H.Y = %s, want %s", got, wantY)
+	}
+}
+
+func TestHomomorphism(t *testing.T) {
+	b1, _ := NewBlindingFactor(nil)
+	b2, _ := NewBlindingFactor(nil)
+	c1, err := Commit(700, b1)
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	c2, _ := Commit(300, b2)
+
+	sum, err := SumBlindingFactors([]*BlindingFactor{b1, b2}, nil)
+	if err != nil {
+		t.Fatalf("SumBlindingFactors: %v", err)
+	}
+	if !c1.Add(c2).Open(1000, sum) {
+		t.Errorf("sum of commitments does not open to the sum of values")
+	}
+	diff, _ := SumBlindingFactors([]*BlindingFactor{b1}, []*BlindingFactor{b2})
+	if !c1.Sub(c2).Open(400, diff) {
+		t.Errorf("difference of commitments does not open to the difference of values")
+	}
+	if c1.Open(701, b1) || c1.Open(700, b2) {
+		t.Errorf("commitment opens to the wrong value or blinding factor")
+	}
+
+	zero := c1.Sub(c1)
+	if !zero.IsInfinity() {
+		t.Errorf("c - c is not the point at infinity")
+	}
+	parsed, err := ParseCommitment(zero.Serialize())
+	if err != nil || !parsed.IsInfinity() {
+		t.Errorf("point at infinity did not round trip: %v", err)
+	}
+	parsed, err = ParseCommitment(c1.Serialize())
+	if err != nil || !parsed.Equal(c1) {
+		t.Errorf("commitment did not round trip: %v", err)
+	}
+}
+
+// TestBalance checks a transaction with two inputs and two outputs, the
+// change output's blinding factor chosen so that the blinding factors cancel.
+func TestBalance(t *testing.T) {
+	in1, _ := NewBlindingFactor(nil)
+	in2, _ := NewBlindingFactor(nil)
+	out1, _ := NewBlindingFactor(nil)
+	change, err := SumBlindingFactors([]*BlindingFactor{in1, in2}, []*BlindingFactor{out1})
+	if err != nil {
+		t.Fatalf("SumBlindingFactors: %v", err)
+	}
+
+	cIn1, _ := Commit(50, in1)
+	cIn2, _ := Commit(25, in2)
+	cOut1, _ := Commit(60, out1)
+	cChange, _ := Commit(15, change)
+	zero := &Commitment{X: new(big.Int), Y: new(big.Int)}
+
+	if !VerifySum([]*Commitment{cIn1, cIn2}, []*Commitment{cOut1, cChange}, zero) {
+		t.Errorf("balanced transaction does not verify")
+	}
+	cChange, _ = Commit(16, change)
+	if VerifySum([]*Commitment{cIn1, cIn2}, []*Commitment{cOut1, cChange}, zero) {
+		t.Errorf("transaction creating money verifies")
+	}
+}
+
+func TestInvalidBlindingFactor(t *testing.T) {
+	var b BlindingFactor
+	for i := range b {
+		b[i] = 0xff
+	}
+	if _, err := Commit(1, &b); err != ErrInvalidBlindingFactor {
+		t.Errorf("got %v, want %v", err, ErrInvalidBlindingFactor)
+	}
+}
@@ -0,0 +1,145 @@
+package signer
+
+import "bufio"
+import "encoding/hex"
+import "encoding/json"
+import "fmt"
+import "net"
+import "sync"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// Client is a SchnorrSigner for a key of a signing service on a Unix
+// socket, see Server.  The service is not trusted blindly: every signature
+// it returns is verified against the public key fetched by Dial.  A Client
+// is safe for concurrent use; requests are sent one at a time.
+type Client struct {
+	key string
+	pub *ec.PublicKey
+
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to the signing service at the socket path and returns a
+// client for the named key.
+func Dial(path, key string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("signer: %v", err)
+	}
+	c := &Client{
+		key:  key,
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(bufio.NewReader(conn)),
+	}
+	b, err := c.call(&request{Method: methodPublicKey})
+	if err == nil {
+		c.pub, err = ec.ParsePubKey(b, ec.S256())
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection to the service.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// PublicKey returns the public key of the remote key.
+func (c *Client) PublicKey() *ec.PublicKey {
+	return c.pub
+}
+
+// SignHash returns an ECDSA signature of the hash made by the service.
+func (c *Client) SignHash(hash []byte) (*ec.Signature, error) {
+	b, err := c.sign(methodSignHash, hash, false)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ec.ParseDERSignature(b, ec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("signer: malformed signature from service: %v", err)
+	}
+	if !sig.Verify(hash, c.pub) {
+		return nil, ErrBadSigning
+	}
+	return sig, nil
+}
+
+// SignCompact returns a recoverable signature of the hash made by the
+// service.
+func (c *Client) SignCompact(hash []byte, isCompressedKey bool) ([]byte, error) {
+	b, err := c.sign(methodSignCompact, hash, isCompressedKey)
+	if err != nil {
+		return nil, err
+	}
+	pub, compressed, err := ec.RecoverCompact(ec.S256(), b, hash)
+	if err != nil || compressed != isCompressedKey ||
+		pub.X.Cmp(c.pub.X) != 0 || pub.Y.Cmp(c.pub.Y) != 0 {
+		return nil, ErrBadSigning
+	}
+	return b, nil
+}
+
+// SignSchnorr returns a BIP340 signature of the hash made by the service,
+// or ErrNoSchnorr if the key of the service does not support it.
+func (c *Client) SignSchnorr(hash []byte) (*ec.SchnorrSignature, error) {
+	b, err := c.sign(methodSignSchnorr, hash, false)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ec.ParseSchnorrSignature(b)
+	if err != nil {
+		return nil, fmt.Errorf("signer: malformed signature from service: %v", err)
+	}
+	if !sig.Verify(hash, c.pub) {
+		return nil, ErrBadSigning
+	}
+	return sig, nil
+}
+
+// sign sends a signing request for the hash.
+func (c *Client) sign(method string, hash []byte, compressed bool) ([]byte, error) {
+	if len(hash) != HashSize {
+		return nil, ErrHashSize
+	}
+	return c.call(&request{Method: method, Hash: hex.EncodeToString(hash), Compressed: compressed})
+}
+
+// call sends a request for the key of the client and returns the decoded
+// result.
+func (c *Client) call(req *request) ([]byte, error) {
+	req.Key = c.key
+	var resp response
+
+	c.mu.Lock()
+	err := c.enc.Encode(req)
+	if err == nil {
+		err = c.dec.Decode(&resp)
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("signer: %v", err)
+	}
+
+	switch resp.Error {
+	case "":
+	case ErrNoSchnorr.Error():
+		return nil, ErrNoSchnorr
+	default:
+		return nil, fmt.Errorf("signer: service: %s", resp.Error)
+	}
+	b, err := hex.DecodeString(resp.Result)
+	if err != nil {
+		return nil, fmt.Errorf("signer: malformed result from service: %v", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,85 @@
+package signer
+
+import "strconv"
+import "strings"
+import "fmt"
+
+// import hd "../hdkeychain"
+import hd "github.com/symphonyprotocol/sutil/hdkeychain"
+
+// Keyring hands out signers for the keys below an extended private key,
+// addressed by BIP32 paths such as "m/44'/60'/0'/0/0".
+type Keyring struct {
+	master *hd.ExtendedKey
+}
+
+// NewKeyring returns a keyring for the extended private key, usually the
+// master key of a seed.  The keyring uses the key directly, so zeroing it
+// disables the keyring.
+func NewKeyring(master *hd.ExtendedKey) (*Keyring, error) {
+	if _, err := master.ECPrivKey(); err != nil {
+		return nil, fmt.Errorf("signer: keyring needs an extended private key: %v", err)
+	}
+	return &Keyring{master: master}, nil
+}
+
+// Signer returns the signer of the key at path, relative to the key of the
+// keyring.
+func (k *Keyring) Signer(path string) (*Local, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k.master
+	for _, idx := range indexes {
+		child, err := key.Child(idx)
+		if key != k.master {
+			key.Zero()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("signer: deriving %s: %v", path, err)
+		}
+		key = child
+	}
+
+	priv, err := key.ECPrivKey()
+	if key != k.master {
+		key.Zero()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewLocal(priv), nil
+}
+
+// Zero wipes the extended key of the keyring.
+func (k *Keyring) Zero() {
+	k.master.Zero()
+}
+
+// ParsePath parses a BIP32 path of the form m/a/b'/c into child indexes.
+// A ' or h suffix marks a hardened index, which is offset by
+// hdkeychain.HardenedKeyStart.  "m" alone is the empty path.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("signer: path %q does not start with m", path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		hardened := strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h")
+		if hardened {
+			p = p[:len(p)-1]
+		}
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || n >= hd.HardenedKeyStart {
+			return nil, fmt.Errorf("signer: invalid index %q in path %q", p, path)
+		}
+		idx := uint32(n)
+		if hardened {
+			idx += hd.HardenedKeyStart
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
@@ -0,0 +1,258 @@
+package signer
+
+import "bufio"
+import "encoding/hex"
+import "encoding/json"
+import "errors"
+import "fmt"
+import "net"
+import "os"
+import "path/filepath"
+import "sync"
+import "syscall"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// The signing service speaks newline delimited JSON over a Unix socket.
+// Each request names a key of the server and a method, and gets exactly one
+// response.  Hashes and results are hex encoded:
+//
+//   {"key":"node","method":"publicKey"}
+//   {"key":"node","method":"signHash","hash":"..."}
+//   {"key":"node","method":"signCompact","hash":"...","compressed":true}
+//   {"key":"node","method":"signSchnorr","hash":"..."}
+//
+//   {"result":"..."}  or  {"error":"..."}
+//
+// publicKey returns the 33 byte compressed key, signHash a DER signature,
+// signCompact a 65 byte compact signature and signSchnorr a 64 byte BIP340
+// signature.  Access control is left to the permissions of the socket.
+
+const (
+	methodPublicKey   = "publicKey"
+	methodSignHash    = "signHash"
+	methodSignCompact = "signCompact"
+	methodSignSchnorr = "signSchnorr"
+)
+
+type request struct {
+	Key        string `json:"key"`
+	Method     string `json:"method"`
+	Hash       string `json:"hash,omitempty"`
+	Compressed bool   `json:"compressed,omitempty"`
+}
+
+type response struct {
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Server is the reference signing service: it serves the requests of
+// Clients with the signers it was given, which may be of any kind.
+type Server struct {
+	keys map[string]Signer
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// NewServer returns a server for the signers, keyed by the names clients
+// use to select them.
+func NewServer(keys map[string]Signer) *Server {
+	return &Server{keys: keys, conns: make(map[net.Conn]struct{})}
+}
+
+// Listen listens on the Unix socket at path, readable and writable by the
+// owner only.  The socket is bound in a private directory next to path and
+// renamed into place, so it is never reachable with wider permissions.  A
+// stale socket file left at path is removed first, but Listen fails if
+// another server is still listening there.
+func Listen(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("signer: %s exists and is not a socket", path)
+		}
+		c, err := net.Dial("unix", path)
+		if err == nil {
+			c.Close()
+			return nil, fmt.Errorf("signer: %s is in use", path)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("signer: %v", err)
+		}
+		os.Remove(path)
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".signer")
+	if err != nil {
+		return nil, fmt.Errorf("signer: %v", err)
+	}
+	defer os.Remove(dir)
+	tmp := filepath.Join(dir, "sock")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("signer: %v", err)
+	}
+	l.SetUnlinkOnClose(false)
+	if err = os.Chmod(tmp, 0600); err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		l.Close()
+		os.Remove(tmp)
+		return nil, fmt.Errorf("signer: %v", err)
+	}
+	return &listener{UnixListener: l, path: path}, nil
+}
+
+// listener is a Unix listener whose socket was renamed to path after it
+// was bound.  It removes the socket at path when closed.
+type listener struct {
+	*net.UnixListener
+	path string
+	once sync.Once
+}
+
+func (l *listener) Addr() net.Addr {
+	return &net.UnixAddr{Name: l.path, Net: "unix"}
+}
+
+func (l *listener) Close() error {
+	err := l.UnixListener.Close()
+	l.once.Do(func() { os.Remove(l.path) })
+	return err
+}
+
+// ListenAndServe serves the Unix socket at path, see Listen, until Close.
+func (s *Server) ListenAndServe(path string) error {
+	l, err := Listen(path)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l and serves each in its own goroutine
+// until Close, after which it returns nil.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("signer: %v", err)
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return nil
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the server and closes its listener and connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return err
+}
+
+// track registers an open connection, unless the server is closed.
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	enc := json.NewEncoder(conn)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			// EOF, a closed connection or a stream that is no
+			// longer in sync; either way the connection is done.
+			return
+		}
+		resp := s.handle(&req)
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle runs one request.
+func (s *Server) handle(req *request) response {
+	sg, ok := s.keys[req.Key]
+	if !ok {
+		return response{Error: fmt.Sprintf("unknown key %q", req.Key)}
+	}
+	if req.Method == methodPublicKey {
+		return response{Result: hex.EncodeToString(sg.PublicKey().SerializeCompressed())}
+	}
+
+	hash, err := hex.DecodeString(req.Hash)
+	if err != nil || len(hash) != HashSize {
+		return response{Error: ErrHashSize.Error()}
+	}
+	var result []byte
+	switch req.Method {
+	case methodSignHash:
+		var sig *ec.Signature
+		if sig, err = sg.SignHash(hash); err == nil {
+			result = sig.Serialize()
+		}
+	case methodSignCompact:
+		result, err = sg.SignCompact(hash, req.Compressed)
+	case methodSignSchnorr:
+		ss, ok := sg.(SchnorrSigner)
+		if !ok {
+			return response{Error: ErrNoSchnorr.Error()}
+		}
+		var sig *ec.SchnorrSignature
+		if sig, err = ss.SignSchnorr(hash); err == nil {
+			result = sig.Serialize()
+		}
+	default:
+		return response{Error: fmt.Sprintf("unknown method %q", req.Method)}
+	}
+	if err != nil {
+		return response{Error: err.Error()}
+	}
+	return response{Result: hex.EncodeToString(result)}
+}
@@ -0,0 +1,173 @@
+package signer
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+)
+
+// ecdsaOnly hides the SignSchnorr method of a signer.
+type ecdsaOnly struct{ Signer }
+
+// impostor claims the public key of one signer and signs with another.
+type impostor struct {
+	claimed, actual Signer
+}
+
+func (i impostor) PublicKey() *ec.PublicKey { return i.claimed.PublicKey() }
+func (i impostor) SignHash(h []byte) (*ec.Signature, error) {
+	return i.actual.SignHash(h)
+}
+func (i impostor) SignCompact(h []byte, c bool) ([]byte, error) {
+	return i.actual.SignCompact(h, c)
+}
+
+// startServer runs srv on a socket in a temporary directory and
+// returns its path.
+func startServer(t *testing.T, srv *Server) string {
+	path := filepath.Join(t.TempDir(), "signer.sock")
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(l) }()
+	t.Cleanup(func() {
+		srv.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return path
+}
+
+func TestClient(t *testing.T) {
+	node, other := testKey("node"), testKey("other")
+	path := startServer(t, NewServer(map[string]Signer{
+		"node":     NewLocal(node),
+		"ecdsa":    ecdsaOnly{NewLocal(other)},
+		"impostor": impostor{claimed: NewLocal(node), actual: NewLocal(other)},
+	}))
+
+	c, err := Dial(path, "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	checkSigner(t, "client", c, node)
+
+	// Concurrent requests share the connection.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hash := testHash(string(rune('a' + i)))
+			sig, err := c.SignHash(hash)
+			if err != nil || !sig.Verify(hash, node.ECPubKey()) {
+				t.Errorf("concurrent SignHash: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ecdsa, err := Dial(path, "ecdsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ecdsa.Close()
+	if _, err := ecdsa.SignHash(testHash("ecdsa")); err != nil {
+		t.Errorf("SignHash: %v", err)
+	}
+	if _, err := ecdsa.SignSchnorr(testHash("ecdsa")); err != ErrNoSchnorr {
+		t.Errorf("SignSchnorr: got %v, want %v", err, ErrNoSchnorr)
+	}
+
+	// Signatures that do not match the advertised key are rejected.
+	liar, err := Dial(path, "impostor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer liar.Close()
+	if _, err := liar.SignHash(testHash("liar")); err != ErrBadSigning {
+		t.Errorf("impostor SignHash: got %v, want %v", err, ErrBadSigning)
+	}
+	if _, err := liar.SignCompact(testHash("liar"), true); err != ErrBadSigning {
+		t.Errorf("impostor SignCompact: got %v, want %v", err, ErrBadSigning)
+	}
+
+	if _, err := Dial(path, "missing"); err == nil {
+		t.Error("Dial succeeded for an unknown key")
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	srv := NewServer(map[string]Signer{"node": NewLocal(testKey("node"))})
+	path := startServer(t, srv)
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("socket mode %v, %v", fi.Mode(), err)
+	}
+
+	c, err := Dial(path, "node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SignHash(testHash("closed")); err == nil {
+		t.Error("signed through a closed server")
+	}
+	if _, err := Dial(path, "node"); err == nil {
+		t.Error("dialed a closed server")
+	}
+}
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "signer.sock")
+
+	// A socket nobody listens on any more is replaced.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatalf("stale socket: %v", err)
+	}
+	if l.Addr().String() != path {
+		t.Errorf("Addr = %v, want %s", l.Addr(), path)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%d files in the socket directory", len(entries))
+	}
+
+	// The socket of a running server is left alone.
+	if _, err := Listen(path); err == nil {
+		t.Error("Listen took over the socket of a running server")
+	}
+	if c, err := net.Dial("unix", path); err != nil {
+		t.Errorf("socket lost: %v", err)
+	} else {
+		c.Close()
+	}
+
+	l.Close()
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket left after Close: %v", err)
+	}
+
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, nil, 0600)
+	if _, err := Listen(file); err == nil {
+		t.Error("Listen replaced a regular file")
+	}
+}
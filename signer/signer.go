@@ -0,0 +1,95 @@
+// 签名接口: 把签名与内存中的私钥解耦, 私钥可以在本进程, 在 HD 钱包里, 也可以在
+// 另一个进程 (签名守护进程, HSM 代理) 中, 调用方只依赖 Signer.
+//
+// Three implementations are provided:
+//
+//   Local     a *elliptic.PrivateKey held in process
+//   Keyring   signers for BIP32 paths below a hdkeychain.ExtendedKey
+//   Client    a key held by a signing service on a Unix socket, see Server
+//
+// All of them sign 32 byte hashes.  ECDSA signatures are the deterministic
+// RFC 6979 signatures of PrivateKey.Sign and compact signatures are those of
+// elliptic.SignCompact, so a signature does not depend on where the key is.
+
+package signer
+
+import "fmt"
+
+// import ec "../elliptic"
+import ec "github.com/symphonyprotocol/sutil/elliptic"
+
+// HashSize is the length of the hashes that signers sign.
+const HashSize = 32
+
+var (
+	ErrHashSize   = fmt.Errorf("signer: hash must be %d bytes", HashSize)
+	ErrNoSchnorr  = fmt.Errorf("signer: key does not support Schnorr signatures")
+	ErrBadSigning = fmt.Errorf("signer: signature does not verify against the public key")
+)
+
+// Signer signs hashes with a secp256k1 key it does not have to expose.
+type Signer interface {
+	// PublicKey returns the public key of the signer.
+	PublicKey() *ec.PublicKey
+
+	// SignHash returns an ECDSA signature of the 32 byte hash.
+	SignHash(hash []byte) (*ec.Signature, error)
+
+	// SignCompact returns a 65 byte recoverable signature of the hash in
+	// the format of elliptic.SignCompact.
+	SignCompact(hash []byte, isCompressedKey bool) ([]byte, error)
+}
+
+// SchnorrSigner is a Signer that can also make BIP340 Schnorr signatures.
+// Callers check for it with a type assertion.
+type SchnorrSigner interface {
+	Signer
+
+	// SignSchnorr returns a BIP340 signature of the 32 byte hash.
+	SignSchnorr(hash []byte) (*ec.SchnorrSignature, error)
+}
+
+// Local is a Signer for a private key held in process.
+type Local struct {
+	priv *ec.PrivateKey
+}
+
+// NewLocal returns a signer for priv.  The signer uses priv directly, so
+// zeroing priv disables the signer.
+func NewLocal(priv *ec.PrivateKey) *Local {
+	return &Local{priv: priv}
+}
+
+// PublicKey returns the public key of the signer.
+func (l *Local) PublicKey() *ec.PublicKey {
+	return l.priv.ECPubKey()
+}
+
+// SignHash returns an ECDSA signature of the hash.
+func (l *Local) SignHash(hash []byte) (*ec.Signature, error) {
+	if len(hash) != HashSize {
+		return nil, ErrHashSize
+	}
+	return l.priv.Sign(hash)
+}
+
+// SignCompact returns a recoverable signature of the hash.
+func (l *Local) SignCompact(hash []byte, isCompressedKey bool) ([]byte, error) {
+	if len(hash) != HashSize {
+		return nil, ErrHashSize
+	}
+	return ec.SignCompact(ec.S256(), l.priv, hash, isCompressedKey)
+}
+
+// SignSchnorr returns a BIP340 signature of the hash.
+func (l *Local) SignSchnorr(hash []byte) (*ec.SchnorrSignature, error) {
+	if len(hash) != HashSize {
+		return nil, ErrHashSize
+	}
+	return l.priv.SignSchnorr(hash)
+}
+
+// Zero wipes the private key of the signer.
+func (l *Local) Zero() {
+	l.priv.Zero()
+}
@@ -0,0 +1,150 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	ec "github.com/symphonyprotocol/sutil/elliptic"
+	hd "github.com/symphonyprotocol/sutil/hdkeychain"
+)
+
+func testKey(seed string) *ec.PrivateKey {
+	b := sha256.Sum256([]byte(seed))
+	priv, _ := ec.PrivKeyFromBytes(ec.S256(), b[:])
+	return priv
+}
+
+func testHash(msg string) []byte {
+	h := sha256.Sum256([]byte(msg))
+	return h[:]
+}
+
+// checkSigner checks that s makes valid signatures for its public key, and
+// that they are the signatures of priv where those are deterministic.
+func checkSigner(t *testing.T, name string, s Signer, priv *ec.PrivateKey) {
+	hash := testHash(name)
+	pub := s.PublicKey()
+	if pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+		t.Errorf("%s: wrong public key", name)
+	}
+
+	sig, err := s.SignHash(hash)
+	if err != nil {
+		t.Fatalf("%s: SignHash: %v", name, err)
+	}
+	want, _ := priv.Sign(hash)
+	if !sig.IsEqual(want) || !sig.Verify(hash, pub) {
+		t.Errorf("%s: SignHash differs from PrivateKey.Sign", name)
+	}
+
+	for _, compressed := range []bool{false, true} {
+		compact, err := s.SignCompact(hash, compressed)
+		if err != nil {
+			t.Fatalf("%s: SignCompact: %v", name, err)
+		}
+		wantCompact, _ := ec.SignCompact(ec.S256(), priv, hash, compressed)
+		if !bytes.Equal(compact, wantCompact) {
+			t.Errorf("%s: SignCompact(%v) differs from elliptic.SignCompact", name, compressed)
+		}
+	}
+
+	if _, err := s.SignHash(hash[:31]); err != ErrHashSize {
+		t.Errorf("%s: short hash: got %v, want %v", name, err, ErrHashSize)
+	}
+
+	ss, ok := s.(SchnorrSigner)
+	if !ok {
+		t.Fatalf("%s: not a SchnorrSigner", name)
+	}
+	schnorr, err := ss.SignSchnorr(hash)
+	if err != nil {
+		t.Fatalf("%s: SignSchnorr: %v", name, err)
+	}
+	if !schnorr.Verify(hash, pub) {
+		t.Errorf("%s: invalid Schnorr signature", name)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	priv := testKey("local")
+	checkSigner(t, "local", NewLocal(priv), priv)
+}
+
+func TestKeyring(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 32)
+	master, err := hd.NewMaster(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr, err := NewKeyring(master)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The key at m/44'/0'/1 derived by hand.
+	want, _ := hd.NewMaster(seed)
+	for _, idx := range []uint32{hd.HardenedKeyStart + 44, hd.HardenedKeyStart, 1} {
+		if want, err = want.Child(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantPriv, _ := want.ECPrivKey()
+
+	for _, path := range []string{"m/44'/0'/1", "m/44h/0h/1"} {
+		s, err := kr.Signer(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		checkSigner(t, path, s, wantPriv)
+	}
+
+	// Deriving children leaves the master key intact.
+	masterPriv, _ := master.ECPrivKey()
+	s, err := kr.Signer("m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSigner(t, "m", s, masterPriv)
+
+	neutered, _ := master.Neuter()
+	if _, err := NewKeyring(neutered); err == nil {
+		t.Error("NewKeyring accepted an extended public key")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	h := uint32(hd.HardenedKeyStart)
+	tests := []struct {
+		path string
+		want []uint32
+	}{
+		{"m", []uint32{}},
+		{"m/0", []uint32{0}},
+		{"m/44'/60'/0'/0/7", []uint32{h + 44, h + 60, h, 0, 7}},
+		{"m/1h/2147483647", []uint32{h + 1, h - 1}},
+	}
+	for _, test := range tests {
+		got, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", test.path, err)
+			continue
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("ParsePath(%q) = %v, want %v", test.path, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("ParsePath(%q) = %v, want %v", test.path, got, test.want)
+				break
+			}
+		}
+	}
+
+	for _, path := range []string{"", "M/0", "0/1", "m/", "m/x", "m/-1", "m/2147483648", "m/1''"} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q) accepted", path)
+		}
+	}
+}